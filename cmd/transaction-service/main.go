@@ -1,24 +1,98 @@
 package main
 
 import (
-	"log"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/kodra-pay/transaction-service/internal/config"
+	"github.com/kodra-pay/transaction-service/internal/handlers"
+	"github.com/kodra-pay/transaction-service/internal/logging"
 	"github.com/kodra-pay/transaction-service/internal/middleware"
 	"github.com/kodra-pay/transaction-service/internal/routes"
+	"github.com/kodra-pay/transaction-service/internal/tracing"
 )
 
 func main() {
-	cfg := config.Load("transaction-service", "7004")
+	cfg, err := config.Load("transaction-service", "7004")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	logger := logging.New(cfg.LogLevel).With("service", cfg.ServiceName)
+	slog.SetDefault(logger)
+
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		os.Exit(runMigrate(cfg, logger, os.Args[2:]))
+	}
 
-	app := fiber.New()
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.ServiceName, cfg.Tracing)
+	if err != nil {
+		logger.Error("failed to set up tracing", "error", err)
+		os.Exit(1)
+	}
+
+	app := fiber.New(fiber.Config{
+		ReadTimeout:  cfg.HTTP.ReadTimeout,
+		WriteTimeout: cfg.HTTP.WriteTimeout,
+		IdleTimeout:  cfg.HTTP.IdleTimeout,
+		ErrorHandler: handlers.ErrorHandler,
+	})
 	app.Use(middleware.RequestID())
 
-	routes.Register(app, cfg.ServiceName)
+	startupCtx, cancelStartup := context.WithTimeout(context.Background(), cfg.StartupTimeout)
+	deps, err := routes.NewDependencies(startupCtx, cfg, logger)
+	cancelStartup()
+	if err != nil {
+		logger.Error("failed to start", "error", err)
+		shutdownTracing(context.Background())
+		os.Exit(1)
+	}
+	shutdownServices, err := routes.Register(app, cfg, logger, deps)
+	if err != nil {
+		logger.Error("failed to start", "error", err)
+		shutdownTracing(context.Background())
+		os.Exit(1)
+	}
+
+	stop, cancel := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer cancel()
+
+	listenErr := make(chan error, 1)
+	go func() {
+		logger.Info("listening", "port", cfg.Port)
+		listenErr <- app.Listen(":" + cfg.Port)
+	}()
+
+	exitCode := 0
+	select {
+	case err := <-listenErr:
+		logger.Error("server stopped", "error", err)
+		exitCode = 1
+	case <-stop.Done():
+		logger.Info("shutting down", "timeout", cfg.ShutdownTimeout)
+	}
+
+	// One deadline covers draining requests, background work and flushing spans.
+	ctx, cancelShutdown := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancelShutdown()
 
-	log.Printf("%s listening on :%s", cfg.ServiceName, cfg.Port)
-	if err := app.Listen(":" + cfg.Port); err != nil {
-		log.Fatal(err)
+	if err := app.ShutdownWithContext(ctx); err != nil {
+		logger.Error("failed to drain in-flight requests", "error", err)
+		exitCode = 1
+	}
+	if err := shutdownServices(ctx); err != nil {
+		exitCode = 1
 	}
+	if err := shutdownTracing(ctx); err != nil {
+		logger.Warn("failed to flush traces", "error", err)
+	}
+
+	logger.Info("shutdown complete")
+	os.Exit(exitCode)
 }
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/kodra-pay/transaction-service/internal/config"
+	"github.com/kodra-pay/transaction-service/internal/migrate"
+	"github.com/kodra-pay/transaction-service/internal/repositories"
+	"github.com/kodra-pay/transaction-service/migrations"
+)
+
+const migrateUsage = "usage: transaction-service migrate up | down [steps] | status"
+
+// runMigrate implements "transaction-service migrate up|down|status" and
+// returns the process exit code.
+func runMigrate(cfg config.Config, logger *slog.Logger, args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+	if cfg.Storage != config.StoragePostgres {
+		fmt.Fprintf(os.Stderr, "migrate needs STORAGE=%s, got %s\n", config.StoragePostgres, cfg.Storage)
+		return 2
+	}
+
+	ctx := context.Background()
+	startupCtx, cancel := context.WithTimeout(ctx, cfg.StartupTimeout)
+	defer cancel()
+	db, err := repositories.Connect(startupCtx, cfg.Postgres, logger)
+	if err != nil {
+		logger.Error("failed to connect to database", "error", err)
+		return 1
+	}
+	defer db.Close()
+
+	m, err := migrate.New(db, migrations.FS, logger)
+	if err != nil {
+		logger.Error("failed to load migrations", "error", err)
+		return 1
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := m.Up(ctx)
+		if err != nil {
+			logger.Error("migration failed", "error", err)
+			return 1
+		}
+		logger.Info("database is up to date", "applied", len(applied))
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				fmt.Fprintln(os.Stderr, migrateUsage)
+				return 2
+			}
+		}
+		reverted, err := m.Down(ctx, steps)
+		if err != nil {
+			logger.Error("migration failed", "error", err)
+			return 1
+		}
+		logger.Info("reverted migrations", "reverted", len(reverted))
+	case "status":
+		list, err := m.Status(ctx)
+		if err != nil {
+			logger.Error("failed to read migration status", "error", err)
+			return 1
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, s := range list {
+			applied := "pending"
+			if s.AppliedAt != nil {
+				applied = s.AppliedAt.UTC().Format("2006-01-02 15:04:05Z")
+			}
+			fmt.Fprintf(w, "%04d\t%s\t%s\n", s.Version, s.Name, applied)
+		}
+		w.Flush()
+	default:
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+	return 0
+}
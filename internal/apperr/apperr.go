@@ -0,0 +1,189 @@
+// Package apperr defines the typed errors services and handlers return and
+// the stable codes clients see in error responses.
+package apperr
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Code classifies a failure for API clients. Codes are part of the API
+// contract: add new ones, never rename them.
+type Code string
+
+const (
+	CodeInvalidRequest         Code = "invalid_request"
+	CodeValidationFailed       Code = "validation_failed"
+	CodeUnauthenticated        Code = "unauthenticated"
+	CodeForbidden              Code = "forbidden"
+	CodeNotFound               Code = "not_found"
+	CodeMethodNotAllowed       Code = "method_not_allowed"
+	CodeConflict               Code = "conflict"
+	CodeDuplicateReference     Code = "duplicate_reference"
+	CodeInvalidStateTransition Code = "invalid_state_transition"
+	CodeUnprocessable          Code = "unprocessable"
+	CodeLimitExceeded          Code = "limit_exceeded"
+	CodeInsufficientBalance    Code = "insufficient_balance"
+	CodeRateLimited            Code = "rate_limited"
+	CodeUnavailable            Code = "unavailable"
+	CodeInternal               Code = "internal_error"
+)
+
+var statuses = map[Code]int{
+	CodeInvalidRequest:         http.StatusBadRequest,
+	CodeValidationFailed:       http.StatusBadRequest,
+	CodeUnauthenticated:        http.StatusUnauthorized,
+	CodeForbidden:              http.StatusForbidden,
+	CodeNotFound:               http.StatusNotFound,
+	CodeMethodNotAllowed:       http.StatusMethodNotAllowed,
+	CodeConflict:               http.StatusConflict,
+	CodeDuplicateReference:     http.StatusConflict,
+	CodeInvalidStateTransition: http.StatusConflict,
+	CodeUnprocessable:          http.StatusUnprocessableEntity,
+	CodeLimitExceeded:          http.StatusUnprocessableEntity,
+	CodeInsufficientBalance:    http.StatusUnprocessableEntity,
+	CodeRateLimited:            http.StatusTooManyRequests,
+	CodeUnavailable:            http.StatusServiceUnavailable,
+	CodeInternal:               http.StatusInternalServerError,
+}
+
+// Status is the HTTP status responses with code are sent with.
+func (c Code) Status() int {
+	if s, ok := statuses[c]; ok {
+		return s
+	}
+	return http.StatusInternalServerError
+}
+
+// FieldError names one request field that failed validation.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Error is a failure with a code clients can act on. Message is shown to
+// clients, except for internal errors, whose message and cause are only
+// logged. Package-level *Error values are sentinels for errors.Is; never
+// modify one.
+type Error struct {
+	Code    Code
+	Message string
+	Fields  []FieldError
+	// Details is extra machine-readable context, rendered as is.
+	Details interface{}
+	cause   error
+}
+
+func New(code Code, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+// Wrap returns an error with code and message that keeps cause for logs and
+// errors.Is.
+func Wrap(code Code, message string, cause error) *Error {
+	return &Error{Code: code, Message: message, cause: cause}
+}
+
+// Internal hides cause from clients behind message.
+func Internal(message string, cause error) *Error {
+	return Wrap(CodeInternal, message, cause)
+}
+
+// BadRequest reports a request that could not be read, such as a malformed
+// body or path parameter.
+func BadRequest(message string) *Error {
+	return New(CodeInvalidRequest, message)
+}
+
+// Invalid reports a single invalid field; the message reads "<field> <problem>".
+func Invalid(field, problem string) *Error {
+	return Validation(FieldError{Field: field, Message: problem})
+}
+
+// Validation reports one or more invalid fields.
+func Validation(fields ...FieldError) *Error {
+	message := "request validation failed"
+	if len(fields) == 1 {
+		message = fields[0].Field + " " + fields[0].Message
+	}
+	return &Error{Code: CodeValidationFailed, Message: message, Fields: fields}
+}
+
+func Forbidden(message string) *Error {
+	return New(CodeForbidden, message)
+}
+
+func Unauthenticated(message string) *Error {
+	return New(CodeUnauthenticated, message)
+}
+
+func (e *Error) Error() string {
+	if e.cause != nil {
+		return e.Message + ": " + e.cause.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// Status returns the HTTP status err should be answered with: the status of
+// its code, the code of a *fiber.Error, or 500 for anything else.
+func Status(err error) int {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code.Status()
+	}
+	var fe *fiber.Error
+	if errors.As(err, &fe) {
+		return fe.Code
+	}
+	return http.StatusInternalServerError
+}
+
+// From converts err into the *Error a client is shown. Domain errors keep
+// their text, including any context they were wrapped in; Fiber's own errors
+// such as unknown routes get the code matching their status; anything else
+// is internal and its text is withheld.
+func From(err error) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		if e.Code == CodeInternal {
+			return &Error{Code: CodeInternal, Message: e.Message}
+		}
+		shown := *e
+		shown.Message = err.Error()
+		shown.cause = nil
+		return &shown
+	}
+	var fe *fiber.Error
+	if errors.As(err, &fe) {
+		return &Error{Code: codeForStatus(fe.Code), Message: fe.Message}
+	}
+	return &Error{Code: CodeInternal, Message: "internal server error"}
+}
+
+func codeForStatus(status int) Code {
+	for code, s := range statuses {
+		if s == status && code.primary() {
+			return code
+		}
+	}
+	if status >= http.StatusInternalServerError {
+		return CodeInternal
+	}
+	return CodeInvalidRequest
+}
+
+// primary reports whether c is the generic code for its status, as opposed
+// to a more specific one sharing it.
+func (c Code) primary() bool {
+	switch c {
+	case CodeValidationFailed, CodeDuplicateReference, CodeInvalidStateTransition, CodeLimitExceeded, CodeInsufficientBalance:
+		return false
+	}
+	return true
+}
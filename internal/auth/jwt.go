@@ -0,0 +1,245 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/kodra-pay/transaction-service/internal/config"
+)
+
+var ErrInvalidToken = errors.New("invalid token")
+
+// JWTVerifier validates RS256, ES256 and HS256 tokens issued by the dashboard
+// and other Kodra services and turns their claims into a Principal.
+type JWTVerifier struct {
+	cfg    config.JWTConfig
+	keys   map[string]interface{} // by kid; "" holds keys without a kid
+	hmac   []byte
+	parser *jwt.Parser
+}
+
+// NewJWTVerifier loads the configured keys. It returns nil, nil when no key
+// source is configured, which disables JWT authentication.
+func NewJWTVerifier(cfg config.JWTConfig) (*JWTVerifier, error) {
+	if cfg.JWKSFile == "" && len(cfg.PublicKeyFiles) == 0 && cfg.HMACSecret == "" {
+		return nil, nil
+	}
+
+	v := &JWTVerifier{cfg: cfg, keys: map[string]interface{}{}}
+	if cfg.HMACSecret != "" {
+		v.hmac = []byte(cfg.HMACSecret)
+	}
+	if cfg.JWKSFile != "" {
+		if err := v.loadJWKS(cfg.JWKSFile); err != nil {
+			return nil, err
+		}
+	}
+	for _, entry := range cfg.PublicKeyFiles {
+		kid, path, ok := strings.Cut(entry, "=")
+		if !ok {
+			kid, path = "", entry
+		}
+		key, err := loadPEMPublicKey(path)
+		if err != nil {
+			return nil, err
+		}
+		v.keys[kid] = key
+	}
+
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{"RS256", "ES256", "HS256"}),
+		jwt.WithLeeway(cfg.Leeway),
+		jwt.WithExpirationRequired(),
+	}
+	if cfg.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		opts = append(opts, jwt.WithAudience(cfg.Audience))
+	}
+	v.parser = jwt.NewParser(opts...)
+	return v, nil
+}
+
+// LooksLikeJWT reports whether a bearer credential has the shape of a compact JWS.
+func LooksLikeJWT(credential string) bool {
+	return strings.Count(credential, ".") == 2 && strings.HasPrefix(credential, "eyJ")
+}
+
+// Verify checks the token's signature and registered claims and extracts the
+// merchant and role claims.
+func (v *JWTVerifier) Verify(token string) (*Principal, error) {
+	claims := jwt.MapClaims{}
+	if _, err := v.parser.ParseWithClaims(token, claims, v.keyFor); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	p := &Principal{Kind: KindJWT, Mode: ModeLive}
+	p.Subject, _ = claims.GetSubject()
+
+	merchantID, err := intClaim(claims[v.cfg.MerchantClaim])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s claim: %v", ErrInvalidToken, v.cfg.MerchantClaim, err)
+	}
+	p.MerchantID = merchantID
+
+	switch roles := claims[v.cfg.RolesClaim].(type) {
+	case string:
+		p.Roles = strings.Fields(roles)
+	case []interface{}:
+		for _, r := range roles {
+			if s, ok := r.(string); ok {
+				p.Roles = append(p.Roles, s)
+			}
+		}
+	}
+	return p, nil
+}
+
+// keyFor picks the verification key for the token's algorithm and kid.
+func (v *JWTVerifier) keyFor(t *jwt.Token) (interface{}, error) {
+	if t.Method.Alg() == "HS256" {
+		if v.hmac == nil {
+			return nil, errors.New("HS256 is not configured")
+		}
+		return v.hmac, nil
+	}
+
+	kid, _ := t.Header["kid"].(string)
+	key, ok := v.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+	switch key.(type) {
+	case *rsa.PublicKey:
+		if t.Method.Alg() != "RS256" {
+			return nil, fmt.Errorf("key %q does not support %s", kid, t.Method.Alg())
+		}
+	case *ecdsa.PublicKey:
+		if t.Method.Alg() != "ES256" {
+			return nil, fmt.Errorf("key %q does not support %s", kid, t.Method.Alg())
+		}
+	}
+	return key, nil
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+	K   string `json:"k"`
+}
+
+func (v *JWTVerifier) loadJWKS(path string) error {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read jwks: %w", err)
+	}
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(raw, &set); err != nil {
+		return fmt.Errorf("parse jwks: %w", err)
+	}
+
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		switch k.Kty {
+		case "RSA":
+			n, err := decodeBigInt(k.N)
+			if err != nil {
+				return fmt.Errorf("jwks key %q: n: %w", k.Kid, err)
+			}
+			e, err := decodeBigInt(k.E)
+			if err != nil {
+				return fmt.Errorf("jwks key %q: e: %w", k.Kid, err)
+			}
+			v.keys[k.Kid] = &rsa.PublicKey{N: n, E: int(e.Int64())}
+		case "EC":
+			if k.Crv != "P-256" {
+				return fmt.Errorf("jwks key %q: unsupported curve %q", k.Kid, k.Crv)
+			}
+			x, err := decodeBigInt(k.X)
+			if err != nil {
+				return fmt.Errorf("jwks key %q: x: %w", k.Kid, err)
+			}
+			y, err := decodeBigInt(k.Y)
+			if err != nil {
+				return fmt.Errorf("jwks key %q: y: %w", k.Kid, err)
+			}
+			v.keys[k.Kid] = &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}
+		case "oct":
+			secret, err := base64.RawURLEncoding.DecodeString(k.K)
+			if err != nil {
+				return fmt.Errorf("jwks key %q: k: %w", k.Kid, err)
+			}
+			v.hmac = secret
+		}
+	}
+	return nil
+}
+
+func loadPEMPublicKey(path string) (interface{}, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read public key: %w", err)
+	}
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		return nil, fmt.Errorf("public key %s: no PEM block found", path)
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("public key %s: %w", path, err)
+	}
+	switch key.(type) {
+	case *rsa.PublicKey, *ecdsa.PublicKey:
+		return key, nil
+	}
+	return nil, fmt.Errorf("public key %s: unsupported key type %T", path, key)
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// intClaim accepts merchant IDs encoded as JSON numbers or strings. A missing
+// claim yields zero.
+func intClaim(v interface{}) (int, error) {
+	switch n := v.(type) {
+	case nil:
+		return 0, nil
+	case float64:
+		return int(n), nil
+	case string:
+		if n == "" {
+			return 0, nil
+		}
+		return strconv.Atoi(n)
+	}
+	return 0, fmt.Errorf("unexpected type %T", v)
+}
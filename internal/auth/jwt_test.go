@@ -0,0 +1,155 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/kodra-pay/transaction-service/internal/config"
+)
+
+func testJWTConfig() config.JWTConfig {
+	return config.JWTConfig{
+		HMACSecret:    "test-secret",
+		Issuer:        "kodra-dashboard",
+		MerchantClaim: "merchant_id",
+		RolesClaim:    "roles",
+	}
+}
+
+func signHS256(t *testing.T, secret string, claims jwt.MapClaims) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestJWTVerifierClaims(t *testing.T) {
+	v, err := NewJWTVerifier(testJWTConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := time.Now().Add(time.Hour).Unix()
+
+	p, err := v.Verify(signHS256(t, "test-secret", jwt.MapClaims{
+		"sub": "user-1", "iss": "kodra-dashboard", "exp": exp, "merchant_id": "42", "roles": "developer viewer",
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Kind != KindJWT || p.Subject != "user-1" || p.MerchantID != 42 || !p.HasRole("viewer") || p.IsInternal() {
+		t.Errorf("merchant user verified as %+v", p)
+	}
+
+	p, err = v.Verify(signHS256(t, "test-secret", jwt.MapClaims{
+		"sub": "settlement", "iss": "kodra-dashboard", "exp": exp, "roles": []string{RoleService},
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !p.IsInternal() || p.MerchantID != 0 {
+		t.Errorf("service token verified as %+v", p)
+	}
+}
+
+func TestJWTVerifierRejects(t *testing.T) {
+	v, err := NewJWTVerifier(testJWTConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	valid := jwt.MapClaims{"sub": "user-1", "iss": "kodra-dashboard", "exp": time.Now().Add(time.Hour).Unix()}
+	with := func(key string, value interface{}) jwt.MapClaims {
+		c := jwt.MapClaims{}
+		for k, v := range valid {
+			c[k] = v
+		}
+		if value == nil {
+			delete(c, key)
+		} else {
+			c[key] = value
+		}
+		return c
+	}
+	none, err := jwt.NewWithClaims(jwt.SigningMethodNone, valid).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]string{
+		"wrong secret": signHS256(t, "other-secret", valid),
+		"expired":      signHS256(t, "test-secret", with("exp", time.Now().Add(-time.Hour).Unix())),
+		"no expiry":    signHS256(t, "test-secret", with("exp", nil)),
+		"wrong issuer": signHS256(t, "test-secret", with("iss", "someone-else")),
+		"bad merchant": signHS256(t, "test-secret", with("merchant_id", "abc")),
+		"unsigned":     none,
+		"not a token":  "eyJhbGciOi.x.y",
+	}
+	for name, token := range tests {
+		if _, err := v.Verify(token); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("%s: %v, want ErrInvalidToken", name, err)
+		}
+	}
+}
+
+func TestJWTVerifierES256PublicKeyFile(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "dashboard.pem")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := testJWTConfig()
+	cfg.HMACSecret = ""
+	cfg.PublicKeyFiles = []string{"dash-1=" + path}
+	v, err := NewJWTVerifier(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"sub": "user-2", "iss": "kodra-dashboard", "exp": time.Now().Add(time.Hour).Unix(), "merchant_id": 7,
+	})
+	token.Header["kid"] = "dash-1"
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p, err := v.Verify(signed); err != nil || p.MerchantID != 7 {
+		t.Errorf("ES256 token: %+v, %v", p, err)
+	}
+
+	token.Header["kid"] = "unknown"
+	if signed, err = token.SignedString(key); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := v.Verify(signed); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("unknown kid: %v, want ErrInvalidToken", err)
+	}
+	if !LooksLikeJWT(signed) || LooksLikeJWT("kp_live_abc") {
+		t.Error("LooksLikeJWT misclassifies credentials")
+	}
+}
+
+func TestNewJWTVerifierDisabled(t *testing.T) {
+	if v, err := NewJWTVerifier(config.JWTConfig{}); v != nil || err != nil {
+		t.Errorf("no key source: %v, %v, want nil, nil", v, err)
+	}
+}
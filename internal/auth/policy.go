@@ -0,0 +1,155 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Roles understood by the policy layer.
+const (
+	RoleMerchantOwner  = "merchant-owner"
+	RoleMerchantViewer = "merchant-viewer"
+	RoleSupport        = "support"
+	RoleFinance        = "finance"
+)
+
+// Actions checked by handlers before calling into services.
+const (
+	ActionTransactionCreate  = "transactions:create"
+	ActionTransactionRead    = "transactions:read"
+	ActionTransactionListAll = "transactions:list_all"
+	ActionTransactionCapture = "transactions:capture"
+	ActionTransactionRefund  = "transactions:refund"
+	ActionTransactionVoid    = "transactions:void"
+	ActionTransactionEdit    = "transactions:edit"
+	ActionTransactionHistory = "transactions:history"
+	ActionTransactionReport  = "transactions:report_status"
+	ActionPayoutCreate       = "payouts:create"
+	ActionAdjustmentRead     = "adjustments:read"
+	ActionAdjustmentPropose  = "adjustments:propose"
+	ActionAdjustmentApprove  = "adjustments:approve"
+	ActionDisputeOpen        = "disputes:open"
+	ActionDisputeRead        = "disputes:read"
+	ActionDisputeRespond     = "disputes:respond"
+	ActionDisputeResolve     = "disputes:resolve"
+	ActionReviewRead         = "reviews:read"
+	ActionReviewDecide       = "reviews:decide"
+	ActionLimitRead          = "limits:read"
+	ActionLimitWrite         = "limits:write"
+	ActionAPIKeyRead         = "api_keys:read"
+	ActionAPIKeyManage       = "api_keys:manage"
+	ActionPIIRead            = "pii:read"
+	ActionPIIRotate          = "pii:rotate"
+	ActionCustomerExport     = "customers:export"
+	ActionCustomerErase      = "customers:erase"
+)
+
+var knownRoles = map[string]bool{
+	RoleMerchantOwner:  true,
+	RoleMerchantViewer: true,
+	RoleSupport:        true,
+	RoleFinance:        true,
+	RoleAdmin:          true,
+	RoleService:        true,
+}
+
+// DefaultPolicy is used when no policy file is configured.
+var DefaultPolicy = map[string][]string{
+	ActionTransactionCreate:  {RoleMerchantOwner, RoleAdmin, RoleService},
+	ActionTransactionRead:    {RoleMerchantOwner, RoleMerchantViewer, RoleSupport, RoleFinance, RoleAdmin, RoleService},
+	ActionTransactionListAll: {RoleSupport, RoleFinance, RoleAdmin, RoleService},
+	ActionTransactionCapture: {RoleMerchantOwner, RoleAdmin, RoleService},
+	ActionTransactionRefund:  {RoleMerchantOwner, RoleFinance, RoleAdmin, RoleService},
+	ActionTransactionVoid:    {RoleMerchantOwner, RoleFinance, RoleAdmin, RoleService},
+	ActionTransactionEdit:    {RoleSupport, RoleAdmin},
+	ActionTransactionHistory: {RoleMerchantOwner, RoleSupport, RoleFinance, RoleAdmin, RoleService},
+	ActionTransactionReport:  {RoleAdmin, RoleService},
+	ActionPayoutCreate:       {RoleMerchantOwner, RoleFinance, RoleAdmin, RoleService},
+	ActionAdjustmentRead:     {RoleSupport, RoleFinance, RoleAdmin, RoleService},
+	ActionAdjustmentPropose:  {RoleFinance, RoleAdmin, RoleService},
+	ActionAdjustmentApprove:  {RoleFinance, RoleAdmin},
+	ActionDisputeOpen:        {RoleAdmin, RoleService},
+	ActionDisputeRead:        {RoleMerchantOwner, RoleMerchantViewer, RoleSupport, RoleFinance, RoleAdmin, RoleService},
+	ActionDisputeRespond:     {RoleMerchantOwner, RoleSupport, RoleAdmin, RoleService},
+	ActionDisputeResolve:     {RoleFinance, RoleAdmin, RoleService},
+	ActionReviewRead:         {RoleSupport, RoleAdmin, RoleService},
+	ActionReviewDecide:       {RoleSupport, RoleAdmin, RoleService},
+	ActionLimitRead:          {RoleSupport, RoleFinance, RoleAdmin, RoleService},
+	ActionLimitWrite:         {RoleFinance, RoleAdmin, RoleService},
+	ActionAPIKeyRead:         {RoleMerchantOwner, RoleSupport, RoleAdmin, RoleService},
+	ActionAPIKeyManage:       {RoleMerchantOwner, RoleAdmin, RoleService},
+	ActionPIIRead:            {RoleMerchantOwner, RoleSupport, RoleFinance, RoleAdmin, RoleService},
+	ActionPIIRotate:          {RoleAdmin},
+	ActionCustomerExport:     {RoleSupport, RoleAdmin, RoleService},
+	ActionCustomerErase:      {RoleAdmin, RoleService},
+}
+
+// Decision is the outcome of a policy check, kept for the audit log.
+type Decision struct {
+	Allowed bool
+	Action  string
+	Role    string // the role that granted access, if any
+	Reason  string
+}
+
+// Policy maps actions to the roles allowed to perform them. Anything not
+// listed is denied.
+type Policy struct {
+	rules map[string]map[string]bool
+}
+
+func NewPolicy(rules map[string][]string) (*Policy, error) {
+	p := &Policy{rules: map[string]map[string]bool{}}
+	for action, roles := range rules {
+		p.rules[action] = map[string]bool{}
+		for _, role := range roles {
+			if !knownRoles[role] {
+				return nil, fmt.Errorf("policy for %s: unknown role %q", action, role)
+			}
+			p.rules[action][role] = true
+		}
+	}
+	return p, nil
+}
+
+// LoadPolicy reads a JSON object of action to allowed roles, e.g.
+// {"transactions:refund": ["finance", "admin"]}. An empty path yields DefaultPolicy.
+func LoadPolicy(path string) (*Policy, error) {
+	if path == "" {
+		return NewPolicy(DefaultPolicy)
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read policy: %w", err)
+	}
+	var rules map[string][]string
+	if err := json.Unmarshal(raw, &rules); err != nil {
+		return nil, fmt.Errorf("parse policy: %w", err)
+	}
+	return NewPolicy(rules)
+}
+
+// Decide checks whether the principal may perform the action.
+func (p *Policy) Decide(principal *Principal, action string) Decision {
+	d := Decision{Action: action}
+	if principal == nil {
+		d.Reason = "unauthenticated"
+		return d
+	}
+	allowed, ok := p.rules[action]
+	if !ok {
+		d.Reason = "no policy for action"
+		return d
+	}
+	for _, role := range principal.Roles {
+		if allowed[role] {
+			d.Allowed = true
+			d.Role = role
+			d.Reason = "granted by role"
+			return d
+		}
+	}
+	d.Reason = "no permitted role"
+	return d
+}
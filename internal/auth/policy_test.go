@@ -0,0 +1,65 @@
+package auth
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDefaultPolicyDecisions(t *testing.T) {
+	policy, err := LoadPolicy("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	owner := &Principal{Kind: KindAPIKey, MerchantID: 1, Roles: []string{RoleMerchantOwner}}
+	viewer := &Principal{Kind: KindJWT, MerchantID: 1, Roles: []string{RoleMerchantViewer}}
+	support := &Principal{Kind: KindJWT, Roles: []string{RoleSupport}}
+
+	tests := []struct {
+		name      string
+		principal *Principal
+		action    string
+		allowed   bool
+	}{
+		{"owner refunds", owner, ActionTransactionRefund, true},
+		{"viewer reads", viewer, ActionTransactionRead, true},
+		{"viewer cannot refund", viewer, ActionTransactionRefund, false},
+		{"owner cannot open disputes", owner, ActionDisputeOpen, false},
+		{"support decides reviews", support, ActionReviewDecide, true},
+		{"support cannot write limits", support, ActionLimitWrite, false},
+		{"unauthenticated", nil, ActionTransactionRead, false},
+		{"unknown action", owner, "transactions:teleport", false},
+	}
+	for _, tt := range tests {
+		d := policy.Decide(tt.principal, tt.action)
+		if d.Allowed != tt.allowed {
+			t.Errorf("%s: allowed = %v (%s), want %v", tt.name, d.Allowed, d.Reason, tt.allowed)
+		}
+	}
+}
+
+func TestLoadPolicyFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "policy.json")
+	if err := os.WriteFile(path, []byte(`{"transactions:refund": ["finance"]}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	policy, err := LoadPolicy(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	owner := &Principal{MerchantID: 1, Roles: []string{RoleMerchantOwner}}
+	if policy.Decide(owner, ActionTransactionRefund).Allowed {
+		t.Error("policy file did not replace the default refund rule")
+	}
+	if d := policy.Decide(&Principal{Roles: []string{RoleFinance}}, ActionTransactionRefund); !d.Allowed || d.Role != RoleFinance {
+		t.Errorf("finance refund: %+v", d)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"transactions:refund": ["superuser"]}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadPolicy(path); err == nil {
+		t.Error("unknown role accepted")
+	}
+}
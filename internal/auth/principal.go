@@ -0,0 +1,99 @@
+package auth
+
+import "context"
+
+// Kinds of authenticated callers.
+const (
+	KindAPIKey   = "api_key"
+	KindInternal = "internal"
+	KindJWT      = "jwt"
+)
+
+// Roles of callers that are not bound to a merchant. Internal tokens act as
+// RoleService; the remaining roles are in policy.go.
+const (
+	RoleService = "service"
+	RoleAdmin   = "admin"
+)
+
+// API key modes. Test-mode keys create transactions that never reach the
+// ledger or settlement.
+const (
+	ModeTest = "test"
+	ModeLive = "live"
+)
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	Kind       string
+	Subject    string
+	MerchantID int // zero for internal callers, which are not bound to a merchant
+	KeyID      int
+	Mode       string
+	Roles      []string
+}
+
+// IsInternal reports whether the caller is another Kodra service or staff
+// with access across merchants. JWT callers qualify when their token names no
+// merchant and carries a staff role. What they may do is still up to the Policy.
+func (p *Principal) IsInternal() bool {
+	if p == nil {
+		return false
+	}
+	switch p.Kind {
+	case KindInternal:
+		return true
+	case KindJWT:
+		return p.MerchantID == 0 &&
+			(p.HasRole(RoleService) || p.HasRole(RoleAdmin) || p.HasRole(RoleSupport) || p.HasRole(RoleFinance))
+	}
+	return false
+}
+
+// HasRole reports whether the caller was granted the role.
+func (p *Principal) HasRole(role string) bool {
+	if p == nil {
+		return false
+	}
+	for _, r := range p.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// Livemode reports whether writes by this caller affect real money.
+func (p *Principal) Livemode() bool {
+	return p == nil || p.Mode != ModeTest
+}
+
+// CanAccessMerchant reports whether the caller may read or write data owned by the merchant.
+func (p *Principal) CanAccessMerchant(merchantID int) bool {
+	if p == nil {
+		return false
+	}
+	return p.IsInternal() || p.MerchantID == merchantID
+}
+
+// CanAccessTransaction reports whether the caller may read or act on a
+// transaction of the merchant created in the given mode. API keys only reach
+// transactions of their own mode, so a test key cannot touch live money.
+func (p *Principal) CanAccessTransaction(merchantID int, livemode bool) bool {
+	if !p.CanAccessMerchant(merchantID) {
+		return false
+	}
+	return p.Kind != KindAPIKey || p.Livemode() == livemode
+}
+
+type principalKey struct{}
+
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext returns the principal stored by WithPrincipal, or nil.
+func FromContext(ctx context.Context) *Principal {
+	p, _ := ctx.Value(principalKey{}).(*Principal)
+	return p
+}
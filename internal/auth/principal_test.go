@@ -0,0 +1,43 @@
+package auth
+
+import "testing"
+
+func TestPrincipalAccess(t *testing.T) {
+	internal := &Principal{Kind: KindInternal, Mode: ModeLive}
+	live := &Principal{Kind: KindAPIKey, MerchantID: 7, Mode: ModeLive}
+	test := &Principal{Kind: KindAPIKey, MerchantID: 7, Mode: ModeTest}
+	var none *Principal
+
+	tests := []struct {
+		name       string
+		p          *Principal
+		merchantID int
+		livemode   bool
+		merchant   bool
+		tx         bool
+	}{
+		{"internal", internal, 9, false, true, true},
+		{"live key, own live transaction", live, 7, true, true, true},
+		{"live key, own test transaction", live, 7, false, true, false},
+		{"test key, own test transaction", test, 7, false, true, true},
+		{"test key, own live transaction", test, 7, true, true, false},
+		{"other merchant", live, 8, true, false, false},
+		{"no principal", none, 7, true, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.p.CanAccessMerchant(tt.merchantID); got != tt.merchant {
+				t.Errorf("CanAccessMerchant(%d) = %v, want %v", tt.merchantID, got, tt.merchant)
+			}
+			if got := tt.p.CanAccessTransaction(tt.merchantID, tt.livemode); got != tt.tx {
+				t.Errorf("CanAccessTransaction(%d, %v) = %v, want %v", tt.merchantID, tt.livemode, got, tt.tx)
+			}
+		})
+	}
+	if !internal.IsInternal() || live.IsInternal() || none.IsInternal() {
+		t.Error("IsInternal is only true for internal callers")
+	}
+	if !live.Livemode() || test.Livemode() {
+		t.Error("Livemode follows the key's mode")
+	}
+}
@@ -0,0 +1,80 @@
+// Package background tracks the goroutines a request or worker leaves behind,
+// so shutdown can wait for them instead of cutting them off.
+package background
+
+import (
+	"context"
+	"sync"
+)
+
+// Tasks is a set of tracked goroutines. One-off work such as a balance update
+// is started with Go and allowed to finish on shutdown; long-running loops
+// should run under Context and return once shutdown begins.
+type Tasks struct {
+	mu      sync.Mutex
+	wg      sync.WaitGroup
+	closed  bool
+	stopped context.Context
+	stop    context.CancelFunc
+}
+
+func NewTasks() *Tasks {
+	stopped, stop := context.WithCancel(context.Background())
+	return &Tasks{stopped: stopped, stop: stop}
+}
+
+// Go runs fn in a tracked goroutine. Once Shutdown has been called fn runs
+// synchronously instead, so late work is still done before the caller returns.
+// A nil Tasks runs fn untracked.
+func (t *Tasks) Go(fn func()) {
+	if t == nil {
+		go fn()
+		return
+	}
+	t.mu.Lock()
+	if t.closed {
+		t.mu.Unlock()
+		fn()
+		return
+	}
+	t.wg.Add(1)
+	t.mu.Unlock()
+
+	go func() {
+		defer t.wg.Done()
+		fn()
+	}()
+}
+
+// Context returns a context cancelled when shutdown begins, for loops started
+// with Go.
+func (t *Tasks) Context() context.Context {
+	if t == nil {
+		return context.Background()
+	}
+	return t.stopped
+}
+
+// Shutdown signals long-running tasks to stop and waits for every tracked
+// task to return, or for ctx to end, whichever is first.
+func (t *Tasks) Shutdown(ctx context.Context) error {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	t.closed = true
+	t.mu.Unlock()
+	t.stop()
+
+	done := make(chan struct{})
+	go func() {
+		t.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
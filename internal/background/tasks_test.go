@@ -0,0 +1,68 @@
+package background
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestShutdownWaitsForTasks(t *testing.T) {
+	tasks := NewTasks()
+	var done atomic.Int32
+	release := make(chan struct{})
+	for i := 0; i < 3; i++ {
+		tasks.Go(func() {
+			<-release
+			done.Add(1)
+		})
+	}
+	loopStopped := make(chan struct{})
+	tasks.Go(func() {
+		<-tasks.Context().Done()
+		close(loopStopped)
+	})
+
+	time.AfterFunc(20*time.Millisecond, func() { close(release) })
+	if err := tasks.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if done.Load() != 3 {
+		t.Errorf("%d of 3 tasks finished before Shutdown returned", done.Load())
+	}
+	select {
+	case <-loopStopped:
+	default:
+		t.Error("long-running task was not told to stop")
+	}
+
+	ran := false
+	tasks.Go(func() { ran = true })
+	if !ran {
+		t.Error("task started after shutdown did not run before Go returned")
+	}
+}
+
+func TestShutdownGivesUpAtDeadline(t *testing.T) {
+	tasks := NewTasks()
+	block := make(chan struct{})
+	defer close(block)
+	tasks.Go(func() { <-block })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := tasks.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("shutdown with a stuck task: %v, want DeadlineExceeded", err)
+	}
+}
+
+func TestNilTasks(t *testing.T) {
+	var tasks *Tasks
+	done := make(chan struct{})
+	tasks.Go(func() { close(done) })
+	<-done
+	if tasks.Context().Err() != nil || tasks.Shutdown(context.Background()) != nil {
+		t.Error("nil Tasks is not a no-op")
+	}
+}
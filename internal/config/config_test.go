@@ -0,0 +1,79 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadDefaults(t *testing.T) {
+	cfg, err := Load("transaction-service", "7004")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Port != "7004" || cfg.Risk.VelocityWindow != time.Hour || !strings.Contains(cfg.Postgres.DSN, "sslmode=disable") {
+		t.Errorf("defaults: port %s, velocity window %s, dsn %s", cfg.Port, cfg.Risk.VelocityWindow, cfg.Postgres.DSN)
+	}
+}
+
+func TestLoadFileWithEnvironmentOverride(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(`
+port: 8080
+risk:
+  review_score: 40
+  blocked_bins: ["400000", "510510"]
+rate_limit:
+  merchant_overrides: "12:writes=300|reads=2000"
+`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("CONFIG_FILE", path)
+	t.Setenv("PORT", "9090")
+
+	cfg, err := Load("transaction-service", "7004")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Port != "9090" {
+		t.Errorf("port %s, want the environment's 9090", cfg.Port)
+	}
+	if cfg.Risk.ReviewScore != 40 || len(cfg.Risk.BlockedBINs) != 2 || cfg.Risk.BlockedBINs[1] != "510510" {
+		t.Errorf("risk from file: %+v", cfg.Risk)
+	}
+	if cfg.RateLimit.MerchantOverrides[12]["reads"] != 2000 {
+		t.Errorf("merchant overrides: %v", cfg.RateLimit.MerchantOverrides)
+	}
+}
+
+func TestLoadReportsEveryProblem(t *testing.T) {
+	t.Setenv("PORT", "http")
+	t.Setenv("RISK_VELOCITY_WINDOW", "an hour")
+	t.Setenv("RISK_REVIEW_SCORE", "95")
+	t.Setenv("POSTGRES_MAX_IDLE_CONNS", "50")
+
+	_, err := Load("transaction-service", "7004")
+	if err == nil {
+		t.Fatal("loaded an invalid configuration")
+	}
+	for _, key := range []string{"PORT", "RISK_VELOCITY_WINDOW", "RISK_REVIEW_SCORE", "POSTGRES_MAX_IDLE_CONNS"} {
+		if !strings.Contains(err.Error(), key+":") {
+			t.Errorf("error does not name %s:\n%v", key, err)
+		}
+	}
+}
+
+func TestLoadRedisURL(t *testing.T) {
+	t.Setenv("REDIS_URL", "rediss://:secret@cache.internal:6380/2")
+
+	cfg, err := Load("transaction-service", "7004")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := RedisConfig{Addr: "cache.internal:6380", Password: "secret", DB: 2, TLS: true, DialTimeout: 5 * time.Second}
+	if cfg.Redis != want {
+		t.Errorf("redis %+v, want %+v", cfg.Redis, want)
+	}
+}
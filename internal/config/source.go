@@ -0,0 +1,263 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// loader looks settings up by environment variable name, in the environment
+// first and then in the config file, and collects every invalid value it
+// meets instead of stopping at the first.
+type loader struct {
+	file map[string]string
+	errs []error
+}
+
+// getenv is os.Getenv with surrounding whitespace removed.
+func getenv(key string) string {
+	return strings.TrimSpace(os.Getenv(key))
+}
+
+// newLoader reads the optional YAML config file. Its keys are the environment
+// variable names in lower case, and may be nested by prefix, so
+//
+//	risk:
+//	  review_score: 40
+//	  blocked_bins: ["400000", "510510"]
+//
+// sets RISK_REVIEW_SCORE and RISK_BLOCKED_BINS. Lists are read as if they were
+// comma separated.
+func newLoader(path string) (*loader, error) {
+	l := &loader{file: map[string]string{}}
+	if path == "" {
+		return l, nil
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read config file: %w", err)
+	}
+	var doc map[string]interface{}
+	if err := yaml.Unmarshal(raw, &doc); err != nil {
+		return nil, fmt.Errorf("parse config file %s: %w", path, err)
+	}
+	flatten("", doc, l.file)
+	return l, nil
+}
+
+func flatten(prefix string, node map[string]interface{}, out map[string]string) {
+	for key, value := range node {
+		name := strings.ToUpper(key)
+		if prefix != "" {
+			name = prefix + "_" + name
+		}
+		switch v := value.(type) {
+		case map[string]interface{}:
+			flatten(name, v, out)
+		case []interface{}:
+			items := make([]string, len(v))
+			for i, item := range v {
+				items[i] = fmt.Sprint(item)
+			}
+			out[name] = strings.Join(items, ",")
+		case nil:
+		default:
+			out[name] = fmt.Sprint(v)
+		}
+	}
+}
+
+func (l *loader) lookup(key string) string {
+	if v := getenv(key); v != "" {
+		return v
+	}
+	return strings.TrimSpace(l.file[key])
+}
+
+func (l *loader) invalid(key, value, want string) {
+	l.errs = append(l.errs, fmt.Errorf("%s: %q is not %s", key, value, want))
+}
+
+func (l *loader) string(key, def string) string {
+	if v := l.lookup(key); v != "" {
+		return v
+	}
+	return def
+}
+
+func (l *loader) int(key string, def int) int {
+	v := l.lookup(key)
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		l.invalid(key, v, "an integer")
+		return def
+	}
+	return n
+}
+
+func (l *loader) bool(key string, def bool) bool {
+	v := l.lookup(key)
+	if v == "" {
+		return def
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		l.invalid(key, v, "true or false")
+		return def
+	}
+	return b
+}
+
+func (l *loader) float(key string, def float64) float64 {
+	v := l.lookup(key)
+	if v == "" {
+		return def
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		l.invalid(key, v, "a number")
+		return def
+	}
+	return f
+}
+
+func (l *loader) duration(key string, def time.Duration) time.Duration {
+	v := l.lookup(key)
+	if v == "" {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		l.invalid(key, v, "a duration such as 30s or 5m")
+		return def
+	}
+	return d
+}
+
+// list splits a comma separated setting, dropping empty items.
+func (l *loader) list(key string) []string {
+	var out []string
+	for _, item := range strings.Split(l.lookup(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// redis reads REDIS_ADDR, REDIS_PASSWORD, REDIS_DB and REDIS_TLS. REDIS_URL,
+// which the settlement publisher used to read, takes precedence when set.
+func (l *loader) redis() RedisConfig {
+	cfg := RedisConfig{
+		Addr:        l.string("REDIS_ADDR", "redis:6379"),
+		Password:    l.string("REDIS_PASSWORD", ""),
+		DB:          l.int("REDIS_DB", 0),
+		TLS:         l.bool("REDIS_TLS", false),
+		DialTimeout: l.duration("REDIS_DIAL_TIMEOUT", 5*time.Second),
+	}
+	raw := l.string("REDIS_URL", "")
+	if raw == "" {
+		return cfg
+	}
+	if !strings.HasPrefix(raw, "redis://") && !strings.HasPrefix(raw, "rediss://") {
+		cfg.Addr = raw
+		return cfg
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		l.invalid("REDIS_URL", raw, "a redis:// or rediss:// URL")
+		return cfg
+	}
+	cfg.Addr = u.Host
+	cfg.TLS = u.Scheme == "rediss"
+	if password, ok := u.User.Password(); ok {
+		cfg.Password = password
+	}
+	if db := strings.TrimPrefix(u.Path, "/"); db != "" {
+		n, err := strconv.Atoi(db)
+		if err != nil {
+			l.invalid("REDIS_URL", raw, "a URL with a numeric database")
+			return cfg
+		}
+		cfg.DB = n
+	}
+	return cfg
+}
+
+// merchantAmounts parses "12:500000,15:100000" (currency units) into
+// per-merchant amounts in minor units.
+func (l *loader) merchantAmounts(key string) map[int]int64 {
+	out := map[int]int64{}
+	for merchant, value := range l.merchantPairs(key) {
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			l.invalid(key, value, fmt.Sprintf("an amount (merchant %d)", merchant))
+			continue
+		}
+		out[merchant] = toMinorUnits(f)
+	}
+	return out
+}
+
+// merchantCurrencies parses "12:NGN|USD,15:GHS" into per-merchant currency lists.
+func (l *loader) merchantCurrencies(key string) map[int][]string {
+	out := map[int][]string{}
+	for merchant, value := range l.merchantPairs(key) {
+		for _, cur := range strings.Split(value, "|") {
+			if cur = strings.ToUpper(strings.TrimSpace(cur)); cur != "" {
+				out[merchant] = append(out[merchant], cur)
+			}
+		}
+	}
+	return out
+}
+
+// merchantRateLimits parses "12:writes=300|reads=2000,15:exports=50" into
+// per-merchant, per-class requests per minute.
+func (l *loader) merchantRateLimits(key string) map[int]map[string]int {
+	out := map[int]map[string]int{}
+	for merchant, value := range l.merchantPairs(key) {
+		for _, entry := range strings.Split(value, "|") {
+			class, limit, ok := strings.Cut(strings.TrimSpace(entry), "=")
+			n, err := strconv.Atoi(strings.TrimSpace(limit))
+			if !ok || err != nil || n < 0 {
+				l.invalid(key, entry, fmt.Sprintf("class=limit (merchant %d)", merchant))
+				continue
+			}
+			if out[merchant] == nil {
+				out[merchant] = map[string]int{}
+			}
+			out[merchant][strings.ToLower(strings.TrimSpace(class))] = n
+		}
+	}
+	return out
+}
+
+func (l *loader) merchantPairs(key string) map[int]string {
+	out := map[int]string{}
+	raw := l.lookup(key)
+	if raw == "" {
+		return out
+	}
+	for _, pair := range strings.Split(raw, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		id, value, ok := strings.Cut(strings.TrimSpace(pair), ":")
+		merchant, err := strconv.Atoi(strings.TrimSpace(id))
+		if !ok || err != nil {
+			l.invalid(key, pair, "merchant_id:value")
+			continue
+		}
+		out[merchant] = strings.TrimSpace(value)
+	}
+	return out
+}
@@ -0,0 +1,105 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// validate checks the values that parsed but cannot work, naming the setting
+// each problem comes from.
+func (c Config) validate() []error {
+	var errs []error
+	fail := func(key, format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf("%s: %s", key, fmt.Sprintf(format, args...)))
+	}
+	positive := func(key string, d time.Duration) {
+		if d <= 0 {
+			fail(key, "must be a positive duration, got %s", d)
+		}
+	}
+
+	if port, err := strconv.Atoi(c.Port); err != nil || port < 1 || port > 65535 {
+		fail("PORT", "%q is not a TCP port", c.Port)
+	}
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(c.LogLevel)); err != nil {
+		fail("LOG_LEVEL", "%q is not one of debug, info, warn or error", c.LogLevel)
+	}
+	if c.Storage != StoragePostgres && c.Storage != StorageMemory {
+		fail("STORAGE", "%q is not %s or %s", c.Storage, StoragePostgres, StorageMemory)
+	}
+
+	if u, err := url.Parse(c.Postgres.DSN); err != nil || (u.Scheme != "postgres" && u.Scheme != "postgresql") {
+		fail("POSTGRES_URL", "must be a postgres:// URL")
+	}
+	if c.Postgres.MaxOpenConns < 1 {
+		fail("POSTGRES_MAX_OPEN_CONNS", "must be at least 1, got %d", c.Postgres.MaxOpenConns)
+	}
+	if c.Postgres.MaxIdleConns < 0 || c.Postgres.MaxIdleConns > c.Postgres.MaxOpenConns {
+		fail("POSTGRES_MAX_IDLE_CONNS", "must be between 0 and POSTGRES_MAX_OPEN_CONNS (%d), got %d", c.Postgres.MaxOpenConns, c.Postgres.MaxIdleConns)
+	}
+	positive("POSTGRES_CONN_MAX_LIFETIME", c.Postgres.ConnMaxLifetime)
+
+	if c.Redis.Addr == "" || !strings.Contains(c.Redis.Addr, ":") {
+		fail("REDIS_ADDR", "%q is not host:port", c.Redis.Addr)
+	}
+	if c.Redis.DB < 0 {
+		fail("REDIS_DB", "must not be negative, got %d", c.Redis.DB)
+	}
+	positive("REDIS_DIAL_TIMEOUT", c.Redis.DialTimeout)
+
+	if u, err := url.Parse(c.MerchantService.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		fail("MERCHANT_SERVICE_URL", "%q is not an http(s) URL", c.MerchantService.URL)
+	}
+	positive("MERCHANT_SERVICE_TIMEOUT", c.MerchantService.Timeout)
+
+	positive("HTTP_READ_TIMEOUT", c.HTTP.ReadTimeout)
+	positive("HTTP_WRITE_TIMEOUT", c.HTTP.WriteTimeout)
+	positive("HTTP_IDLE_TIMEOUT", c.HTTP.IdleTimeout)
+	positive("SHUTDOWN_TIMEOUT", c.ShutdownTimeout)
+	positive("STARTUP_TIMEOUT", c.StartupTimeout)
+	positive("READYZ_CHECK_TIMEOUT", c.Health.CheckTimeout)
+	positive("ERASURE_POLL_INTERVAL", c.Privacy.ErasureInterval)
+	positive("RISK_VELOCITY_WINDOW", c.Risk.VelocityWindow)
+
+	if c.Risk.ReviewScore < 0 || c.Risk.ReviewScore > 100 {
+		fail("RISK_REVIEW_SCORE", "must be between 0 and 100, got %d", c.Risk.ReviewScore)
+	}
+	if c.Risk.BlockScore < 0 || c.Risk.BlockScore > 100 {
+		fail("RISK_BLOCK_SCORE", "must be between 0 and 100, got %d", c.Risk.BlockScore)
+	}
+	if c.Risk.ReviewScore > c.Risk.BlockScore {
+		fail("RISK_REVIEW_SCORE", "must not exceed RISK_BLOCK_SCORE (%d), got %d", c.Risk.BlockScore, c.Risk.ReviewScore)
+	}
+	for _, limit := range []struct {
+		key string
+		n   int
+	}{
+		{"RATE_LIMIT_READS", c.RateLimit.Reads},
+		{"RATE_LIMIT_WRITES", c.RateLimit.Writes},
+		{"RATE_LIMIT_EXPORTS", c.RateLimit.Exports},
+	} {
+		if limit.n < 0 {
+			fail(limit.key, "must not be negative, got %d", limit.n)
+		}
+	}
+
+	switch c.PII.KeyProvider {
+	case "local":
+	default:
+		fail("PII_KEY_PROVIDER", "%q is not a known key provider (local)", c.PII.KeyProvider)
+	}
+	switch c.Tracing.Exporter {
+	case "none", "stdout", "otlp":
+	default:
+		fail("OTEL_TRACES_EXPORTER", "%q is not one of none, stdout or otlp", c.Tracing.Exporter)
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		fail("OTEL_TRACES_SAMPLER_ARG", "must be between 0 and 1, got %v", c.Tracing.SampleRatio)
+	}
+	return errs
+}
@@ -1,34 +1,55 @@
 package dto
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/kodra-pay/transaction-service/internal/apperr"
+)
 
 // TransactionCreateRequest DTO for creating a new transaction
 type TransactionCreateRequest struct {
-	Reference     string `json:"reference,omitempty"`
-	MerchantID    int `json:"merchant_id"`
-	CustomerEmail string `json:"customer_email,omitempty"`
-	CustomerID    int `json:"customer_id"`
-	CustomerName  string `json:"customer_name,omitempty"`
-	Amount        float64  `json:"amount"` // currency units (e.g., NGN)
-	Currency      string `json:"currency"`
-	PaymentMethod string `json:"payment_method,omitempty"`
-	Description   string `json:"description,omitempty"`
-	Status        string `json:"status,omitempty"`
+	Reference     string  `json:"reference,omitempty" validate:"max=100"`
+	MerchantID    int     `json:"merchant_id" validate:"required,min=1"`
+	CustomerEmail string  `json:"customer_email,omitempty" validate:"email,max=254"`
+	CustomerID    int     `json:"customer_id" validate:"min=0"`
+	CustomerName  string  `json:"customer_name,omitempty" validate:"max=200"`
+	Amount        float64 `json:"amount" validate:"required,min=0.01,max=1000000000000,decimals=2"` // currency units (e.g., NGN)
+	Currency      string  `json:"currency" validate:"required,currency"`
+	PaymentMethod string  `json:"payment_method,omitempty" validate:"oneof=card bank_transfer ussd mobile_money"`
+	Description   string  `json:"description,omitempty" validate:"max=500"`
+	Status        string  `json:"status,omitempty" validate:"oneof=pending authorized success"` // initial status; defaults to success
+	CardBIN       string  `json:"card_bin,omitempty" validate:"numeric,min=6,max=8"`            // used for risk screening only, never stored
 }
 
 // TransactionResponse DTO for returning transaction information
 type TransactionResponse struct {
-	ID            int       `json:"id"`
-	Reference     string    `json:"reference"`
-	MerchantID    int       `json:"merchant_id"`
-	CustomerEmail string    `json:"customer_email"`
-	CustomerID    int       `json:"customer_id"`
-	CustomerName  string    `json:"customer_name,omitempty"`
-	Amount        float64     `json:"amount"` // currency units (e.g., NGN)
-	Currency      string    `json:"currency"`
-	Status        string    `json:"status"`
-	Description   string    `json:"description,omitempty"`
-	CreatedAt     time.Time `json:"created_at"`
+	ID            int                        `json:"id"`
+	Reference     string                     `json:"reference"`
+	MerchantID    int                        `json:"merchant_id"`
+	Type          string                     `json:"type"` // payment, payout, ...
+	CustomerEmail string                     `json:"customer_email"`
+	CustomerID    int                        `json:"customer_id"`
+	CustomerName  string                     `json:"customer_name,omitempty"`
+	Amount        float64                    `json:"amount"` // currency units (e.g., NGN)
+	Currency      string                     `json:"currency"`
+	Status        string                     `json:"status"`
+	Description   string                     `json:"description,omitempty"`
+	RiskScore     int                        `json:"risk_score"`
+	RiskDecision  string                     `json:"risk_decision,omitempty"`
+	RiskReasons   []string                   `json:"risk_reasons,omitempty"`
+	Livemode      bool                       `json:"livemode"`
+	CreatedAt     time.Time                  `json:"created_at"`
+	Timeline      []TransactionEventResponse `json:"timeline,omitempty"` // only with ?include=timeline
+}
+
+// PayoutCreateRequest DTO for paying out part of a merchant's balance
+type PayoutCreateRequest struct {
+	Reference   string  `json:"reference" validate:"required,max=100"`
+	MerchantID  int     `json:"merchant_id" validate:"required,min=1"`
+	Amount      float64 `json:"amount" validate:"required,min=0.01,max=1000000000000,decimals=2"` // currency units (e.g., NGN)
+	Currency    string  `json:"currency" validate:"required,currency"`
+	Description string  `json:"description,omitempty" validate:"max=500"`
 }
 
 // TransactionListResponse DTO for returning a list of transactions
@@ -36,3 +57,271 @@ type TransactionListResponse struct {
 	Transactions []TransactionResponse `json:"transactions"`
 	Total        int                   `json:"total"`
 }
+
+// DisputeCreateRequest DTO for opening a dispute against a transaction
+type DisputeCreateRequest struct {
+	Amount        float64   `json:"amount,omitempty" validate:"min=0.01,max=1000000000000,decimals=2"` // currency units; defaults to the full transaction amount
+	ReasonCode    string    `json:"reason_code" validate:"required,max=50"`
+	EvidenceDueBy time.Time `json:"evidence_due_by"`
+}
+
+// DisputeUpdateRequest DTO for moving a dispute to a new status
+type DisputeUpdateRequest struct {
+	Status string `json:"status" validate:"required,oneof=needs_response under_review won lost"`
+}
+
+// DisputeEvidenceRequest DTO for attaching evidence metadata to a dispute
+type DisputeEvidenceRequest struct {
+	Type        string `json:"type" validate:"required,max=50"`
+	Description string `json:"description,omitempty" validate:"max=1000"`
+	FileURL     string `json:"file_url,omitempty" validate:"url,max=2048"`
+	SubmittedBy string `json:"submitted_by,omitempty" validate:"max=100"`
+}
+
+// DisputeEvidenceResponse DTO for returning evidence metadata
+type DisputeEvidenceResponse struct {
+	ID          int       `json:"id"`
+	Type        string    `json:"type"`
+	Description string    `json:"description,omitempty"`
+	FileURL     string    `json:"file_url,omitempty"`
+	SubmittedBy string    `json:"submitted_by,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// DisputeResponse DTO for returning dispute information
+type DisputeResponse struct {
+	ID            int                       `json:"id"`
+	TransactionID int                       `json:"transaction_id"`
+	Reference     string                    `json:"reference"`
+	MerchantID    int                       `json:"merchant_id"`
+	Amount        float64                   `json:"amount"` // currency units (e.g., NGN)
+	Currency      string                    `json:"currency"`
+	ReasonCode    string                    `json:"reason_code"`
+	Status        string                    `json:"status"`
+	EvidenceDueBy time.Time                 `json:"evidence_due_by"`
+	Livemode      bool                      `json:"livemode"`
+	ResolvedAt    *time.Time                `json:"resolved_at,omitempty"`
+	Evidence      []DisputeEvidenceResponse `json:"evidence,omitempty"`
+	CreatedAt     time.Time                 `json:"created_at"`
+	UpdatedAt     time.Time                 `json:"updated_at"`
+}
+
+// DisputeListResponse DTO for returning a list of disputes
+type DisputeListResponse struct {
+	Disputes []DisputeResponse `json:"disputes"`
+	Total    int               `json:"total"`
+}
+
+// ReviewDecisionRequest DTO for claiming or deciding a manual review
+type ReviewDecisionRequest struct {
+	Notes string `json:"notes,omitempty" validate:"max=1000"`
+}
+
+// ReviewResponse DTO for returning a manual review and the transaction it holds
+type ReviewResponse struct {
+	ID          int                 `json:"id"`
+	Status      string              `json:"status"`
+	Reviewer    string              `json:"reviewer,omitempty"`
+	Notes       string              `json:"notes,omitempty"`
+	ClaimedAt   *time.Time          `json:"claimed_at,omitempty"`
+	DecidedAt   *time.Time          `json:"decided_at,omitempty"`
+	CreatedAt   time.Time           `json:"created_at"`
+	Transaction TransactionResponse `json:"transaction"`
+}
+
+// ReviewListResponse DTO for returning the review queue
+type ReviewListResponse struct {
+	Reviews []ReviewResponse `json:"reviews"`
+	Total   int              `json:"total"`
+}
+
+// AdjustmentCreateRequest DTO for proposing a manual credit or debit of a
+// merchant's balance. Amount is in currency units.
+type AdjustmentCreateRequest struct {
+	Reference  string  `json:"reference" validate:"required,max=100"`
+	MerchantID int     `json:"merchant_id" validate:"required,min=1"`
+	Direction  string  `json:"direction" validate:"required,oneof=credit debit"`
+	Amount     float64 `json:"amount" validate:"required,min=0.01,max=1000000000000,decimals=2"`
+	Currency   string  `json:"currency" validate:"required,currency"`
+	Reason     string  `json:"reason" validate:"required,max=500"`
+}
+
+// AdjustmentDecisionRequest DTO for approving or rejecting an adjustment
+type AdjustmentDecisionRequest struct {
+	Notes string `json:"notes,omitempty" validate:"max=1000"`
+}
+
+// AdjustmentResponse DTO for returning an adjustment and its transaction
+type AdjustmentResponse struct {
+	ID            int                 `json:"id"`
+	Direction     string              `json:"direction"`
+	Reason        string              `json:"reason"`
+	Status        string              `json:"status"`
+	ProposedBy    string              `json:"proposed_by"`
+	DecidedBy     string              `json:"decided_by,omitempty"`
+	DecisionNotes string              `json:"decision_notes,omitempty"`
+	DecidedAt     *time.Time          `json:"decided_at,omitempty"`
+	CreatedAt     time.Time           `json:"created_at"`
+	Transaction   TransactionResponse `json:"transaction"`
+}
+
+// AdjustmentListResponse DTO for returning a list of adjustments
+type AdjustmentListResponse struct {
+	Adjustments []AdjustmentResponse `json:"adjustments"`
+	Total       int                  `json:"total"`
+}
+
+// MerchantLimitRequest DTO for setting a merchant's limits in one currency.
+// Amounts are in currency units; 0 disables a cap.
+type MerchantLimitRequest struct {
+	Currency        string  `json:"currency" validate:"required,currency"`
+	Tier            string  `json:"tier,omitempty" validate:"max=50"`
+	MaxSingleAmount float64 `json:"max_single_amount" validate:"min=0,max=1000000000000,decimals=2"`
+	DailyVolume     float64 `json:"daily_volume" validate:"min=0,max=1000000000000,decimals=2"`
+	MonthlyVolume   float64 `json:"monthly_volume" validate:"min=0,max=1000000000000,decimals=2"`
+}
+
+// MerchantLimitResponse DTO for returning a merchant's limits and current usage
+type MerchantLimitResponse struct {
+	Currency        string    `json:"currency,omitempty"`
+	Tier            string    `json:"tier"`
+	MaxSingleAmount float64   `json:"max_single_amount"`
+	DailyVolume     float64   `json:"daily_volume"`
+	MonthlyVolume   float64   `json:"monthly_volume"`
+	DailyUsed       float64   `json:"daily_used"`
+	MonthlyUsed     float64   `json:"monthly_used"`
+	UpdatedAt       time.Time `json:"updated_at,omitempty"`
+}
+
+// MerchantLimitListResponse DTO for returning all limits of a merchant
+type MerchantLimitListResponse struct {
+	MerchantID int                     `json:"merchant_id"`
+	Defaults   MerchantLimitResponse   `json:"defaults"`
+	Limits     []MerchantLimitResponse `json:"limits"`
+}
+
+// ErrorResponse DTO every failed request is answered with
+type ErrorResponse struct {
+	Code      string              `json:"code"`
+	Message   string              `json:"message"`
+	Fields    []apperr.FieldError `json:"fields,omitempty"`
+	Details   interface{}         `json:"details,omitempty"`
+	RequestID string              `json:"request_id,omitempty"`
+}
+
+// LimitExceededDetails DTO explaining which limit rejected a transaction
+type LimitExceededDetails struct {
+	Limit     string  `json:"limit"`
+	Currency  string  `json:"currency"`
+	Max       float64 `json:"max"`
+	Current   float64 `json:"current"`
+	Attempted float64 `json:"attempted"`
+}
+
+// APIKeyCreateRequest DTO for issuing a merchant API key
+type APIKeyCreateRequest struct {
+	Mode string `json:"mode" validate:"required,oneof=test live"`
+}
+
+// APIKeyResponse DTO for returning API key metadata (never the key itself)
+type APIKeyResponse struct {
+	ID         int        `json:"id"`
+	MerchantID int        `json:"merchant_id"`
+	Prefix     string     `json:"prefix"`
+	Mode       string     `json:"mode"`
+	Status     string     `json:"status"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// APIKeyCreatedResponse DTO returned once when a key is issued or rotated
+type APIKeyCreatedResponse struct {
+	APIKeyResponse
+	Key string `json:"key"`
+}
+
+// APIKeyListResponse DTO for returning a merchant's API keys
+type APIKeyListResponse struct {
+	Keys []APIKeyResponse `json:"keys"`
+}
+
+// TransactionUpdateRequest DTO for an operator correcting transaction details.
+// Only the fields that are set are changed.
+type TransactionUpdateRequest struct {
+	Description   *string `json:"description,omitempty" validate:"max=500"`
+	CustomerEmail *string `json:"customer_email,omitempty" validate:"email,max=254"`
+	CustomerName  *string `json:"customer_name,omitempty" validate:"max=200"`
+	CustomerID    *int    `json:"customer_id,omitempty" validate:"min=0"`
+}
+
+// AuditEntryResponse DTO for one entry in a transaction's audit trail
+type AuditEntryResponse struct {
+	ID        int             `json:"id"`
+	Action    string          `json:"action"`
+	ActorKind string          `json:"actor_kind"`
+	Actor     string          `json:"actor"`
+	RequestID string          `json:"request_id,omitempty"`
+	Before    json.RawMessage `json:"before,omitempty"`
+	After     json.RawMessage `json:"after,omitempty"`
+	PrevHash  string          `json:"prev_hash"`
+	Hash      string          `json:"hash"`
+	Verified  bool            `json:"verified"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// TransactionHistoryResponse DTO for returning a transaction's audit trail
+type TransactionHistoryResponse struct {
+	Reference string               `json:"reference"`
+	Verified  bool                 `json:"verified"` // every entry's hash matches its content
+	Entries   []AuditEntryResponse `json:"entries"`
+}
+
+// TransactionEventRequest DTO for a status change reported by a worker or processor webhook
+type TransactionEventRequest struct {
+	Status   string                 `json:"status" validate:"required,max=50"`
+	Source   string                 `json:"source" validate:"required,oneof=worker webhook"`
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// TransactionEventResponse DTO for one entry of a transaction's status timeline
+type TransactionEventResponse struct {
+	FromStatus string          `json:"from_status,omitempty"`
+	ToStatus   string          `json:"to_status"`
+	Source     string          `json:"source"`
+	Metadata   json.RawMessage `json:"metadata,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// CustomerExportResponse DTO for a data subject export of a customer's transactions
+type CustomerExportResponse struct {
+	CustomerID   int                   `json:"customer_id,omitempty"`
+	Email        string                `json:"email,omitempty"`
+	GeneratedAt  time.Time             `json:"generated_at"`
+	Transactions []TransactionResponse `json:"transactions"`
+	Total        int                   `json:"total"`
+}
+
+// ErasureCreateRequest DTO for asking that a customer's PII be erased
+type ErasureCreateRequest struct {
+	CustomerID int    `json:"customer_id,omitempty" validate:"min=0"`
+	Email      string `json:"email,omitempty" validate:"email,max=254"`
+	Reason     string `json:"reason,omitempty" validate:"max=500"`
+}
+
+// ErasureResponse DTO for returning an erasure request and, once it has run, its verification
+type ErasureResponse struct {
+	ID                    int        `json:"id"`
+	CustomerID            int        `json:"customer_id,omitempty"`
+	Status                string     `json:"status"`
+	RequestedBy           string     `json:"requested_by"`
+	Reason                string     `json:"reason,omitempty"`
+	TransactionsAffected  int        `json:"transactions_affected"`
+	LedgerEntriesAffected int        `json:"ledger_entries_affected"`
+	Error                 string     `json:"error,omitempty"`
+	Verified              *bool      `json:"verified,omitempty"`               // set once completed: no transaction still links to the customer
+	RemainingTransactions *int       `json:"remaining_transactions,omitempty"` // set once completed
+	StartedAt             *time.Time `json:"started_at,omitempty"`
+	CompletedAt           *time.Time `json:"completed_at,omitempty"`
+	CreatedAt             time.Time  `json:"created_at"`
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/kodra-pay/transaction-service/internal/apperr"
+	"github.com/kodra-pay/transaction-service/internal/auth"
+	"github.com/kodra-pay/transaction-service/internal/dto"
+	"github.com/kodra-pay/transaction-service/internal/services"
+)
+
+type AdjustmentHandler struct {
+	svc    *services.AdjustmentService
+	policy *auth.Policy
+}
+
+func NewAdjustmentHandler(svc *services.AdjustmentService, policy *auth.Policy) *AdjustmentHandler {
+	return &AdjustmentHandler{svc: svc, policy: policy}
+}
+
+func (h *AdjustmentHandler) Create(c *fiber.Ctx) error {
+	if err := authorize(c, h.policy, auth.ActionAdjustmentPropose); err != nil {
+		return err
+	}
+	var req dto.AdjustmentCreateRequest
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+	resp, err := h.svc.Propose(c.UserContext(), req)
+	if err != nil {
+		return serviceError(err, "failed to propose adjustment")
+	}
+	redactAdjustments(c, h.policy, &resp)
+	return c.Status(fiber.StatusCreated).JSON(resp)
+}
+
+func (h *AdjustmentHandler) List(c *fiber.Ctx) error {
+	if err := authorize(c, h.policy, auth.ActionAdjustmentRead); err != nil {
+		return err
+	}
+	limit, err := listLimit(c)
+	if err != nil {
+		return err
+	}
+	resp, err := h.svc.List(c.UserContext(), c.QueryInt("merchant_id", 0), c.Query("status"), limit)
+	if err != nil {
+		return apperr.Internal("failed to list adjustments", err)
+	}
+	redactAdjustmentList(c, h.policy, &resp)
+	return c.JSON(resp)
+}
+
+func (h *AdjustmentHandler) Get(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return apperr.Invalid("id", "must be a positive integer")
+	}
+	if err := authorize(c, h.policy, auth.ActionAdjustmentRead); err != nil {
+		return err
+	}
+	resp, err := h.svc.Get(c.UserContext(), id)
+	if err != nil {
+		return serviceError(err, "failed to fetch adjustment")
+	}
+	redactAdjustments(c, h.policy, &resp)
+	return c.JSON(resp)
+}
+
+func (h *AdjustmentHandler) Approve(c *fiber.Ctx) error {
+	id, req, err := h.parseDecision(c)
+	if err != nil {
+		return err
+	}
+	resp, err := h.svc.Approve(c.UserContext(), id, req)
+	if err != nil {
+		return serviceError(err, "failed to approve adjustment")
+	}
+	redactAdjustments(c, h.policy, &resp)
+	return c.JSON(resp)
+}
+
+func (h *AdjustmentHandler) Reject(c *fiber.Ctx) error {
+	id, req, err := h.parseDecision(c)
+	if err != nil {
+		return err
+	}
+	resp, err := h.svc.Reject(c.UserContext(), id, req)
+	if err != nil {
+		return serviceError(err, "failed to reject adjustment")
+	}
+	redactAdjustments(c, h.policy, &resp)
+	return c.JSON(resp)
+}
+
+// parseDecision authorizes the caller to decide adjustments and reads the adjustment id and body.
+func (h *AdjustmentHandler) parseDecision(c *fiber.Ctx) (int, dto.AdjustmentDecisionRequest, error) {
+	var req dto.AdjustmentDecisionRequest
+	if err := authorize(c, h.policy, auth.ActionAdjustmentApprove); err != nil {
+		return 0, req, err
+	}
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return 0, req, apperr.Invalid("id", "must be a positive integer")
+	}
+	// Notes are optional, so a decision may come without a body.
+	if len(c.Body()) > 0 {
+		if err := parseBody(c, &req); err != nil {
+			return 0, req, err
+		}
+	}
+	return id, req, nil
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/kodra-pay/transaction-service/internal/apperr"
+	"github.com/kodra-pay/transaction-service/internal/auth"
+	"github.com/kodra-pay/transaction-service/internal/dto"
+	"github.com/kodra-pay/transaction-service/internal/middleware"
+	"github.com/kodra-pay/transaction-service/internal/services"
+)
+
+type APIKeyHandler struct {
+	svc    *services.APIKeyService
+	policy *auth.Policy
+}
+
+func NewAPIKeyHandler(svc *services.APIKeyService, policy *auth.Policy) *APIKeyHandler {
+	return &APIKeyHandler{svc: svc, policy: policy}
+}
+
+// Create issues a key for the merchant in the path. Internal callers only.
+func (h *APIKeyHandler) Create(c *fiber.Ctx) error {
+	if err := authorize(c, h.policy, auth.ActionAPIKeyManage); err != nil {
+		return err
+	}
+	merchantID, err := c.ParamsInt("merchant_id")
+	if err != nil || merchantID <= 0 {
+		return apperr.Invalid("merchant_id", "must be a positive integer")
+	}
+	// Checked here as well as by the route, so the route cannot be moved out
+	// of the internal group without merchants being able to mint each other keys.
+	if !middleware.Principal(c).CanAccessMerchant(merchantID) {
+		return apperr.Forbidden("merchant_id does not match credentials")
+	}
+	var req dto.APIKeyCreateRequest
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+	resp, err := h.svc.Create(c.UserContext(), merchantID, req.Mode)
+	if err != nil {
+		return serviceError(err, "failed to create api key")
+	}
+	return c.Status(fiber.StatusCreated).JSON(resp)
+}
+
+// List returns the keys of the merchant in the path for internal callers, or
+// the caller's own keys otherwise.
+func (h *APIKeyHandler) List(c *fiber.Ctx) error {
+	if err := authorize(c, h.policy, auth.ActionAPIKeyRead); err != nil {
+		return err
+	}
+	p := middleware.Principal(c)
+	merchantID := p.MerchantID
+	if p.IsInternal() {
+		id, err := c.ParamsInt("merchant_id")
+		if err != nil || id <= 0 {
+			return apperr.Invalid("merchant_id", "must be a positive integer")
+		}
+		merchantID = id
+	}
+	resp, err := h.svc.List(c.UserContext(), merchantID)
+	if err != nil {
+		return apperr.Internal("failed to list api keys", err)
+	}
+	return c.JSON(resp)
+}
+
+// Rotate replaces the key used to authenticate this request.
+func (h *APIKeyHandler) Rotate(c *fiber.Ctx) error {
+	if err := authorize(c, h.policy, auth.ActionAPIKeyManage); err != nil {
+		return err
+	}
+	resp, err := h.svc.Rotate(c.UserContext(), middleware.Principal(c))
+	if err != nil {
+		return serviceError(err, "failed to rotate api key")
+	}
+	return c.Status(fiber.StatusCreated).JSON(resp)
+}
+
+func (h *APIKeyHandler) Revoke(c *fiber.Ctx) error {
+	if err := authorize(c, h.policy, auth.ActionAPIKeyManage); err != nil {
+		return err
+	}
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return apperr.Invalid("id", "must be a positive integer")
+	}
+	// Another merchant's key is reported as missing rather than forbidden.
+	key, err := h.svc.Get(c.UserContext(), id)
+	if err != nil {
+		return serviceError(err, "failed to fetch api key")
+	}
+	if !middleware.Principal(c).CanAccessMerchant(key.MerchantID) {
+		return services.ErrAPIKeyNotFound
+	}
+	if err := h.svc.Revoke(c.UserContext(), middleware.Principal(c), id); err != nil {
+		return serviceError(err, "failed to revoke api key")
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/kodra-pay/transaction-service/internal/apperr"
+	"github.com/kodra-pay/transaction-service/internal/auth"
+	"github.com/kodra-pay/transaction-service/internal/logging"
+	"github.com/kodra-pay/transaction-service/internal/middleware"
+)
+
+// authorize consults the policy for the caller and logs every decision, allowed
+// or denied, so access to sensitive operations can be audited.
+func authorize(c *fiber.Ctx, policy *auth.Policy, action string) error {
+	if !allowed(c, policy, action) {
+		return apperr.Forbidden("not permitted to perform " + action)
+	}
+	return nil
+}
+
+// allowed is authorize for checks that change what a response contains
+// rather than whether the request may proceed.
+func allowed(c *fiber.Ctx, policy *auth.Policy, action string) bool {
+	p := middleware.Principal(c)
+	d := policy.Decide(p, action)
+
+	outcome := "deny"
+	if d.Allowed {
+		outcome = "allow"
+	}
+	var kind, subject string
+	var merchantID int
+	var roles []string
+	if p != nil {
+		kind, subject, merchantID, roles = p.Kind, p.Subject, p.MerchantID, p.Roles
+	}
+	logging.FromContext(c.UserContext()).InfoContext(c.UserContext(), "authz decision",
+		"decision", outcome, "action", action, "kind", kind, "subject", subject,
+		"merchant_id", merchantID, "roles", roles, "granted_by", d.Role, "reason", d.Reason,
+		"method", c.Method(), "path", c.Path())
+
+	return d.Allowed
+}
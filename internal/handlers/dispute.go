@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/kodra-pay/transaction-service/internal/apperr"
+	"github.com/kodra-pay/transaction-service/internal/auth"
+	"github.com/kodra-pay/transaction-service/internal/dto"
+	"github.com/kodra-pay/transaction-service/internal/middleware"
+	"github.com/kodra-pay/transaction-service/internal/models"
+	"github.com/kodra-pay/transaction-service/internal/services"
+)
+
+type DisputeHandler struct {
+	svc    *services.DisputeService
+	policy *auth.Policy
+}
+
+func NewDisputeHandler(svc *services.DisputeService, policy *auth.Policy) *DisputeHandler {
+	return &DisputeHandler{svc: svc, policy: policy}
+}
+
+func (h *DisputeHandler) Create(c *fiber.Ctx) error {
+	ref := c.Params("reference")
+	if ref == "" {
+		return apperr.Invalid("reference", "is required")
+	}
+	if err := authorize(c, h.policy, auth.ActionDisputeOpen); err != nil {
+		return err
+	}
+	var req dto.DisputeCreateRequest
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+
+	resp, err := h.svc.Open(c.UserContext(), ref, req)
+	if err != nil {
+		return serviceError(err, "failed to open dispute")
+	}
+	return c.Status(fiber.StatusCreated).JSON(resp)
+}
+
+func (h *DisputeHandler) Get(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return apperr.Invalid("id", "must be a positive integer")
+	}
+	if err := authorize(c, h.policy, auth.ActionDisputeRead); err != nil {
+		return err
+	}
+	resp, err := h.svc.Get(c.UserContext(), id)
+	if err != nil {
+		return serviceError(err, "failed to fetch dispute")
+	}
+	if !middleware.Principal(c).CanAccessTransaction(resp.MerchantID, resp.Livemode) {
+		return services.ErrDisputeNotFound
+	}
+	return c.JSON(resp)
+}
+
+func (h *DisputeHandler) List(c *fiber.Ctx) error {
+	if err := authorize(c, h.policy, auth.ActionDisputeRead); err != nil {
+		return err
+	}
+	limit, err := listLimit(c)
+	if err != nil {
+		return err
+	}
+	merchantID := c.QueryInt("merchant_id", 0)
+	var livemode *bool
+	if p := middleware.Principal(c); !p.IsInternal() {
+		merchantID = p.MerchantID
+		mode := p.Livemode()
+		livemode = &mode
+	}
+	resp, err := h.svc.List(c.UserContext(), merchantID, c.Query("status"), livemode, limit)
+	if err != nil {
+		return apperr.Internal("failed to list disputes", err)
+	}
+	return c.JSON(resp)
+}
+
+func (h *DisputeHandler) Update(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return apperr.Invalid("id", "must be a positive integer")
+	}
+	var req dto.DisputeUpdateRequest
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+
+	// Submitting a response is the merchant's side; deciding the outcome is not.
+	action := auth.ActionDisputeResolve
+	if req.Status == models.DisputeStatusUnderReview {
+		action = auth.ActionDisputeRespond
+	}
+	if err := authorize(c, h.policy, action); err != nil {
+		return err
+	}
+	if !middleware.Principal(c).IsInternal() {
+		if err := h.authorize(c, id); err != nil {
+			return err
+		}
+	}
+
+	resp, err := h.svc.UpdateStatus(c.UserContext(), id, req.Status)
+	if err != nil {
+		return serviceError(err, "failed to update dispute")
+	}
+	return c.JSON(resp)
+}
+
+func (h *DisputeHandler) AddEvidence(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return apperr.Invalid("id", "must be a positive integer")
+	}
+	var req dto.DisputeEvidenceRequest
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+
+	if err := authorize(c, h.policy, auth.ActionDisputeRespond); err != nil {
+		return err
+	}
+	p := middleware.Principal(c)
+	if !p.IsInternal() {
+		if err := h.authorize(c, id); err != nil {
+			return err
+		}
+		req.SubmittedBy = p.Subject
+	}
+
+	resp, err := h.svc.AddEvidence(c.UserContext(), id, req)
+	if err != nil {
+		return serviceError(err, "failed to attach evidence")
+	}
+	return c.Status(fiber.StatusCreated).JSON(resp)
+}
+
+// authorize checks that the dispute exists and belongs to the caller, in the
+// caller's mode.
+func (h *DisputeHandler) authorize(c *fiber.Ctx, id int) error {
+	d, err := h.svc.Get(c.UserContext(), id)
+	if err != nil {
+		return serviceError(err, "failed to fetch dispute")
+	}
+	if !middleware.Principal(c).CanAccessTransaction(d.MerchantID, d.Livemode) {
+		return services.ErrDisputeNotFound
+	}
+	return nil
+}
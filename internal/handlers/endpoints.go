@@ -1,88 +1,271 @@
 package handlers
 
 import (
-	"fmt"
+	"context"
 
 	"github.com/gofiber/fiber/v2"
 
+	"github.com/kodra-pay/transaction-service/internal/apperr"
+	"github.com/kodra-pay/transaction-service/internal/auth"
 	"github.com/kodra-pay/transaction-service/internal/dto"
+	"github.com/kodra-pay/transaction-service/internal/middleware"
 	"github.com/kodra-pay/transaction-service/internal/services"
+	"github.com/kodra-pay/transaction-service/internal/validate"
 )
 
 type TransactionHandler struct {
-	svc *services.TransactionService
+	svc    *services.TransactionService
+	policy *auth.Policy
 }
 
-func NewTransactionHandler(svc *services.TransactionService) *TransactionHandler {
-	return &TransactionHandler{svc: svc}
+func NewTransactionHandler(svc *services.TransactionService, policy *auth.Policy) *TransactionHandler {
+	return &TransactionHandler{svc: svc, policy: policy}
 }
 
 func (h *TransactionHandler) Create(c *fiber.Ctx) error {
+	if err := authorize(c, h.policy, auth.ActionTransactionCreate); err != nil {
+		return err
+	}
 	var req dto.TransactionCreateRequest
 	if err := c.BodyParser(&req); err != nil {
-		return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("invalid request body: %v", err))
+		return invalidBody(err)
+	}
+
+	// Merchants can only create transactions for themselves; internal callers name the merchant.
+	p := middleware.Principal(c)
+	if !p.IsInternal() {
+		if req.MerchantID != 0 && req.MerchantID != p.MerchantID {
+			return apperr.Forbidden("merchant_id does not match credentials")
+		}
+		req.MerchantID = p.MerchantID
+	}
+	if err := validate.Struct(&req); err != nil {
+		return err
+	}
+
+	resp, err := h.svc.Create(c.UserContext(), req)
+	if err != nil {
+		return serviceError(err, "failed to create transaction")
+	}
+	redactTransactions(c, h.policy, &resp)
+	return c.Status(fiber.StatusCreated).JSON(resp)
+}
+
+// CreatePayout pays out part of a merchant's balance. Like Create, merchants
+// can only pay out their own balance.
+func (h *TransactionHandler) CreatePayout(c *fiber.Ctx) error {
+	if err := authorize(c, h.policy, auth.ActionPayoutCreate); err != nil {
+		return err
+	}
+	var req dto.PayoutCreateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return invalidBody(err)
+	}
+	p := middleware.Principal(c)
+	if !p.IsInternal() {
+		if req.MerchantID != 0 && req.MerchantID != p.MerchantID {
+			return apperr.Forbidden("merchant_id does not match credentials")
+		}
+		req.MerchantID = p.MerchantID
 	}
-	if req.MerchantID == 0 || req.Amount <= 0 {
-		return fiber.NewError(fiber.StatusBadRequest, "merchant_id and positive amount are required")
+	if err := validate.Struct(&req); err != nil {
+		return err
 	}
 
-	resp, err := h.svc.Create(c.Context(), req)
+	resp, err := h.svc.CreatePayout(c.UserContext(), req)
 	if err != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("failed to create transaction: %v", err))
+		return serviceError(err, "failed to create payout")
 	}
+	redactTransactions(c, h.policy, &resp)
 	return c.Status(fiber.StatusCreated).JSON(resp)
 }
 
 func (h *TransactionHandler) Get(c *fiber.Ctx) error {
 	ref := c.Params("reference") // Use c.Params
 	if ref == "" {
-		return fiber.NewError(fiber.StatusBadRequest, "reference is required")
+		return apperr.Invalid("reference", "is required")
+	}
+	if err := authorize(c, h.policy, auth.ActionTransactionRead); err != nil {
+		return err
 	}
-	resp, err := h.svc.Get(c.Context(), ref) // Pass string
+	resp, err := h.svc.Get(c.UserContext(), ref) // Pass string
 	if err != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, "failed to fetch transaction")
+		return apperr.Internal("failed to fetch transaction", err)
+	}
+	// Another merchant's transaction, or one of the other mode, is reported as
+	// missing rather than forbidden.
+	if resp.Reference == "" || !middleware.Principal(c).CanAccessTransaction(resp.MerchantID, resp.Livemode) {
+		return services.ErrTransactionNotFound
 	}
-	if resp.Reference == "" { // Check for empty string
-		return fiber.NewError(fiber.StatusNotFound, "transaction not found")
+	if c.Query("include") == "timeline" {
+		timeline, err := h.svc.Timeline(c.UserContext(), resp.ID)
+		if err != nil {
+			return apperr.Internal("failed to fetch transaction timeline", err)
+		}
+		resp.Timeline = timeline
 	}
+	redactTransactions(c, h.policy, &resp)
 	return c.JSON(resp)
 }
 
 func (h *TransactionHandler) List(c *fiber.Ctx) error {
-	limit := c.QueryInt("limit", 50) // Default limit to 50
-
+	limit, err := listLimit(c)
+	if err != nil {
+		return err
+	}
 	status := c.Query("status")
-	if status != "" {
-		resp, err := h.svc.ListByStatus(c.Context(), status, limit)
+
+	if err := authorize(c, h.policy, auth.ActionTransactionRead); err != nil {
+		return err
+	}
+
+	p := middleware.Principal(c)
+	if !p.IsInternal() {
+		livemode := p.Livemode()
+		resp, err := h.svc.ListByMerchant(c.UserContext(), p.MerchantID, status, &livemode, limit)
 		if err != nil {
-			return fiber.NewError(fiber.StatusInternalServerError, "failed to list transactions by status")
+			return apperr.Internal("failed to list transactions by merchant", err)
 		}
+		redactTransactionList(c, h.policy, &resp)
 		return c.JSON(resp)
 	}
 
 	merchantID := c.QueryInt("merchant_id", 0)
+	if status != "" && merchantID == 0 {
+		if err := authorize(c, h.policy, auth.ActionTransactionListAll); err != nil {
+			return err
+		}
+		resp, err := h.svc.ListByStatus(c.UserContext(), status, limit)
+		if err != nil {
+			return apperr.Internal("failed to list transactions by status", err)
+		}
+		redactTransactionList(c, h.policy, &resp)
+		return c.JSON(resp)
+	}
+
 	if merchantID == 0 {
-		return fiber.NewError(fiber.StatusBadRequest, "merchant_id is required unless filtering by status")
+		return apperr.Invalid("merchant_id", "is required unless filtering by status")
 	}
-	resp, err := h.svc.ListByMerchant(c.Context(), merchantID, limit)
+	resp, err := h.svc.ListByMerchant(c.UserContext(), merchantID, status, nil, limit)
 	if err != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, "failed to list transactions by merchant")
+		return apperr.Internal("failed to list transactions by merchant", err)
 	}
+	redactTransactionList(c, h.policy, &resp)
 	return c.JSON(resp)
 }
 
 func (h *TransactionHandler) Capture(c *fiber.Ctx) error {
-	ref := c.Params("reference") // Use c.Params
+	return h.changeStatus(c, auth.ActionTransactionCapture, h.svc.Capture)
+}
+
+func (h *TransactionHandler) Refund(c *fiber.Ctx) error {
+	return h.changeStatus(c, auth.ActionTransactionRefund, h.svc.Refund)
+}
+
+func (h *TransactionHandler) Void(c *fiber.Ctx) error {
+	return h.changeStatus(c, auth.ActionTransactionVoid, h.svc.Void)
+}
+
+// ReportStatus records a status change reported by a background worker or
+// a payment processor webhook.
+func (h *TransactionHandler) ReportStatus(c *fiber.Ctx) error {
+	ref := c.Params("reference")
 	if ref == "" {
-		return fiber.NewError(fiber.StatusBadRequest, "reference is required")
+		return apperr.Invalid("reference", "is required")
+	}
+	if err := authorize(c, h.policy, auth.ActionTransactionReport); err != nil {
+		return err
+	}
+	var req dto.TransactionEventRequest
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+	resp, err := h.svc.ApplyStatusUpdate(c.UserContext(), ref, req)
+	if err != nil {
+		return serviceError(err, "failed to update transaction")
 	}
-	return c.JSON(h.svc.Capture(c.Context(), ref))
+	redactTransactions(c, h.policy, &resp)
+	return c.JSON(resp)
 }
 
-func (h *TransactionHandler) Refund(c *fiber.Ctx) error {
+// ReencryptPII starts moving all stored customer PII onto the current key
+// after a key rotation. The work runs in the background.
+func (h *TransactionHandler) ReencryptPII(c *fiber.Ctx) error {
+	if err := authorize(c, h.policy, auth.ActionPIIRotate); err != nil {
+		return err
+	}
+	h.svc.StartPIIReencryption(c.UserContext())
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{"status": "started"})
+}
+
+// Update lets an operator correct a transaction's descriptive fields.
+func (h *TransactionHandler) Update(c *fiber.Ctx) error {
+	ref := c.Params("reference")
+	if ref == "" {
+		return apperr.Invalid("reference", "is required")
+	}
+	if err := authorize(c, h.policy, auth.ActionTransactionEdit); err != nil {
+		return err
+	}
+	var req dto.TransactionUpdateRequest
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+	resp, err := h.svc.UpdateDetails(c.UserContext(), ref, req)
+	if err != nil {
+		return serviceError(err, "failed to update transaction")
+	}
+	redactTransactions(c, h.policy, &resp)
+	return c.JSON(resp)
+}
+
+// History returns the audit trail of a transaction.
+func (h *TransactionHandler) History(c *fiber.Ctx) error {
+	ref := c.Params("reference")
+	if ref == "" {
+		return apperr.Invalid("reference", "is required")
+	}
+	if err := authorize(c, h.policy, auth.ActionTransactionHistory); err != nil {
+		return err
+	}
+	if err := h.authorizeReference(c, ref); err != nil {
+		return err
+	}
+	resp, err := h.svc.History(c.UserContext(), ref)
+	if err != nil {
+		return apperr.Internal("failed to fetch transaction history", err)
+	}
+	return c.JSON(resp)
+}
+
+func (h *TransactionHandler) changeStatus(c *fiber.Ctx, action string, change func(context.Context, string) (dto.TransactionResponse, error)) error {
 	ref := c.Params("reference") // Use c.Params
 	if ref == "" {
-		return fiber.NewError(fiber.StatusBadRequest, "reference is required")
+		return apperr.Invalid("reference", "is required")
+	}
+	if err := authorize(c, h.policy, action); err != nil {
+		return err
+	}
+	if err := h.authorizeReference(c, ref); err != nil {
+		return err
+	}
+	resp, err := change(c.UserContext(), ref)
+	if err != nil {
+		return serviceError(err, "failed to update transaction")
+	}
+	redactTransactions(c, h.policy, &resp)
+	return c.JSON(resp)
+}
+
+// authorizeReference checks that the transaction exists and belongs to the
+// caller, in the caller's mode.
+func (h *TransactionHandler) authorizeReference(c *fiber.Ctx, ref string) error {
+	tx, err := h.svc.Get(c.UserContext(), ref)
+	if err != nil {
+		return apperr.Internal("failed to fetch transaction", err)
+	}
+	if tx.Reference == "" || !middleware.Principal(c).CanAccessTransaction(tx.MerchantID, tx.Livemode) {
+		return services.ErrTransactionNotFound
 	}
-	return c.JSON(h.svc.Refund(c.Context(), ref))
+	return nil
 }
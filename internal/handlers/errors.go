@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/kodra-pay/transaction-service/internal/apperr"
+	"github.com/kodra-pay/transaction-service/internal/dto"
+	"github.com/kodra-pay/transaction-service/internal/requestctx"
+	"github.com/kodra-pay/transaction-service/internal/services"
+	"github.com/kodra-pay/transaction-service/internal/validate"
+)
+
+// ErrorHandler answers every failed request with a dto.ErrorResponse. It is
+// the app's fiber.Config.ErrorHandler, so it also covers errors raised by
+// middleware and by Fiber itself, such as unknown routes. Internal errors are
+// logged by the access log; clients only see their generic message.
+func ErrorHandler(c *fiber.Ctx, err error) error {
+	e := apperr.From(err)
+	return c.Status(apperr.Status(err)).JSON(dto.ErrorResponse{
+		Code:      string(e.Code),
+		Message:   e.Message,
+		Fields:    e.Fields,
+		Details:   e.Details,
+		RequestID: requestctx.RequestID(c.UserContext()),
+	})
+}
+
+// serviceError passes a service's domain errors through to ErrorHandler and
+// hides anything else, such as database failures, behind msg.
+func serviceError(err error, msg string) error {
+	var limitErr *services.LimitExceededError
+	if errors.As(err, &limitErr) {
+		return limitExceeded(limitErr)
+	}
+	var e *apperr.Error
+	if errors.As(err, &e) {
+		return err
+	}
+	return apperr.Internal(msg, err)
+}
+
+// invalidBody reports a request body that could not be decoded.
+func invalidBody(err error) error {
+	return apperr.Wrap(apperr.CodeInvalidRequest, "invalid request body", err)
+}
+
+// parseBody decodes the request body into out and checks it against the
+// rules in its validate tags.
+func parseBody(c *fiber.Ctx, out interface{}) error {
+	if err := c.BodyParser(out); err != nil {
+		return invalidBody(err)
+	}
+	return validate.Struct(out)
+}
@@ -1,19 +1,85 @@
 package handlers
 
-import "github.com/gofiber/fiber/v2"
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// HealthCheck reports whether a dependency the service needs to serve
+// traffic is usable.
+type HealthCheck struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
 
 type HealthHandler struct {
 	Service string
+	timeout time.Duration
+	checks  []HealthCheck
 }
 
-func NewHealthHandler(service string) *HealthHandler {
-	return &HealthHandler{Service: service}
+// NewHealthHandler serves liveness and readiness. Each readiness check gets
+// its own timeout so one hung dependency cannot stall the probe.
+func NewHealthHandler(service string, timeout time.Duration, checks ...HealthCheck) *HealthHandler {
+	return &HealthHandler{Service: service, timeout: timeout, checks: checks}
 }
 
 func (h *HealthHandler) Register(r fiber.Router) {
 	r.Get("/health", h.Health)
+	r.Get("/livez", h.Health)
+	r.Get("/readyz", h.Ready)
 }
 
+// Health reports that the process is up. It deliberately checks no
+// dependencies, so an outage elsewhere does not get the pod restarted.
 func (h *HealthHandler) Health(c *fiber.Ctx) error {
 	return c.JSON(fiber.Map{"status": "ok", "service": h.Service})
 }
+
+// Ready runs every check concurrently and answers 503 if any fails, so the
+// pod is taken out of rotation until its dependencies recover.
+func (h *HealthHandler) Ready(c *fiber.Ctx) error {
+	type result struct {
+		Status     string `json:"status"`
+		Error      string `json:"error,omitempty"`
+		DurationMS int64  `json:"duration_ms"`
+	}
+	results := make([]result, len(h.checks))
+
+	// c.UserContext is not safe to call from several goroutines, so every
+	// check derives its deadline from one captured context.
+	parent := c.UserContext()
+	var wg sync.WaitGroup
+	for i, check := range h.checks {
+		wg.Add(1)
+		go func(i int, check HealthCheck) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(parent, h.timeout)
+			defer cancel()
+			start := time.Now()
+			err := check.Check(ctx)
+			results[i] = result{Status: "ok", DurationMS: time.Since(start).Milliseconds()}
+			if err != nil {
+				results[i].Status = "fail"
+				results[i].Error = err.Error()
+			}
+		}(i, check)
+	}
+	wg.Wait()
+
+	status := "ok"
+	checks := fiber.Map{}
+	for i, check := range h.checks {
+		checks[check.Name] = results[i]
+		if results[i].Error != "" {
+			status = "unavailable"
+		}
+	}
+	if status != "ok" {
+		c.Status(fiber.StatusServiceUnavailable)
+	}
+	return c.JSON(fiber.Map{"status": status, "service": h.Service, "checks": checks})
+}
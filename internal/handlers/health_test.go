@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func readiness(t *testing.T, checks ...HealthCheck) (int, map[string]interface{}) {
+	t.Helper()
+	app := fiber.New()
+	NewHealthHandler("transaction-service", 50*time.Millisecond, checks...).Register(app)
+	resp, err := app.Test(httptest.NewRequest("GET", "/readyz", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var body map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, body
+}
+
+func TestReadyz(t *testing.T) {
+	ok := HealthCheck{Name: "postgres", Check: func(context.Context) error { return nil }}
+	if status, body := readiness(t, ok); status != fiber.StatusOK || body["status"] != "ok" {
+		t.Errorf("healthy dependencies: %d %v", status, body)
+	}
+
+	down := HealthCheck{Name: "redis", Check: func(context.Context) error { return errors.New("connection refused") }}
+	hung := HealthCheck{Name: "merchant-service", Check: func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}}
+	start := time.Now()
+	status, body := readiness(t, ok, down, hung)
+	if status != fiber.StatusServiceUnavailable || body["status"] != "unavailable" {
+		t.Errorf("failing dependencies: %d %v", status, body)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("a hung check held the probe for %v", elapsed)
+	}
+	checks, _ := body["checks"].(map[string]interface{})
+	for name, want := range map[string]string{"postgres": "ok", "redis": "fail", "merchant-service": "fail"} {
+		if check, _ := checks[name].(map[string]interface{}); check["status"] != want {
+			t.Errorf("%s: %v, want %s", name, checks[name], want)
+		}
+	}
+}
+
+func TestLivezChecksNothing(t *testing.T) {
+	app := fiber.New()
+	down := HealthCheck{Name: "postgres", Check: func(context.Context) error { return errors.New("down") }}
+	NewHealthHandler("transaction-service", time.Second, down).Register(app)
+	resp, err := app.Test(httptest.NewRequest("GET", "/livez", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != fiber.StatusOK {
+		t.Errorf("livez with a dependency down: %d", resp.StatusCode)
+	}
+}
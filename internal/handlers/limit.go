@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/kodra-pay/transaction-service/internal/apperr"
+	"github.com/kodra-pay/transaction-service/internal/auth"
+	"github.com/kodra-pay/transaction-service/internal/dto"
+	"github.com/kodra-pay/transaction-service/internal/services"
+)
+
+type LimitHandler struct {
+	svc    *services.LimitService
+	policy *auth.Policy
+}
+
+func NewLimitHandler(svc *services.LimitService, policy *auth.Policy) *LimitHandler {
+	return &LimitHandler{svc: svc, policy: policy}
+}
+
+func (h *LimitHandler) List(c *fiber.Ctx) error {
+	if err := authorize(c, h.policy, auth.ActionLimitRead); err != nil {
+		return err
+	}
+	merchantID, err := c.ParamsInt("merchant_id")
+	if err != nil || merchantID <= 0 {
+		return apperr.Invalid("merchant_id", "must be a positive integer")
+	}
+	resp, err := h.svc.List(c.UserContext(), merchantID)
+	if err != nil {
+		return apperr.Internal("failed to fetch merchant limits", err)
+	}
+	return c.JSON(resp)
+}
+
+func (h *LimitHandler) Set(c *fiber.Ctx) error {
+	if err := authorize(c, h.policy, auth.ActionLimitWrite); err != nil {
+		return err
+	}
+	merchantID, err := c.ParamsInt("merchant_id")
+	if err != nil || merchantID <= 0 {
+		return apperr.Invalid("merchant_id", "must be a positive integer")
+	}
+	var req dto.MerchantLimitRequest
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+	resp, err := h.svc.Set(c.UserContext(), merchantID, req)
+	if err != nil {
+		return serviceError(err, "failed to update merchant limits")
+	}
+	return c.JSON(resp)
+}
+
+// limitExceeded converts a LimitExceededError into a limit_exceeded error
+// whose details tell callers which limit was hit.
+func limitExceeded(e *services.LimitExceededError) error {
+	return &apperr.Error{
+		Code:    apperr.CodeLimitExceeded,
+		Message: e.Error(),
+		Details: dto.LimitExceededDetails{
+			Limit:     e.Limit,
+			Currency:  e.Currency,
+			Max:       float64(e.Max) / 100,
+			Current:   float64(e.Current) / 100,
+			Attempted: float64(e.Attempted) / 100,
+		},
+	}
+}
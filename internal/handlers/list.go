@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/kodra-pay/transaction-service/internal/apperr"
+)
+
+const (
+	defaultListLimit = 50
+	maxListLimit     = 100
+)
+
+// listLimit reads the limit query parameter of list endpoints, capping it at
+// maxListLimit so a single request cannot pull an unbounded page.
+func listLimit(c *fiber.Ctx) (int, error) {
+	limit := c.QueryInt("limit", defaultListLimit)
+	if limit <= 0 {
+		return 0, apperr.Invalid("limit", "must be a positive integer")
+	}
+	if limit > maxListLimit {
+		limit = maxListLimit
+	}
+	return limit, nil
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/kodra-pay/transaction-service/internal/auth"
+	"github.com/kodra-pay/transaction-service/internal/dto"
+	"github.com/kodra-pay/transaction-service/internal/pii"
+)
+
+// redactTransactions masks customer PII in the given responses unless the
+// caller's roles allow reading it in full.
+func redactTransactions(c *fiber.Ctx, policy *auth.Policy, txs ...*dto.TransactionResponse) {
+	if len(txs) == 0 || allowed(c, policy, auth.ActionPIIRead) {
+		return
+	}
+	for _, tx := range txs {
+		tx.CustomerEmail = pii.MaskEmail(tx.CustomerEmail)
+		tx.CustomerName = pii.MaskName(tx.CustomerName)
+	}
+}
+
+// redactTransactionList masks customer PII across a list response.
+func redactTransactionList(c *fiber.Ctx, policy *auth.Policy, list *dto.TransactionListResponse) {
+	txs := make([]*dto.TransactionResponse, len(list.Transactions))
+	for i := range list.Transactions {
+		txs[i] = &list.Transactions[i]
+	}
+	redactTransactions(c, policy, txs...)
+}
+
+// redactReviews masks customer PII in the transactions held by reviews.
+func redactReviews(c *fiber.Ctx, policy *auth.Policy, reviews ...*dto.ReviewResponse) {
+	txs := make([]*dto.TransactionResponse, len(reviews))
+	for i, rv := range reviews {
+		txs[i] = &rv.Transaction
+	}
+	redactTransactions(c, policy, txs...)
+}
+
+// redactReviewList masks customer PII across the review queue.
+func redactReviewList(c *fiber.Ctx, policy *auth.Policy, list *dto.ReviewListResponse) {
+	reviews := make([]*dto.ReviewResponse, len(list.Reviews))
+	for i := range list.Reviews {
+		reviews[i] = &list.Reviews[i]
+	}
+	redactReviews(c, policy, reviews...)
+}
+
+// redactAdjustments masks customer PII in the transactions of adjustments.
+func redactAdjustments(c *fiber.Ctx, policy *auth.Policy, adjustments ...*dto.AdjustmentResponse) {
+	txs := make([]*dto.TransactionResponse, len(adjustments))
+	for i, adj := range adjustments {
+		txs[i] = &adj.Transaction
+	}
+	redactTransactions(c, policy, txs...)
+}
+
+// redactAdjustmentList masks customer PII across a list of adjustments.
+func redactAdjustmentList(c *fiber.Ctx, policy *auth.Policy, list *dto.AdjustmentListResponse) {
+	adjustments := make([]*dto.AdjustmentResponse, len(list.Adjustments))
+	for i := range list.Adjustments {
+		adjustments[i] = &list.Adjustments[i]
+	}
+	redactAdjustments(c, policy, adjustments...)
+}
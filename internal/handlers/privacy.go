@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/kodra-pay/transaction-service/internal/apperr"
+	"github.com/kodra-pay/transaction-service/internal/auth"
+	"github.com/kodra-pay/transaction-service/internal/dto"
+	"github.com/kodra-pay/transaction-service/internal/services"
+)
+
+type PrivacyHandler struct {
+	svc    *services.PrivacyService
+	policy *auth.Policy
+}
+
+func NewPrivacyHandler(svc *services.PrivacyService, policy *auth.Policy) *PrivacyHandler {
+	return &PrivacyHandler{svc: svc, policy: policy}
+}
+
+// Export returns all transactions of the customer given by customer_id or email.
+func (h *PrivacyHandler) Export(c *fiber.Ctx) error {
+	if err := authorize(c, h.policy, auth.ActionCustomerExport); err != nil {
+		return err
+	}
+	resp, err := h.svc.Export(c.UserContext(), c.QueryInt("customer_id", 0), c.Query("email"))
+	if err != nil {
+		return serviceError(err, "failed to export customer transactions")
+	}
+	return c.JSON(resp)
+}
+
+// RequestErasure records an erasure request; the background job carries it out.
+func (h *PrivacyHandler) RequestErasure(c *fiber.Ctx) error {
+	if err := authorize(c, h.policy, auth.ActionCustomerErase); err != nil {
+		return err
+	}
+	var req dto.ErasureCreateRequest
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+	resp, err := h.svc.RequestErasure(c.UserContext(), req)
+	if err != nil {
+		return serviceError(err, "failed to record erasure request")
+	}
+	return c.Status(fiber.StatusAccepted).JSON(resp)
+}
+
+// GetErasure returns an erasure request and whether it has been verified.
+func (h *PrivacyHandler) GetErasure(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return apperr.Invalid("id", "must be a positive integer")
+	}
+	if err := authorize(c, h.policy, auth.ActionCustomerErase); err != nil {
+		return err
+	}
+	resp, err := h.svc.GetErasure(c.UserContext(), id)
+	if err != nil {
+		return serviceError(err, "failed to fetch erasure request")
+	}
+	return c.JSON(resp)
+}
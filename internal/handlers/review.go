@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/kodra-pay/transaction-service/internal/apperr"
+	"github.com/kodra-pay/transaction-service/internal/auth"
+	"github.com/kodra-pay/transaction-service/internal/dto"
+	"github.com/kodra-pay/transaction-service/internal/services"
+)
+
+type ReviewHandler struct {
+	svc    *services.ReviewService
+	policy *auth.Policy
+}
+
+func NewReviewHandler(svc *services.ReviewService, policy *auth.Policy) *ReviewHandler {
+	return &ReviewHandler{svc: svc, policy: policy}
+}
+
+func (h *ReviewHandler) List(c *fiber.Ctx) error {
+	if err := authorize(c, h.policy, auth.ActionReviewRead); err != nil {
+		return err
+	}
+	limit, err := listLimit(c)
+	if err != nil {
+		return err
+	}
+	resp, err := h.svc.List(c.UserContext(), c.Query("status"), limit)
+	if err != nil {
+		return apperr.Internal("failed to list reviews", err)
+	}
+	redactReviewList(c, h.policy, &resp)
+	return c.JSON(resp)
+}
+
+func (h *ReviewHandler) Get(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return apperr.Invalid("id", "must be a positive integer")
+	}
+	if err := authorize(c, h.policy, auth.ActionReviewRead); err != nil {
+		return err
+	}
+	resp, err := h.svc.Get(c.UserContext(), id)
+	if err != nil {
+		return serviceError(err, "failed to fetch review")
+	}
+	redactReviews(c, h.policy, &resp)
+	return c.JSON(resp)
+}
+
+func (h *ReviewHandler) Claim(c *fiber.Ctx) error {
+	id, _, err := h.parseReviewDecision(c)
+	if err != nil {
+		return err
+	}
+	resp, err := h.svc.Claim(c.UserContext(), id)
+	if err != nil {
+		return serviceError(err, "failed to claim review")
+	}
+	redactReviews(c, h.policy, &resp)
+	return c.JSON(resp)
+}
+
+func (h *ReviewHandler) Approve(c *fiber.Ctx) error {
+	id, req, err := h.parseReviewDecision(c)
+	if err != nil {
+		return err
+	}
+	resp, err := h.svc.Approve(c.UserContext(), id, req)
+	if err != nil {
+		return serviceError(err, "failed to approve review")
+	}
+	redactReviews(c, h.policy, &resp)
+	return c.JSON(resp)
+}
+
+func (h *ReviewHandler) Decline(c *fiber.Ctx) error {
+	id, req, err := h.parseReviewDecision(c)
+	if err != nil {
+		return err
+	}
+	resp, err := h.svc.Decline(c.UserContext(), id, req)
+	if err != nil {
+		return serviceError(err, "failed to decline review")
+	}
+	redactReviews(c, h.policy, &resp)
+	return c.JSON(resp)
+}
+
+// parseReviewDecision authorizes the caller to work the queue and reads the
+// review id and body. The reviewer is always the authenticated caller.
+func (h *ReviewHandler) parseReviewDecision(c *fiber.Ctx) (int, dto.ReviewDecisionRequest, error) {
+	var req dto.ReviewDecisionRequest
+	if err := authorize(c, h.policy, auth.ActionReviewDecide); err != nil {
+		return 0, req, err
+	}
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return 0, req, apperr.Invalid("id", "must be a positive integer")
+	}
+	// Notes are optional, so a claim or decision may come without a body.
+	if len(c.Body()) > 0 {
+		if err := parseBody(c, &req); err != nil {
+			return 0, req, err
+		}
+	}
+	return id, req, nil
+}
@@ -0,0 +1,112 @@
+// Package logging builds the service's structured JSON logger. Records are
+// enriched with request-scoped fields found in the context they are logged
+// with, and customer PII is masked before it is written.
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/kodra-pay/transaction-service/internal/auth"
+	"github.com/kodra-pay/transaction-service/internal/pii"
+	"github.com/kodra-pay/transaction-service/internal/requestctx"
+)
+
+// New returns a JSON logger writing to stdout at the given level
+// ("debug", "info", "warn" or "error"; anything else means info).
+func New(level string) *slog.Logger {
+	handler := slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+		Level:       ParseLevel(level),
+		ReplaceAttr: redact,
+	})
+	return slog.New(contextHandler{handler})
+}
+
+// ParseLevel maps a LOG_LEVEL value to a slog level.
+func ParseLevel(level string) slog.Level {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(strings.TrimSpace(level))); err != nil {
+		return slog.LevelInfo
+	}
+	return l
+}
+
+type loggerKey struct{}
+
+// NewContext returns ctx carrying logger.
+func NewContext(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// FromContext returns the logger carried by ctx, or the default logger.
+func FromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
+
+// contextHandler adds the request ID, trace and span IDs and the calling
+// principal from the record's context.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if ctx != nil {
+		if id := requestctx.RequestID(ctx); id != "" {
+			r.AddAttrs(slog.String("request_id", id))
+		}
+		if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+			r.AddAttrs(slog.String("trace_id", sc.TraceID().String()), slog.String("span_id", sc.SpanID().String()))
+		}
+		if p := auth.FromContext(ctx); p != nil {
+			r.AddAttrs(slog.String("principal_kind", p.Kind), slog.String("principal", p.Subject))
+			if p.MerchantID != 0 {
+				r.AddAttrs(slog.Int("merchant_id", p.MerchantID))
+			}
+		}
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
+
+// redactedKeys are attribute keys whose values are customer PII.
+var redactedKeys = map[string]bool{
+	"customer_email": true,
+	"email":          true,
+	"customer_name":  true,
+	"customer_phone": true,
+	"phone":          true,
+	"card_number":    true,
+	"pan":            true,
+}
+
+// redact masks PII attributes wherever they appear, including inside groups.
+func redact(_ []string, a slog.Attr) slog.Attr {
+	key := strings.ToLower(a.Key)
+	if !redactedKeys[key] {
+		return a
+	}
+	if a.Value.Kind() != slog.KindString {
+		return slog.String(a.Key, "[REDACTED]")
+	}
+	switch key {
+	case "customer_email", "email":
+		return slog.String(a.Key, pii.MaskEmail(a.Value.String()))
+	case "customer_name":
+		return slog.String(a.Key, pii.MaskName(a.Value.String()))
+	}
+	return slog.String(a.Key, "[REDACTED]")
+}
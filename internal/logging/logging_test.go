@@ -0,0 +1,69 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/kodra-pay/transaction-service/internal/auth"
+	"github.com/kodra-pay/transaction-service/internal/requestctx"
+)
+
+func captureLogger(buf *bytes.Buffer) *slog.Logger {
+	return slog.New(contextHandler{slog.NewJSONHandler(buf, &slog.HandlerOptions{ReplaceAttr: redact})})
+}
+
+func TestLoggerRedactsPIIAndAddsRequestFields(t *testing.T) {
+	var buf bytes.Buffer
+	logger := captureLogger(&buf)
+	ctx := requestctx.WithRequestID(context.Background(), "req-1")
+	ctx = auth.WithPrincipal(ctx, &auth.Principal{Kind: auth.KindAPIKey, Subject: "kp_live_abc", MerchantID: 42})
+
+	logger.InfoContext(ctx, "transaction created",
+		"customer_email", "ada@example.com",
+		"Customer_Name", "Ada Lovelace",
+		slog.Group("card", "pan", "4111111111111111"),
+		"phone", 2348012345678,
+		"reference", "ref-1")
+
+	var record map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("decode %s: %v", buf.Bytes(), err)
+	}
+	for key, want := range map[string]interface{}{
+		"customer_email": "a***@example.com",
+		"Customer_Name":  "A*** L***",
+		"phone":          "[REDACTED]",
+		"reference":      "ref-1",
+		"request_id":     "req-1",
+		"principal":      "kp_live_abc",
+		"merchant_id":    float64(42),
+	} {
+		if record[key] != want {
+			t.Errorf("%s = %v, want %v", key, record[key], want)
+		}
+	}
+	if card, _ := record["card"].(map[string]interface{}); card["pan"] != "[REDACTED]" {
+		t.Errorf("card = %v, want the PAN redacted", record["card"])
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	for in, want := range map[string]slog.Level{"debug": slog.LevelDebug, " WARN ": slog.LevelWarn, "error": slog.LevelError, "": slog.LevelInfo, "loud": slog.LevelInfo} {
+		if got := ParseLevel(in); got != want {
+			t.Errorf("ParseLevel(%q) = %v, want %v", in, got, want)
+		}
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	logger := captureLogger(&bytes.Buffer{})
+	if FromContext(NewContext(context.Background(), logger)) != logger {
+		t.Error("logger not carried by the context")
+	}
+	if FromContext(context.Background()) != slog.Default() {
+		t.Error("context without a logger did not fall back to the default")
+	}
+}
@@ -0,0 +1,95 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/kodra-pay/transaction-service/internal/models"
+)
+
+// AdjustmentRepository is the in-memory store of ledger adjustments.
+type AdjustmentRepository struct {
+	s *Store
+}
+
+func NewAdjustmentRepository(s *Store) *AdjustmentRepository {
+	return &AdjustmentRepository{s: s}
+}
+
+func copyAdjustment(adj *models.Adjustment) *models.Adjustment {
+	c := *adj
+	return &c
+}
+
+// adjustment returns the stored adjustment with the given ID, or nil. The
+// caller holds r.s.mu.
+func (r *AdjustmentRepository) adjustment(id int) *models.Adjustment {
+	if id < 1 || id > len(r.s.adjustments) {
+		return nil
+	}
+	return r.s.adjustments[id-1]
+}
+
+func (r *AdjustmentRepository) GetByID(ctx context.Context, id int) (*models.Adjustment, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if adj := r.adjustment(id); adj != nil {
+		return copyAdjustment(adj), nil
+	}
+	return nil, nil
+}
+
+// List returns adjustments oldest first. A zero merchantID or empty status
+// matches all.
+func (r *AdjustmentRepository) List(ctx context.Context, merchantID int, status string, limit int) ([]*models.Adjustment, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var list []*models.Adjustment
+	for _, adj := range r.s.adjustments {
+		if (merchantID == 0 || adj.MerchantID == merchantID) && (status == "" || adj.Status == status) {
+			list = append(list, copyAdjustment(adj))
+		}
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		return !newest(list[i].CreatedAt, list[j].CreatedAt, list[i].ID, list[j].ID)
+	})
+	if limit >= 0 && len(list) > limit {
+		list = list[:limit]
+	}
+	return list, nil
+}
+
+// Decide records adj.DecidedBy's decision, moves the adjustment's pending
+// transaction to tx.Status with its timeline event and posts entry, if not
+// nil. It returns false, changing nothing, if the adjustment is no longer
+// pending or adj.DecidedBy proposed it.
+func (r *AdjustmentRepository) Decide(ctx context.Context, adj *models.Adjustment, tx *models.Transaction, ev *models.TransactionEvent, entry *models.LedgerEntry) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	stored := r.adjustment(adj.ID)
+	if stored == nil || stored.Status != models.AdjustmentStatusPending || stored.ProposedBy == adj.DecidedBy {
+		return false, nil
+	}
+	txRow := r.s.transaction(tx.ID)
+	if txRow == nil || txRow.tx.Status != models.TransactionStatusPending {
+		return false, nil
+	}
+
+	at := now()
+	stored.Status = adj.Status
+	stored.DecidedBy = adj.DecidedBy
+	stored.DecisionNotes = adj.DecisionNotes
+	stored.DecidedAt = timePtr(at)
+	stored.UpdatedAt = at
+	adj.DecidedAt = timePtr(at)
+	adj.UpdatedAt = at
+
+	txRow.tx.Status = tx.Status
+	txRow.tx.UpdatedAt = at
+	tx.UpdatedAt = at
+	r.s.insertStatusEvent(tx, ev)
+	if entry != nil {
+		r.s.insertLedgerEntry(entry)
+	}
+	return true, nil
+}
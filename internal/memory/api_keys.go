@@ -0,0 +1,124 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/kodra-pay/transaction-service/internal/models"
+)
+
+// APIKeyRepository is the in-memory store of merchant API keys.
+type APIKeyRepository struct {
+	s *Store
+}
+
+func NewAPIKeyRepository(s *Store) *APIKeyRepository {
+	return &APIKeyRepository{s: s}
+}
+
+func copyAPIKey(k *models.APIKey) *models.APIKey {
+	c := *k
+	for _, t := range []**time.Time{&c.ExpiresAt, &c.LastUsedAt, &c.RevokedAt} {
+		if *t != nil {
+			*t = timePtr(**t)
+		}
+	}
+	return &c
+}
+
+func (r *APIKeyRepository) Create(ctx context.Context, k *models.APIKey) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	return r.insert(k)
+}
+
+// insert enforces the unique key hash. The caller holds r.s.mu.
+func (r *APIKeyRepository) insert(k *models.APIKey) error {
+	for _, existing := range r.s.apiKeys {
+		if existing.KeyHash == k.KeyHash {
+			return fmt.Errorf("api key with prefix %s already exists", k.Prefix)
+		}
+	}
+	k.ID = len(r.s.apiKeys) + 1
+	k.CreatedAt = now()
+	r.s.apiKeys = append(r.s.apiKeys, copyAPIKey(k))
+	return nil
+}
+
+func (r *APIKeyRepository) key(id int) *models.APIKey {
+	if id < 1 || id > len(r.s.apiKeys) {
+		return nil
+	}
+	return r.s.apiKeys[id-1]
+}
+
+func (r *APIKeyRepository) GetByHash(ctx context.Context, hash string) (*models.APIKey, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	for _, k := range r.s.apiKeys {
+		if k.KeyHash == hash {
+			return copyAPIKey(k), nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *APIKeyRepository) GetByID(ctx context.Context, id int) (*models.APIKey, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if k := r.key(id); k != nil {
+		return copyAPIKey(k), nil
+	}
+	return nil, nil
+}
+
+// ListByMerchant returns the merchant's keys newest first.
+func (r *APIKeyRepository) ListByMerchant(ctx context.Context, merchantID int) ([]*models.APIKey, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var list []*models.APIKey
+	for _, k := range r.s.apiKeys {
+		if k.MerchantID == merchantID {
+			list = append(list, copyAPIKey(k))
+		}
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		return newest(list[i].CreatedAt, list[j].CreatedAt, list[i].ID, list[j].ID)
+	})
+	return list, nil
+}
+
+// Rotate stores the replacement key and schedules the old key to expire at
+// graceUntil, unless it already expires sooner.
+func (r *APIKeyRepository) Rotate(ctx context.Context, oldID int, graceUntil time.Time, replacement *models.APIKey) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if err := r.insert(replacement); err != nil {
+		return err
+	}
+	if old := r.key(oldID); old != nil && (old.ExpiresAt == nil || graceUntil.Before(*old.ExpiresAt)) {
+		old.ExpiresAt = timePtr(graceUntil)
+	}
+	return nil
+}
+
+func (r *APIKeyRepository) Revoke(ctx context.Context, id int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if k := r.key(id); k != nil && k.Status != models.APIKeyStatusRevoked {
+		k.Status = models.APIKeyStatusRevoked
+		k.RevokedAt = timePtr(now())
+	}
+	return nil
+}
+
+func (r *APIKeyRepository) TouchLastUsed(ctx context.Context, id int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if k := r.key(id); k != nil {
+		k.LastUsedAt = timePtr(now())
+	}
+	return nil
+}
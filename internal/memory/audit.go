@@ -0,0 +1,54 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/kodra-pay/transaction-service/internal/models"
+)
+
+// AuditRepository is the in-memory hash-chained audit log.
+type AuditRepository struct {
+	s *Store
+}
+
+func NewAuditRepository(s *Store) *AuditRepository {
+	return &AuditRepository{s: s}
+}
+
+func copyAuditEntry(e *models.AuditEntry) *models.AuditEntry {
+	c := *e
+	c.Before = append([]byte(nil), e.Before...)
+	c.After = append([]byte(nil), e.After...)
+	return &c
+}
+
+// Append links the entry to the end of the chain, computes its hash and
+// stores it.
+func (r *AuditRepository) Append(ctx context.Context, e *models.AuditEntry) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	e.PrevHash = ""
+	if n := len(r.s.audit); n > 0 {
+		e.PrevHash = r.s.audit[n-1].Hash
+	}
+	// Truncated as Postgres would, so hashes verify the same way.
+	e.CreatedAt = time.Now().UTC().Truncate(time.Microsecond)
+	e.Hash = e.ComputeHash()
+	e.ID = len(r.s.audit) + 1
+	r.s.audit = append(r.s.audit, copyAuditEntry(e))
+	return nil
+}
+
+// ListByReference returns a transaction's entries oldest first.
+func (r *AuditRepository) ListByReference(ctx context.Context, reference string) ([]*models.AuditEntry, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var list []*models.AuditEntry
+	for _, e := range r.s.audit {
+		if e.Reference == reference {
+			list = append(list, copyAuditEntry(e))
+		}
+	}
+	return list, nil
+}
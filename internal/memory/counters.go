@@ -0,0 +1,121 @@
+package memory
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/kodra-pay/transaction-service/internal/repositories"
+)
+
+// VolumeCounter tracks daily and monthly collected volume per merchant and
+// currency, like repositories.VolumeCounter does in Redis. Counters never
+// expire; they are keyed by day and month, so old ones are simply unused.
+type VolumeCounter struct {
+	mu     sync.Mutex
+	totals map[volumeKey]int64
+}
+
+type volumeKey struct {
+	merchantID int
+	currency   string
+	period     string
+}
+
+func NewVolumeCounter() *VolumeCounter {
+	return &VolumeCounter{totals: map[volumeKey]int64{}}
+}
+
+func volumeKeys(merchantID int, currency string, at time.Time) (volumeKey, volumeKey) {
+	at = at.UTC()
+	return volumeKey{merchantID, currency, "day:" + at.Format("20060102")},
+		volumeKey{merchantID, currency, "month:" + at.Format("200601")}
+}
+
+// Reserve adds amount to the current day and month if both stay within their
+// limits (0 = no limit). It returns one of the repositories.Volume* codes and
+// the totals that were in place before the call.
+func (c *VolumeCounter) Reserve(ctx context.Context, merchantID int, currency string, amount, dailyLimit, monthlyLimit int64, at time.Time) (int, int64, int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	dailyKey, monthlyKey := volumeKeys(merchantID, currency, at)
+	daily, monthly := c.totals[dailyKey], c.totals[monthlyKey]
+	if dailyLimit > 0 && daily+amount > dailyLimit {
+		return repositories.VolumeDailyExceeded, daily, monthly, nil
+	}
+	if monthlyLimit > 0 && monthly+amount > monthlyLimit {
+		return repositories.VolumeMonthlyExceeded, daily, monthly, nil
+	}
+	c.totals[dailyKey] += amount
+	c.totals[monthlyKey] += amount
+	return repositories.VolumeReserved, daily, monthly, nil
+}
+
+// Release gives back a reservation for a transaction that was not completed.
+func (c *VolumeCounter) Release(ctx context.Context, merchantID int, currency string, amount int64, at time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	dailyKey, monthlyKey := volumeKeys(merchantID, currency, at)
+	c.totals[dailyKey] -= amount
+	c.totals[monthlyKey] -= amount
+	return nil
+}
+
+// Usage returns the volume collected so far today and this month.
+func (c *VolumeCounter) Usage(ctx context.Context, merchantID int, currency string, at time.Time) (int64, int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	dailyKey, monthlyKey := volumeKeys(merchantID, currency, at)
+	return c.totals[dailyKey], c.totals[monthlyKey], nil
+}
+
+// TokenBucket keeps rate limit buckets in process, refilling them the same
+// way repositories.TokenBucket does in Redis.
+type TokenBucket struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+type bucket struct {
+	tokens float64
+	ts     time.Time
+}
+
+func NewTokenBucket() *TokenBucket {
+	return &TokenBucket{buckets: map[string]*bucket{}}
+}
+
+// Take removes one token from the bucket at key, which holds up to capacity
+// tokens and refills at capacity per window. It returns whether a token was
+// taken, the tokens left, and the time until a token is available and until
+// the bucket is full.
+func (b *TokenBucket) Take(ctx context.Context, key string, capacity int, window time.Duration) (bool, int, time.Duration, time.Duration, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	at := time.Now()
+	rate := float64(capacity) / float64(window) // tokens per nanosecond
+	st, ok := b.buckets[key]
+	if !ok {
+		st = &bucket{tokens: float64(capacity), ts: at}
+		b.buckets[key] = st
+	}
+	if at.After(st.ts) {
+		st.tokens = math.Min(float64(capacity), st.tokens+float64(at.Sub(st.ts))*rate)
+		st.ts = at
+	}
+	var retry time.Duration
+	allowed := st.tokens >= 1
+	if allowed {
+		st.tokens--
+	} else {
+		retry = ceilDuration((1 - st.tokens) / rate)
+	}
+	return allowed, int(math.Floor(st.tokens)), retry, ceilDuration((float64(capacity) - st.tokens) / rate), nil
+}
+
+// ceilDuration rounds nanoseconds up to whole milliseconds, the resolution
+// the Redis bucket reports in.
+func ceilDuration(ns float64) time.Duration {
+	return time.Duration(math.Ceil(ns/float64(time.Millisecond))) * time.Millisecond
+}
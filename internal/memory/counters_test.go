@@ -0,0 +1,38 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kodra-pay/transaction-service/internal/repositories"
+)
+
+func TestVolumeCounterReserveAndRelease(t *testing.T) {
+	ctx := context.Background()
+	c := NewVolumeCounter()
+	at := time.Date(2026, 3, 31, 23, 59, 0, 0, time.UTC)
+
+	if code, _, _, _ := c.Reserve(ctx, 1, "NGN", 700, 1000, 5000, at); code != repositories.VolumeReserved {
+		t.Fatalf("first reservation: %d", code)
+	}
+	code, daily, _, _ := c.Reserve(ctx, 1, "NGN", 400, 1000, 5000, at)
+	if code != repositories.VolumeDailyExceeded || daily != 700 {
+		t.Fatalf("over the daily limit: %d, daily %d", code, daily)
+	}
+	if code, _, _, _ := c.Reserve(ctx, 2, "NGN", 400, 1000, 5000, at); code != repositories.VolumeReserved {
+		t.Errorf("another merchant's volume counted: %d", code)
+	}
+
+	// The next minute is a new day and month.
+	next := at.Add(time.Minute)
+	if daily, monthly, _ := c.Usage(ctx, 1, "NGN", next); daily != 0 || monthly != 0 {
+		t.Errorf("usage after midnight: %d, %d", daily, monthly)
+	}
+	if err := c.Release(ctx, 1, "NGN", 700, at); err != nil {
+		t.Fatal(err)
+	}
+	if daily, monthly, _ := c.Usage(ctx, 1, "NGN", at); daily != 0 || monthly != 0 {
+		t.Errorf("usage after release: %d, %d", daily, monthly)
+	}
+}
@@ -0,0 +1,145 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/kodra-pay/transaction-service/internal/models"
+	"github.com/kodra-pay/transaction-service/internal/repositories"
+)
+
+// DisputeRepository is the in-memory store of disputes and their evidence.
+type DisputeRepository struct {
+	s *Store
+}
+
+func NewDisputeRepository(s *Store) *DisputeRepository {
+	return &DisputeRepository{s: s}
+}
+
+func copyDispute(d *models.Dispute) *models.Dispute {
+	c := *d
+	return &c
+}
+
+func disputeOpen(d *models.Dispute) bool {
+	return d.Status == models.DisputeStatusNeedsResponse || d.Status == models.DisputeStatusUnderReview
+}
+
+// Create opens a dispute and records its ledger debit together, returning
+// one of the repositories.Dispute* codes like the Postgres repository.
+func (r *DisputeRepository) Create(ctx context.Context, d *models.Dispute, debit *models.LedgerEntry) (int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if row := r.s.transaction(d.TransactionID); row == nil || row.tx.Status != models.TransactionStatusSuccess {
+		return repositories.DisputeNotCaptured, nil
+	}
+	for _, existing := range r.s.disputes {
+		if existing.TransactionID != d.TransactionID {
+			continue
+		}
+		if disputeOpen(existing) {
+			return repositories.DisputeAlreadyOpen, nil
+		}
+		if existing.Status == models.DisputeStatusLost {
+			return repositories.DisputeAlreadyLost, nil
+		}
+	}
+	d.ID = len(r.s.disputes) + 1
+	d.CreatedAt = now()
+	d.UpdatedAt = d.CreatedAt
+	r.s.disputes = append(r.s.disputes, copyDispute(d))
+	if debit != nil {
+		r.s.insertLedgerEntry(debit)
+	}
+	return repositories.DisputeCreated, nil
+}
+
+func (r *DisputeRepository) dispute(id int) *models.Dispute {
+	if id < 1 || id > len(r.s.disputes) {
+		return nil
+	}
+	return r.s.disputes[id-1]
+}
+
+func (r *DisputeRepository) GetByID(ctx context.Context, id int) (*models.Dispute, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if d := r.dispute(id); d != nil {
+		return copyDispute(d), nil
+	}
+	return nil, nil
+}
+
+// List returns disputes newest first, optionally filtered by merchant (0 for
+// all), status ("" for all) and mode (nil for both).
+func (r *DisputeRepository) List(ctx context.Context, merchantID int, status string, livemode *bool, limit int) ([]*models.Dispute, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var list []*models.Dispute
+	for _, d := range r.s.disputes {
+		if (merchantID == 0 || d.MerchantID == merchantID) && (status == "" || d.Status == status) && (livemode == nil || d.Livemode == *livemode) {
+			list = append(list, copyDispute(d))
+		}
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		return newest(list[i].CreatedAt, list[j].CreatedAt, list[i].ID, list[j].ID)
+	})
+	if limit >= 0 && len(list) > limit {
+		list = list[:limit]
+	}
+	return list, nil
+}
+
+// UpdateStatus moves a dispute to d.Status if it is still in from, writing
+// entry to the ledger with it. It returns false if the dispute was no longer
+// in from.
+func (r *DisputeRepository) UpdateStatus(ctx context.Context, d *models.Dispute, from string, entry *models.LedgerEntry) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	stored := r.dispute(d.ID)
+	if stored == nil || stored.Status != from {
+		return false, nil
+	}
+	at := now()
+	stored.Status = d.Status
+	if d.Status == models.DisputeStatusWon || d.Status == models.DisputeStatusLost {
+		stored.ResolvedAt = timePtr(at)
+	}
+	stored.UpdatedAt = at
+	if stored.ResolvedAt != nil {
+		d.ResolvedAt = timePtr(*stored.ResolvedAt)
+	}
+	d.UpdatedAt = at
+	if entry != nil {
+		r.s.insertLedgerEntry(entry)
+	}
+	return true, nil
+}
+
+func (r *DisputeRepository) AddEvidence(ctx context.Context, e *models.DisputeEvidence) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if r.dispute(e.DisputeID) == nil {
+		return fmt.Errorf("dispute %d not found", e.DisputeID)
+	}
+	e.ID = len(r.s.evidence) + 1
+	e.CreatedAt = now()
+	c := *e
+	r.s.evidence = append(r.s.evidence, &c)
+	return nil
+}
+
+func (r *DisputeRepository) ListEvidence(ctx context.Context, disputeID int) ([]*models.DisputeEvidence, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var list []*models.DisputeEvidence
+	for _, e := range r.s.evidence {
+		if e.DisputeID == disputeID {
+			c := *e
+			list = append(list, &c)
+		}
+	}
+	return list, nil
+}
@@ -0,0 +1,88 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kodra-pay/transaction-service/internal/models"
+	"github.com/kodra-pay/transaction-service/internal/repositories"
+)
+
+func newTestDispute(tx *models.Transaction) (*models.Dispute, *models.LedgerEntry) {
+	d := &models.Dispute{
+		TransactionID: tx.ID,
+		Reference:     tx.Reference,
+		MerchantID:    tx.MerchantID,
+		Amount:        tx.Amount,
+		Currency:      tx.Currency,
+		ReasonCode:    "fraud",
+		Status:        models.DisputeStatusNeedsResponse,
+		EvidenceDueBy: time.Now().Add(time.Hour),
+	}
+	debit := &models.LedgerEntry{
+		MerchantID:    tx.MerchantID,
+		TransactionID: tx.ID,
+		EntryType:     models.LedgerEntryDebit,
+		Amount:        tx.Amount,
+		Currency:      tx.Currency,
+		Reference:     tx.Reference,
+	}
+	return d, debit
+}
+
+func TestDisputeChargesBackOnce(t *testing.T) {
+	s := NewStore(nil)
+	ctx := context.Background()
+	repo := NewDisputeRepository(s)
+	tx := createTestTransaction(t, s, 1, 5000, "NGN", models.TransactionStatusSuccess)
+
+	var wg sync.WaitGroup
+	codes := make(chan int, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			d, debit := newTestDispute(tx)
+			code, err := repo.Create(ctx, d, debit)
+			if err != nil {
+				t.Errorf("create dispute: %v", err)
+			}
+			codes <- code
+		}()
+	}
+	wg.Wait()
+	close(codes)
+	created := 0
+	for code := range codes {
+		switch code {
+		case repositories.DisputeCreated:
+			created++
+		case repositories.DisputeAlreadyOpen:
+		default:
+			t.Errorf("concurrent create returned %d", code)
+		}
+	}
+	if created != 1 {
+		t.Fatalf("%d disputes created, want 1", created)
+	}
+	entries, _ := NewLedgerRepository(s).ListByMerchant(ctx, 1)
+	if len(entries) != 1 || entries[0].BalanceAfter != -5000 {
+		t.Errorf("ledger after chargeback: %+v, want one debit of 5000", entries)
+	}
+
+	list, err := repo.List(ctx, 1, "", nil, 10)
+	if err != nil || len(list) != 1 {
+		t.Fatalf("list disputes: %d, %v", len(list), err)
+	}
+	lost := list[0]
+	lost.Status = models.DisputeStatusLost
+	if ok, err := repo.UpdateStatus(ctx, lost, models.DisputeStatusNeedsResponse, nil); !ok || err != nil {
+		t.Fatalf("lose dispute: %v, %v", ok, err)
+	}
+	d, debit := newTestDispute(tx)
+	if code, err := repo.Create(ctx, d, debit); code != repositories.DisputeAlreadyLost || err != nil {
+		t.Errorf("dispute after a lost one: %d, %v, want DisputeAlreadyLost", code, err)
+	}
+}
@@ -0,0 +1,121 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kodra-pay/transaction-service/internal/models"
+)
+
+// staleErasure is how long a running request may go without finishing
+// before ClaimNext hands it to another worker.
+const staleErasure = 15 * time.Minute
+
+// ErasureRepository is the in-memory store of data subject erasure requests.
+type ErasureRepository struct {
+	s *Store
+}
+
+func NewErasureRepository(s *Store) *ErasureRepository {
+	return &ErasureRepository{s: s}
+}
+
+func copyErasure(e *models.ErasureRequest) *models.ErasureRequest {
+	c := *e
+	if e.StartedAt != nil {
+		c.StartedAt = timePtr(*e.StartedAt)
+	}
+	if e.CompletedAt != nil {
+		c.CompletedAt = timePtr(*e.CompletedAt)
+	}
+	return &c
+}
+
+func (r *ErasureRepository) erasure(id int) *models.ErasureRequest {
+	if id < 1 || id > len(r.s.erasures) {
+		return nil
+	}
+	return r.s.erasures[id-1]
+}
+
+// Create records a pending erasure request.
+func (r *ErasureRepository) Create(ctx context.Context, e *models.ErasureRequest) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	e.EmailIndex = r.s.pii.BlindIndex(e.Email)
+	e.Status = models.ErasureStatusPending
+	e.ID = len(r.s.erasures) + 1
+	e.CreatedAt = now()
+	r.s.erasures = append(r.s.erasures, copyErasure(e))
+	return nil
+}
+
+func (r *ErasureRepository) GetByID(ctx context.Context, id int) (*models.ErasureRequest, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if e := r.erasure(id); e != nil {
+		return copyErasure(e), nil
+	}
+	return nil, nil
+}
+
+// ClaimNext marks the oldest pending request, or one left running for too
+// long, as running and returns it. It returns nil if there is none.
+func (r *ErasureRepository) ClaimNext(ctx context.Context) (*models.ErasureRequest, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	at := now()
+	for _, e := range r.s.erasures {
+		stale := e.Status == models.ErasureStatusRunning && e.StartedAt != nil && e.StartedAt.Before(at.Add(-staleErasure))
+		if e.Status == models.ErasureStatusPending || stale {
+			e.Status = models.ErasureStatusRunning
+			e.StartedAt = timePtr(at)
+			return copyErasure(e), nil
+		}
+	}
+	return nil, nil
+}
+
+// Complete records the outcome of a request and forgets its email.
+func (r *ErasureRepository) Complete(ctx context.Context, e *models.ErasureRequest) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	stored := r.erasure(e.ID)
+	if stored == nil {
+		return fmt.Errorf("erasure request %d not found", e.ID)
+	}
+	e.Status = models.ErasureStatusCompleted
+	e.Email = ""
+	e.CompletedAt = timePtr(now())
+	stored.Status = e.Status
+	stored.Email = ""
+	stored.TransactionsAffected = e.TransactionsAffected
+	stored.LedgerEntriesAffected = e.LedgerEntriesAffected
+	stored.Error = ""
+	stored.CompletedAt = timePtr(*e.CompletedAt)
+	return nil
+}
+
+// Fail records why a request could not run.
+func (r *ErasureRepository) Fail(ctx context.Context, id int, reason string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if e := r.erasure(id); e != nil {
+		e.Status = models.ErasureStatusFailed
+		e.Error = reason
+	}
+	return nil
+}
+
+func (r *ErasureRepository) CountByStatus(ctx context.Context, statuses ...string) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var count int64
+	for _, e := range r.s.erasures {
+		if contains(statuses, e.Status) {
+			count++
+		}
+	}
+	return count, nil
+}
@@ -0,0 +1,63 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/kodra-pay/transaction-service/internal/models"
+)
+
+// LedgerRepository is the in-memory wallet ledger.
+type LedgerRepository struct {
+	s *Store
+}
+
+func NewLedgerRepository(s *Store) *LedgerRepository {
+	return &LedgerRepository{s: s}
+}
+
+func (r *LedgerRepository) Record(ctx context.Context, entry *models.LedgerEntry) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	r.s.insertLedgerEntry(entry)
+	return nil
+}
+
+// ListByMerchant returns the merchant's entries in the order they were
+// posted. The Postgres repository has no equivalent; it is here so tests can
+// check postings.
+func (r *LedgerRepository) ListByMerchant(ctx context.Context, merchantID int) ([]*models.LedgerEntry, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var list []*models.LedgerEntry
+	for _, e := range r.s.ledger {
+		if e.MerchantID == merchantID {
+			c := *e
+			list = append(list, &c)
+		}
+	}
+	return list, nil
+}
+
+// EventRepository reads transaction timelines.
+type EventRepository struct {
+	s *Store
+}
+
+func NewEventRepository(s *Store) *EventRepository {
+	return &EventRepository{s: s}
+}
+
+// ListByTransaction returns the transaction's timeline, oldest first.
+func (r *EventRepository) ListByTransaction(ctx context.Context, transactionID int) ([]*models.TransactionEvent, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var list []*models.TransactionEvent
+	for _, e := range r.s.events {
+		if e.TransactionID == transactionID {
+			c := *e
+			c.Metadata = append([]byte(nil), e.Metadata...)
+			list = append(list, &c)
+		}
+	}
+	return list, nil
+}
@@ -0,0 +1,50 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/kodra-pay/transaction-service/internal/models"
+)
+
+func TestLedgerRunningBalancePerCurrency(t *testing.T) {
+	s := NewStore(nil)
+	ctx := context.Background()
+	repo := NewLedgerRepository(s)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			entry := &models.LedgerEntry{MerchantID: 1, EntryType: models.LedgerEntryCredit, Amount: int64(100 + i), Currency: "NGN"}
+			switch {
+			case i%5 == 0:
+				entry.Currency = "USD"
+			case i%3 == 0:
+				entry.EntryType = models.LedgerEntryDebit
+			}
+			if err := repo.Record(ctx, entry); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	entries, err := repo.ListByMerchant(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sums := map[string]int64{}
+	for _, e := range entries {
+		if e.EntryType == models.LedgerEntryCredit {
+			sums[e.Currency] += e.Amount
+		} else {
+			sums[e.Currency] -= e.Amount
+		}
+		if e.BalanceAfter != sums[e.Currency] {
+			t.Errorf("entry %d: balance_after %d, running sum %d %s", e.ID, e.BalanceAfter, sums[e.Currency], e.Currency)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/kodra-pay/transaction-service/internal/models"
+)
+
+// LimitRepository is the in-memory store of per-merchant limit overrides.
+type LimitRepository struct {
+	s *Store
+}
+
+func NewLimitRepository(s *Store) *LimitRepository {
+	return &LimitRepository{s: s}
+}
+
+func (r *LimitRepository) Get(ctx context.Context, merchantID int, currency string) (*models.MerchantLimit, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	l, ok := r.s.limits[limitKey{merchantID, currency}]
+	if !ok {
+		return nil, nil
+	}
+	c := *l
+	return &c, nil
+}
+
+// ListByMerchant returns the merchant's overrides ordered by currency.
+func (r *LimitRepository) ListByMerchant(ctx context.Context, merchantID int) ([]*models.MerchantLimit, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var list []*models.MerchantLimit
+	for key, l := range r.s.limits {
+		if key.merchantID == merchantID {
+			c := *l
+			list = append(list, &c)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Currency < list[j].Currency })
+	return list, nil
+}
+
+func (r *LimitRepository) Upsert(ctx context.Context, l *models.MerchantLimit) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	l.UpdatedAt = now()
+	c := *l
+	r.s.limits[limitKey{l.MerchantID, l.Currency}] = &c
+	return nil
+}
@@ -0,0 +1,131 @@
+// Package memory implements the services' stores and publishers in process,
+// with the same semantics as the Postgres repositories and Redis queues, so
+// tests and local demos run without either.
+//
+// Every store shares one Store, which plays the part of the database: writes
+// that Postgres makes in one transaction, such as a dispute and its ledger
+// debit, happen under one lock here too. Customer PII is kept in plaintext;
+// only the email blind index is computed, so lookups match the real thing.
+package memory
+
+import (
+	"sync"
+	"time"
+
+	"github.com/kodra-pay/transaction-service/internal/models"
+	"github.com/kodra-pay/transaction-service/internal/pii"
+)
+
+// Store holds every table. Rows are stored as copies and handed out as
+// copies, so callers cannot change stored data behind the store's back.
+type Store struct {
+	mu  sync.Mutex
+	pii *pii.Cipher
+
+	// Slices are append-only and a row's ID is its position plus one.
+	transactions []*transactionRow
+	events       []*models.TransactionEvent
+	ledger       []*models.LedgerEntry
+	reviews      []*models.Review
+	adjustments  []*models.Adjustment
+	disputes     []*models.Dispute
+	evidence     []*models.DisputeEvidence
+	audit        []*models.AuditEntry
+	apiKeys      []*models.APIKey
+	erasures     []*models.ErasureRequest
+	limits       map[limitKey]*models.MerchantLimit
+}
+
+type transactionRow struct {
+	tx         models.Transaction
+	emailIndex string
+}
+
+type limitKey struct {
+	merchantID int
+	currency   string
+}
+
+// NewStore returns an empty store. cipher, which may be nil, is only used for
+// email blind indexes.
+func NewStore(cipher *pii.Cipher) *Store {
+	return &Store{pii: cipher, limits: map[limitKey]*models.MerchantLimit{}}
+}
+
+// transaction returns the stored transaction with the given ID, or nil. The
+// caller holds s.mu.
+func (s *Store) transaction(id int) *transactionRow {
+	if id < 1 || id > len(s.transactions) {
+		return nil
+	}
+	return s.transactions[id-1]
+}
+
+func now() time.Time {
+	return time.Now().UTC()
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
+
+func newest(a, b time.Time, aID, bID int) bool {
+	if !a.Equal(b) {
+		return a.After(b)
+	}
+	return aID > bID
+}
+
+// insertLedgerEntry appends entry, deriving balance_after from the
+// merchant's most recent entry in the same currency, the one with the highest
+// ID. The caller holds s.mu.
+func (s *Store) insertLedgerEntry(entry *models.LedgerEntry) {
+	signed := entry.Amount
+	if entry.EntryType == models.LedgerEntryDebit {
+		signed = -entry.Amount
+	}
+	entry.BalanceAfter = signed
+	for i := len(s.ledger) - 1; i >= 0; i-- {
+		if e := s.ledger[i]; e.MerchantID == entry.MerchantID && e.Currency == entry.Currency {
+			entry.BalanceAfter += e.BalanceAfter
+			break
+		}
+	}
+	entry.ID = len(s.ledger) + 1
+	entry.CreatedAt = now()
+	row := *entry
+	s.ledger = append(s.ledger, &row)
+}
+
+// ledgerBalance is the merchant's ledger balance in currency. The caller
+// holds s.mu.
+func (s *Store) ledgerBalance(merchantID int, currency string) int64 {
+	var balance int64
+	for _, e := range s.ledger {
+		if e.MerchantID != merchantID || e.Currency != currency {
+			continue
+		}
+		if e.EntryType == models.LedgerEntryCredit {
+			balance += e.Amount
+		} else {
+			balance -= e.Amount
+		}
+	}
+	return balance
+}
+
+// insertStatusEvent records tx's current status on its timeline. A nil event
+// is skipped. The caller holds s.mu.
+func (s *Store) insertStatusEvent(tx *models.Transaction, ev *models.TransactionEvent) {
+	if ev == nil {
+		return
+	}
+	ev.TransactionID = tx.ID
+	ev.Reference = tx.Reference
+	ev.ToStatus = tx.Status
+	ev.ID = len(s.events) + 1
+	ev.CreatedAt = now()
+	row := *ev
+	row.Metadata = append([]byte(nil), ev.Metadata...)
+	s.events = append(s.events, &row)
+}
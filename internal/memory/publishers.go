@@ -0,0 +1,129 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/kodra-pay/transaction-service/internal/queue"
+)
+
+// Settlement is one transaction queued for settlement.
+type Settlement struct {
+	MerchantID    int
+	Amount        int64
+	Currency      string
+	TransactionID int
+}
+
+// SettlementPublisher queues settlements in process, keeping the unsettled
+// amount per merchant as queue.SettlementPublisher does in Redis.
+type SettlementPublisher struct {
+	mu        sync.Mutex
+	published []Settlement
+	pending   map[int]int64
+}
+
+func NewSettlementPublisher() *SettlementPublisher {
+	return &SettlementPublisher{pending: map[int]int64{}}
+}
+
+func (p *SettlementPublisher) PublishTransaction(ctx context.Context, merchantID int, amount int64, currency string, txID int) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.published = append(p.published, Settlement{MerchantID: merchantID, Amount: amount, Currency: currency, TransactionID: txID})
+	p.pending[merchantID] += amount
+	return nil
+}
+
+// PendingCount returns how many merchants have unsettled transactions.
+func (p *SettlementPublisher) PendingCount(ctx context.Context) (int64, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return int64(len(p.pending)), nil
+}
+
+// Pending returns the merchant's unsettled amount in minor units.
+func (p *SettlementPublisher) Pending(merchantID int) int64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.pending[merchantID]
+}
+
+// Published returns every settlement queued so far, oldest first.
+func (p *SettlementPublisher) Published() []Settlement {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]Settlement(nil), p.published...)
+}
+
+func (p *SettlementPublisher) Close() error {
+	return nil
+}
+
+// NotificationPublisher queues merchant notifications in process. Nothing
+// consumes them, so the backlog only grows.
+type NotificationPublisher struct {
+	mu   sync.Mutex
+	sent []queue.TransactionNotification
+}
+
+func NewNotificationPublisher() *NotificationPublisher {
+	return &NotificationPublisher{}
+}
+
+func (p *NotificationPublisher) Publish(ctx context.Context, n queue.TransactionNotification) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if n.OccurredAt.IsZero() {
+		n.OccurredAt = time.Now().UTC()
+	}
+	p.sent = append(p.sent, n)
+	return nil
+}
+
+// Backlog returns how many notifications are waiting for delivery.
+func (p *NotificationPublisher) Backlog(ctx context.Context) (int64, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return int64(len(p.sent)), nil
+}
+
+// Notifications returns every notification published so far, oldest first.
+func (p *NotificationPublisher) Notifications() []queue.TransactionNotification {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]queue.TransactionNotification(nil), p.sent...)
+}
+
+// BalanceUpdate is one balance movement reported to the merchant service,
+// in currency units.
+type BalanceUpdate struct {
+	MerchantID int
+	Currency   string
+	Amount     float64
+}
+
+// BalanceRecorder stands in for the merchant service and remembers the
+// balance updates it was sent.
+type BalanceRecorder struct {
+	mu      sync.Mutex
+	updates []BalanceUpdate
+}
+
+func NewBalanceRecorder() *BalanceRecorder {
+	return &BalanceRecorder{}
+}
+
+func (b *BalanceRecorder) Record(ctx context.Context, merchantID int, currency string, amount float64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.updates = append(b.updates, BalanceUpdate{MerchantID: merchantID, Currency: currency, Amount: amount})
+}
+
+// Updates returns every update recorded so far, oldest first.
+func (b *BalanceRecorder) Updates() []BalanceUpdate {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]BalanceUpdate(nil), b.updates...)
+}
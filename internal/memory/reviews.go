@@ -0,0 +1,120 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/kodra-pay/transaction-service/internal/models"
+)
+
+// ReviewRepository is the in-memory manual review queue.
+type ReviewRepository struct {
+	s *Store
+}
+
+func NewReviewRepository(s *Store) *ReviewRepository {
+	return &ReviewRepository{s: s}
+}
+
+func copyReview(rv *models.Review) *models.Review {
+	c := *rv
+	return &c
+}
+
+// review returns the stored review with the given ID, or nil. The caller
+// holds r.s.mu.
+func (r *ReviewRepository) review(id int) *models.Review {
+	if id < 1 || id > len(r.s.reviews) {
+		return nil
+	}
+	return r.s.reviews[id-1]
+}
+
+func (r *ReviewRepository) GetByID(ctx context.Context, id int) (*models.Review, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if rv := r.review(id); rv != nil {
+		return copyReview(rv), nil
+	}
+	return nil, nil
+}
+
+// ListByStatus returns reviews oldest first.
+func (r *ReviewRepository) ListByStatus(ctx context.Context, status string, limit int) ([]*models.Review, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var list []*models.Review
+	for _, rv := range r.s.reviews {
+		if rv.Status == status {
+			list = append(list, copyReview(rv))
+		}
+	}
+	sort.SliceStable(list, func(i, j int) bool { return list[i].CreatedAt.Before(list[j].CreatedAt) })
+	if limit >= 0 && len(list) > limit {
+		list = list[:limit]
+	}
+	return list, nil
+}
+
+func (r *ReviewRepository) CountByStatus(ctx context.Context, statuses ...string) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var count int64
+	for _, rv := range r.s.reviews {
+		if contains(statuses, rv.Status) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// Claim assigns a pending review to a reviewer. It returns false if the
+// review is no longer pending.
+func (r *ReviewRepository) Claim(ctx context.Context, rv *models.Review) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	stored := r.review(rv.ID)
+	if stored == nil || stored.Status != models.ReviewStatusPending {
+		return false, nil
+	}
+	at := now()
+	stored.Status = models.ReviewStatusClaimed
+	stored.Reviewer = rv.Reviewer
+	stored.ClaimedAt = timePtr(at)
+	stored.UpdatedAt = at
+	rv.Status = stored.Status
+	rv.ClaimedAt = timePtr(at)
+	rv.UpdatedAt = at
+	return true, nil
+}
+
+// Decide records the reviewer's decision and moves the held transaction to
+// tx.Status with its timeline event. It returns false, changing nothing, if
+// the review is not claimed by rv.Reviewer or the transaction is no longer
+// held.
+func (r *ReviewRepository) Decide(ctx context.Context, rv *models.Review, tx *models.Transaction, ev *models.TransactionEvent) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	stored := r.review(rv.ID)
+	if stored == nil || stored.Status != models.ReviewStatusClaimed || stored.Reviewer != rv.Reviewer {
+		return false, nil
+	}
+	txRow := r.s.transaction(tx.ID)
+	if txRow == nil || txRow.tx.Status != models.TransactionStatusPendingReview {
+		return false, nil
+	}
+
+	at := now()
+	stored.Status = rv.Status
+	stored.Notes = rv.Notes
+	stored.DecidedAt = timePtr(at)
+	stored.UpdatedAt = at
+	rv.DecidedAt = timePtr(at)
+	rv.UpdatedAt = at
+
+	txRow.tx.Status = tx.Status
+	txRow.tx.UpdatedAt = at
+	tx.UpdatedAt = at
+	r.s.insertStatusEvent(tx, ev)
+	return true, nil
+}
@@ -0,0 +1,340 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kodra-pay/transaction-service/internal/models"
+	"github.com/kodra-pay/transaction-service/internal/repositories"
+)
+
+// TransactionRepository is the in-memory counterpart of
+// repositories.TransactionRepository.
+type TransactionRepository struct {
+	s *Store
+}
+
+func NewTransactionRepository(s *Store) *TransactionRepository {
+	return &TransactionRepository{s: s}
+}
+
+func copyTransaction(tx *models.Transaction) *models.Transaction {
+	c := *tx
+	c.RiskReasons = append([]string(nil), tx.RiskReasons...)
+	if tx.LimitReservedAt != nil {
+		c.LimitReservedAt = timePtr(*tx.LimitReservedAt)
+	}
+	return &c
+}
+
+func (r *TransactionRepository) Create(ctx context.Context, tx *models.Transaction, ev *models.TransactionEvent) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if err := r.insert(tx); err != nil {
+		return err
+	}
+	r.s.insertStatusEvent(tx, ev)
+	return nil
+}
+
+// CreateForReview stores a held transaction together with its pending review.
+func (r *TransactionRepository) CreateForReview(ctx context.Context, tx *models.Transaction, ev *models.TransactionEvent) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if err := r.insert(tx); err != nil {
+		return err
+	}
+	r.s.insertStatusEvent(tx, ev)
+	at := now()
+	r.s.reviews = append(r.s.reviews, &models.Review{
+		ID:            len(r.s.reviews) + 1,
+		TransactionID: tx.ID,
+		Reference:     tx.Reference,
+		MerchantID:    tx.MerchantID,
+		Status:        models.ReviewStatusPending,
+		CreatedAt:     at,
+		UpdatedAt:     at,
+	})
+	return nil
+}
+
+// CreateAdjustment stores a proposed adjustment's transaction together with
+// the adjustment awaiting approval.
+func (r *TransactionRepository) CreateAdjustment(ctx context.Context, tx *models.Transaction, ev *models.TransactionEvent, adj *models.Adjustment) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if err := r.insert(tx); err != nil {
+		return err
+	}
+	r.s.insertStatusEvent(tx, ev)
+	at := now()
+	adj.ID = len(r.s.adjustments) + 1
+	adj.TransactionID = tx.ID
+	adj.Reference = tx.Reference
+	adj.CreatedAt = at
+	adj.UpdatedAt = at
+	row := *adj
+	r.s.adjustments = append(r.s.adjustments, &row)
+	return nil
+}
+
+// CreatePayout stores a payout with its ledger debit if the merchant's
+// balance in the payout currency covers it.
+func (r *TransactionRepository) CreatePayout(ctx context.Context, tx *models.Transaction, ev *models.TransactionEvent, debit *models.LedgerEntry) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if debit != nil && r.s.ledgerBalance(tx.MerchantID, tx.Currency) < debit.Amount {
+		return false, nil
+	}
+	if err := r.insert(tx); err != nil {
+		return false, err
+	}
+	r.s.insertStatusEvent(tx, ev)
+	if debit != nil {
+		debit.TransactionID = tx.ID
+		r.s.insertLedgerEntry(debit)
+	}
+	return true, nil
+}
+
+// insert enforces the unique reference constraint. The caller holds r.s.mu.
+func (r *TransactionRepository) insert(tx *models.Transaction) error {
+	for _, row := range r.s.transactions {
+		if row.tx.Reference == tx.Reference {
+			return fmt.Errorf("%w: %s", repositories.ErrDuplicateReference, tx.Reference)
+		}
+	}
+	tx.ID = len(r.s.transactions) + 1
+	tx.CreatedAt = now()
+	tx.UpdatedAt = tx.CreatedAt
+	r.s.transactions = append(r.s.transactions, &transactionRow{
+		tx:         *copyTransaction(tx),
+		emailIndex: r.s.pii.BlindIndex(tx.CustomerEmail),
+	})
+	return nil
+}
+
+func (r *TransactionRepository) GetByReference(ctx context.Context, reference string) (*models.Transaction, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	for _, row := range r.s.transactions {
+		if row.tx.Reference == reference {
+			return copyTransaction(&row.tx), nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *TransactionRepository) GetByID(ctx context.Context, id int) (*models.Transaction, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if row := r.s.transaction(id); row != nil {
+		return copyTransaction(&row.tx), nil
+	}
+	return nil, nil
+}
+
+// ListByMerchant returns the merchant's transactions newest first, optionally
+// narrowed to a status ("" for all) and to live or test mode (nil for both).
+func (r *TransactionRepository) ListByMerchant(ctx context.Context, merchantID int, status string, livemode *bool, limit int) ([]*models.Transaction, error) {
+	return r.newestFirst(limit, func(row *transactionRow) bool {
+		return row.tx.MerchantID == merchantID &&
+			(status == "" || row.tx.Status == status) &&
+			(livemode == nil || row.tx.Livemode == *livemode)
+	}), nil
+}
+
+func (r *TransactionRepository) ListByStatus(ctx context.Context, status string, limit int) ([]*models.Transaction, error) {
+	return r.newestFirst(limit, func(row *transactionRow) bool { return row.tx.Status == status }), nil
+}
+
+func (r *TransactionRepository) newestFirst(limit int, match func(*transactionRow) bool) []*models.Transaction {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var list []*models.Transaction
+	for _, row := range r.s.transactions {
+		if match(row) {
+			list = append(list, copyTransaction(&row.tx))
+		}
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		return newest(list[i].CreatedAt, list[j].CreatedAt, list[i].ID, list[j].ID)
+	})
+	if limit >= 0 && len(list) > limit {
+		list = list[:limit]
+	}
+	return list
+}
+
+// UpdateStatus moves the transaction to tx.Status if its current status is one
+// of from, recording the transition on its timeline. It returns false if the
+// transaction was in some other state.
+func (r *TransactionRepository) UpdateStatus(ctx context.Context, tx *models.Transaction, ev *models.TransactionEvent, from ...string) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	row := r.s.transaction(tx.ID)
+	if row == nil || !contains(from, row.tx.Status) {
+		return false, nil
+	}
+	row.tx.Status = tx.Status
+	row.tx.UpdatedAt = now()
+	tx.UpdatedAt = row.tx.UpdatedAt
+	r.s.insertStatusEvent(tx, ev)
+	return true, nil
+}
+
+// Refund is UpdateStatus for a move to refunded. It fails with
+// repositories.ErrTransactionDisputed, changing nothing, if the transaction
+// has an open or lost dispute.
+func (r *TransactionRepository) Refund(ctx context.Context, tx *models.Transaction, ev *models.TransactionEvent, from ...string) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	row := r.s.transaction(tx.ID)
+	if row == nil || !contains(from, row.tx.Status) {
+		return false, nil
+	}
+	for _, d := range r.s.disputes {
+		if d.TransactionID == tx.ID && models.DisputeChargedBack(d.Status) {
+			return false, fmt.Errorf("%w: %s", repositories.ErrTransactionDisputed, tx.Reference)
+		}
+	}
+	row.tx.Status = tx.Status
+	row.tx.UpdatedAt = now()
+	tx.UpdatedAt = row.tx.UpdatedAt
+	r.s.insertStatusEvent(tx, ev)
+	return true, nil
+}
+
+// UpdateDetails saves the descriptive fields an operator may correct.
+func (r *TransactionRepository) UpdateDetails(ctx context.Context, tx *models.Transaction) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	row := r.s.transaction(tx.ID)
+	if row == nil {
+		return fmt.Errorf("transaction %d not found", tx.ID)
+	}
+	row.tx.Description = tx.Description
+	row.tx.CustomerEmail = tx.CustomerEmail
+	row.tx.CustomerName = tx.CustomerName
+	row.tx.CustomerID = tx.CustomerID
+	row.emailIndex = r.s.pii.BlindIndex(tx.CustomerEmail)
+	row.tx.UpdatedAt = now()
+	tx.UpdatedAt = row.tx.UpdatedAt
+	return nil
+}
+
+// ReencryptPII has nothing to do: PII is held in plaintext and its blind
+// index is always current.
+func (r *TransactionRepository) ReencryptPII(ctx context.Context, batch int) (int, error) {
+	return 0, nil
+}
+
+// matchCustomer reports whether row belongs to the customer with the given
+// ID or email blind index.
+func matchCustomer(row *transactionRow, customerID int, emailIndex string) bool {
+	return (customerID != 0 && row.tx.CustomerID == customerID) ||
+		(emailIndex != "" && row.emailIndex == emailIndex)
+}
+
+// CountRecentByCustomer counts the merchant's transactions in the given mode
+// created since the given time by the customer.
+func (r *TransactionRepository) CountRecentByCustomer(ctx context.Context, merchantID int, livemode bool, customerID int, email string, since time.Time) (int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	index := r.s.pii.BlindIndex(email)
+	count := 0
+	for _, row := range r.s.transactions {
+		if row.tx.MerchantID == merchantID && row.tx.Livemode == livemode &&
+			!row.tx.CreatedAt.Before(since) && matchCustomer(row, customerID, index) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// ListByCustomer returns every transaction of a customer, oldest first.
+func (r *TransactionRepository) ListByCustomer(ctx context.Context, customerID int, email string) ([]*models.Transaction, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	index := r.s.pii.BlindIndex(email)
+	var list []*models.Transaction
+	for _, row := range r.s.transactions {
+		if matchCustomer(row, customerID, index) {
+			list = append(list, copyTransaction(&row.tx))
+		}
+	}
+	return list, nil
+}
+
+func (r *TransactionRepository) CountByCustomer(ctx context.Context, customerID int, emailIndex string) (int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	count := 0
+	for _, row := range r.s.transactions {
+		if matchCustomer(row, customerID, emailIndex) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// Pseudonymise replaces the customer PII of every matching transaction with
+// pseudonym and scrubs it from their ledger descriptions. It returns the
+// number of transactions and ledger entries changed.
+func (r *TransactionRepository) Pseudonymise(ctx context.Context, customerID int, email, pseudonym string) (int, int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	index := r.s.pii.BlindIndex(email)
+	ids := map[int]bool{}
+	identifiers := map[string]bool{}
+	for _, row := range r.s.transactions {
+		if !matchCustomer(row, customerID, index) {
+			continue
+		}
+		ids[row.tx.ID] = true
+		for _, v := range []string{row.tx.CustomerEmail, row.tx.CustomerName} {
+			if v != "" {
+				identifiers[v] = true
+			}
+		}
+	}
+	if len(ids) == 0 {
+		return 0, 0, nil
+	}
+	if email != "" {
+		identifiers[email] = true
+	}
+
+	at := now()
+	for id := range ids {
+		row := r.s.transaction(id)
+		row.tx.CustomerEmail = pseudonym
+		row.tx.CustomerName = ""
+		row.tx.CustomerID = 0
+		row.emailIndex = r.s.pii.BlindIndex(pseudonym)
+		row.tx.UpdatedAt = at
+	}
+	// Counted per identifier replaced, as the Postgres repository does.
+	ledgerEntries := 0
+	for identifier := range identifiers {
+		for _, e := range r.s.ledger {
+			if ids[e.TransactionID] && strings.Contains(e.Description, identifier) {
+				e.Description = strings.ReplaceAll(e.Description, identifier, pseudonym)
+				ledgerEntries++
+			}
+		}
+	}
+	return len(ids), ledgerEntries, nil
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
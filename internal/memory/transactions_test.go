@@ -0,0 +1,174 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kodra-pay/transaction-service/internal/models"
+	"github.com/kodra-pay/transaction-service/internal/repositories"
+)
+
+// createTestTransaction stores a live transaction in the given status.
+func createTestTransaction(t *testing.T, s *Store, merchantID int, amount int64, currency, status string) *models.Transaction {
+	t.Helper()
+	tx := &models.Transaction{
+		Reference:  fmt.Sprintf("test-%d-%d", merchantID, len(s.transactions)+1),
+		MerchantID: merchantID,
+		Amount:     amount,
+		Currency:   currency,
+		Status:     status,
+		Livemode:   true,
+	}
+	if err := NewTransactionRepository(s).Create(context.Background(), tx, nil); err != nil {
+		t.Fatalf("create transaction: %v", err)
+	}
+	return tx
+}
+
+func TestCreateRejectsDuplicateReference(t *testing.T) {
+	s := NewStore(nil)
+	tx := createTestTransaction(t, s, 1, 5000, "NGN", models.TransactionStatusPending)
+
+	dup := *tx
+	if err := NewTransactionRepository(s).Create(context.Background(), &dup, nil); err == nil {
+		t.Error("created a second transaction with the same reference")
+	}
+}
+
+func TestRefundRefusedWhileChargedBack(t *testing.T) {
+	s := NewStore(nil)
+	ctx := context.Background()
+	transactions := NewTransactionRepository(s)
+	disputes := NewDisputeRepository(s)
+	tx := createTestTransaction(t, s, 1, 5000, "NGN", models.TransactionStatusSuccess)
+
+	d, debit := newTestDispute(tx)
+	if code, err := disputes.Create(ctx, d, debit); code != repositories.DisputeCreated || err != nil {
+		t.Fatalf("create dispute: %d, %v", code, err)
+	}
+	refund := *tx
+	refund.Status = models.TransactionStatusRefunded
+	ok, err := transactions.Refund(ctx, &refund, nil, models.TransactionStatusSuccess)
+	if ok || !errors.Is(err, repositories.ErrTransactionDisputed) {
+		t.Fatalf("refund with an open dispute: %v, %v", ok, err)
+	}
+
+	d.Status = models.DisputeStatusWon
+	if ok, err := disputes.UpdateStatus(ctx, d, models.DisputeStatusNeedsResponse, nil); !ok || err != nil {
+		t.Fatalf("win dispute: %v, %v", ok, err)
+	}
+	if ok, err := transactions.Refund(ctx, &refund, nil, models.TransactionStatusSuccess); !ok || err != nil {
+		t.Fatalf("refund after a won dispute: %v, %v", ok, err)
+	}
+	d, debit = newTestDispute(tx)
+	if code, err := disputes.Create(ctx, d, debit); code != repositories.DisputeNotCaptured || err != nil {
+		t.Errorf("dispute after refund: %d, %v, want DisputeNotCaptured", code, err)
+	}
+}
+
+func TestCountRecentByCustomerIsScopedToMerchantAndMode(t *testing.T) {
+	s := NewStore(nil)
+	ctx := context.Background()
+	repo := NewTransactionRepository(s)
+	since := time.Now().Add(-time.Minute)
+
+	for _, tx := range []*models.Transaction{
+		{Reference: "a", MerchantID: 1, CustomerID: 7, Livemode: true},
+		{Reference: "b", MerchantID: 1, CustomerID: 7, Livemode: true},
+		{Reference: "c", MerchantID: 1, CustomerID: 7, Livemode: false},
+		{Reference: "d", MerchantID: 2, CustomerID: 7, Livemode: true},
+		{Reference: "e", MerchantID: 1, CustomerEmail: "ada@example.com", Livemode: true},
+	} {
+		tx.Status = models.TransactionStatusPending
+		if err := repo.Create(ctx, tx, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, c := range []struct {
+		merchantID int
+		livemode   bool
+		customerID int
+		email      string
+		want       int
+	}{
+		{1, true, 7, "", 2},
+		{1, false, 7, "", 1},
+		{2, true, 7, "", 1},
+		{3, true, 7, "", 0},
+		{1, true, 0, "ada@example.com", 1},
+		{1, true, 7, "ada@example.com", 3},
+	} {
+		n, err := repo.CountRecentByCustomer(ctx, c.merchantID, c.livemode, c.customerID, c.email, since)
+		if err != nil || n != c.want {
+			t.Errorf("merchant %d livemode %v customer %d %q: %d, %v, want %d", c.merchantID, c.livemode, c.customerID, c.email, n, err, c.want)
+		}
+	}
+}
+
+func TestStoredTransactionsAreCopies(t *testing.T) {
+	s := NewStore(nil)
+	ctx := context.Background()
+	want := time.Now()
+	reserved := want
+	tx := &models.Transaction{Reference: "copy", MerchantID: 1, Status: models.TransactionStatusPending, LimitReservedAt: &reserved}
+	if err := NewTransactionRepository(s).Create(ctx, tx, nil); err != nil {
+		t.Fatal(err)
+	}
+	*tx.LimitReservedAt = want.Add(time.Hour)
+
+	got, err := NewTransactionRepository(s).GetByReference(ctx, "copy")
+	if err != nil || got == nil || got.LimitReservedAt == nil {
+		t.Fatalf("get: %+v, %v", got, err)
+	}
+	if !got.LimitReservedAt.Equal(want) {
+		t.Errorf("limit reserved at %s, want %s", got.LimitReservedAt, want)
+	}
+}
+
+func TestPayoutsCannotOverspend(t *testing.T) {
+	s := NewStore(nil)
+	ctx := context.Background()
+	ledger := NewLedgerRepository(s)
+	if err := ledger.Record(ctx, &models.LedgerEntry{MerchantID: 1, EntryType: models.LedgerEntryCredit, Amount: 1000, Currency: "NGN"}); err != nil {
+		t.Fatal(err)
+	}
+
+	repo := NewTransactionRepository(s)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	paid := 0
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			tx := &models.Transaction{Reference: fmt.Sprintf("payout-%d", i), MerchantID: 1, Type: models.TransactionTypePayout, Amount: 300, Currency: "NGN", Status: models.TransactionStatusPending, Livemode: true}
+			debit := &models.LedgerEntry{MerchantID: 1, EntryType: models.LedgerEntryDebit, Amount: 300, Currency: "NGN"}
+			ok, err := repo.CreatePayout(ctx, tx, nil, debit)
+			if err != nil {
+				t.Error(err)
+			}
+			if ok {
+				mu.Lock()
+				paid++
+				mu.Unlock()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if paid != 3 {
+		t.Errorf("%d payouts of 300 recorded against a balance of 1000, want 3", paid)
+	}
+	entries, err := ledger.ListByMerchant(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 4 || entries[3].BalanceAfter != 100 {
+		t.Errorf("ledger %+v, want three debits leaving 100", entries)
+	}
+}
@@ -0,0 +1,95 @@
+// Package metrics holds the service's Prometheus collectors, served on /metrics.
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"math"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+const namespace = "transaction_service"
+
+// Registry holds every collector below plus the Go runtime and process collectors.
+var Registry = prometheus.NewRegistry()
+
+var (
+	HTTPRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by method, route template and status code.",
+	}, []string{"method", "route", "status"})
+
+	HTTPDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by method, route template and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	TransactionsCreated = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "transactions_created_total",
+		Help:      "Transactions created, by currency and initial status.",
+	}, []string{"currency", "status"})
+
+	TransactionAmount = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "transactions_created_amount_minor_total",
+		Help:      "Sum of created transaction amounts in minor units, by currency and initial status.",
+	}, []string{"currency", "status"})
+
+	SettlementPublishes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "settlement_publish_total",
+		Help:      "Settlement events published to Redis, by result (success or failure).",
+	}, []string{"result"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequests,
+		HTTPDuration,
+		TransactionsCreated,
+		TransactionAmount,
+		SettlementPublishes,
+	)
+}
+
+// RegisterDB exports the connection pool statistics of db.
+func RegisterDB(db *sql.DB, name string) {
+	Registry.MustRegister(collectors.NewDBStatsCollector(db, name))
+}
+
+// backlogTimeout bounds each backlog query made during a scrape.
+const backlogTimeout = 2 * time.Second
+
+// RegisterBacklog exports the size of a queue of outstanding work, read by
+// count on every scrape. A failed read is reported as NaN. Registering a
+// queue again replaces its earlier gauge, so the service can be wired more
+// than once in a process, as the end-to-end tests do.
+func RegisterBacklog(queue string, count func(context.Context) (int64, error)) {
+	gauge := prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace:   namespace,
+		Name:        "backlog",
+		Help:        "Outstanding work per queue.",
+		ConstLabels: prometheus.Labels{"queue": queue},
+	}, func() float64 {
+		ctx, cancel := context.WithTimeout(context.Background(), backlogTimeout)
+		defer cancel()
+		n, err := count(ctx)
+		if err != nil {
+			slog.Warn("failed to read backlog", "queue", queue, "error", err)
+			return math.NaN()
+		}
+		return float64(n)
+	})
+	Registry.Unregister(gauge)
+	Registry.MustRegister(gauge)
+}
@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/kodra-pay/transaction-service/internal/logging"
+)
+
+// Logger makes logger available to handlers through the request context and
+// writes one access log record per request. Request ID, trace and principal
+// are added from the context, so it must run after RequestID and Tracing.
+func Logger(logger *slog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		c.SetUserContext(logging.NewContext(c.UserContext(), logger))
+
+		own := c.Route()
+		err := c.Next()
+
+		status := responseStatus(c, err)
+		level := slog.LevelInfo
+		if status >= fiber.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		attrs := []slog.Attr{
+			slog.String("method", c.Method()),
+			slog.String("route", routeTemplate(c, own)),
+			slog.String("path", c.Path()),
+			slog.Int("status", status),
+			slog.Duration("duration", time.Since(start)),
+		}
+		if err != nil {
+			attrs = append(attrs, slog.String("error", err.Error()))
+		}
+		logger.LogAttrs(c.UserContext(), level, "request completed", attrs...)
+		return err
+	}
+}
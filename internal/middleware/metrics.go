@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/kodra-pay/transaction-service/internal/metrics"
+)
+
+// Metrics counts and times every request by route template, so
+// /transactions/:reference is one series rather than one per reference.
+func Metrics() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		own := c.Route()
+		err := c.Next()
+
+		labels := []string{c.Method(), routeTemplate(c, own), strconv.Itoa(responseStatus(c, err))}
+		metrics.HTTPRequests.WithLabelValues(labels...).Inc()
+		metrics.HTTPDuration.WithLabelValues(labels...).Observe(time.Since(start).Seconds())
+		return err
+	}
+}
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/kodra-pay/transaction-service/internal/metrics"
+)
+
+func TestMetricsLabelsByRouteTemplate(t *testing.T) {
+	app := fiber.New()
+	app.Use(Metrics())
+	app.Get("/transactions/:reference", func(c *fiber.Ctx) error {
+		if c.Params("reference") == "missing" {
+			return fiber.NewError(fiber.StatusNotFound, "transaction not found")
+		}
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	ok := metrics.HTTPRequests.WithLabelValues("GET", "/transactions/:reference", "200")
+	notFound := metrics.HTTPRequests.WithLabelValues("GET", "/transactions/:reference", "404")
+	unmatched := metrics.HTTPRequests.WithLabelValues("GET", "unmatched", "404")
+	before := []float64{testutil.ToFloat64(ok), testutil.ToFloat64(notFound), testutil.ToFloat64(unmatched)}
+
+	for _, path := range []string{"/transactions/ref-1", "/transactions/ref-2", "/transactions/missing", "/nowhere"} {
+		if _, err := app.Test(httptest.NewRequest("GET", path, nil)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for i, tt := range []struct {
+		name string
+		got  float64
+		want float64
+	}{
+		{"200", testutil.ToFloat64(ok), 2},
+		{"404", testutil.ToFloat64(notFound), 1},
+		{"unmatched", testutil.ToFloat64(unmatched), 1},
+	} {
+		if tt.got-before[i] != tt.want {
+			t.Errorf("%s: counted %v requests, want %v", tt.name, tt.got-before[i], tt.want)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/kodra-pay/transaction-service/internal/apperr"
+	"github.com/kodra-pay/transaction-service/internal/auth"
+	"github.com/kodra-pay/transaction-service/internal/logging"
+	"github.com/kodra-pay/transaction-service/internal/models"
+)
+
+// RateLimiter takes a token from the caller's bucket for a route class.
+type RateLimiter interface {
+	Allow(ctx context.Context, p *auth.Principal, class string) (*models.RateLimitDecision, error)
+}
+
+// RateLimit throttles authenticated callers per route class, answering 429
+// with Retry-After once their bucket is empty. It must run after
+// Authenticate. If Redis is unavailable requests are let through.
+func RateLimit(l RateLimiter) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		d, err := l.Allow(c.UserContext(), Principal(c), routeClass(c))
+		if err != nil {
+			logging.FromContext(c.UserContext()).WarnContext(c.UserContext(), "rate limiting unavailable, allowing request", "error", err)
+			return c.Next()
+		}
+		if d == nil {
+			return c.Next()
+		}
+
+		c.Set("X-RateLimit-Limit", strconv.Itoa(d.Limit))
+		c.Set("X-RateLimit-Remaining", strconv.Itoa(d.Remaining))
+		c.Set("X-RateLimit-Reset", strconv.Itoa(seconds(d.Reset)))
+		if !d.Allowed {
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(seconds(d.RetryAfter)))
+			return apperr.New(apperr.CodeRateLimited, "rate limit exceeded")
+		}
+		return c.Next()
+	}
+}
+
+// exportPaths are the list endpoints merchants can page through in bulk.
+var exportPaths = map[string]bool{
+	"/transactions": true,
+	"/disputes":     true,
+}
+
+// routeClass buckets a request as an export, a write or a read. Exports are
+// bulk reads: merchant-facing lists and anything under an /export path.
+func routeClass(c *fiber.Ctx) string {
+	read := c.Method() == fiber.MethodGet || c.Method() == fiber.MethodHead
+	switch {
+	case read && exportPaths[strings.TrimSuffix(c.Path(), "/")], strings.Contains(c.Path(), "/export"):
+		return models.RateLimitExports
+	case read:
+		return models.RateLimitReads
+	}
+	return models.RateLimitWrites
+}
+
+// seconds rounds up, so clients never retry before a token is available.
+func seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/kodra-pay/transaction-service/internal/apperr"
+	"github.com/kodra-pay/transaction-service/internal/auth"
+	"github.com/kodra-pay/transaction-service/internal/models"
+)
+
+type stubLimiter struct {
+	decision *models.RateLimitDecision
+	err      error
+	classes  []string
+}
+
+func (l *stubLimiter) Allow(ctx context.Context, p *auth.Principal, class string) (*models.RateLimitDecision, error) {
+	l.classes = append(l.classes, class)
+	return l.decision, l.err
+}
+
+func rateLimitedApp(l RateLimiter) *fiber.App {
+	app := fiber.New(fiber.Config{ErrorHandler: func(c *fiber.Ctx, err error) error {
+		return c.SendStatus(apperr.Status(err))
+	}})
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals(principalLocal, &auth.Principal{Kind: auth.KindAPIKey, MerchantID: 1})
+		return c.Next()
+	})
+	app.Use(RateLimit(l))
+	app.All("/*", func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) })
+	return app
+}
+
+func TestRateLimit(t *testing.T) {
+	l := &stubLimiter{decision: &models.RateLimitDecision{Allowed: true, Limit: 60, Remaining: 59, Reset: 1500 * time.Millisecond}}
+	app := rateLimitedApp(l)
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/transactions/sale-1", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != fiber.StatusOK || resp.Header.Get("X-RateLimit-Remaining") != "59" || resp.Header.Get("X-RateLimit-Reset") != "2" {
+		t.Errorf("allowed request: %d, headers %v", resp.StatusCode, resp.Header)
+	}
+
+	l.decision = &models.RateLimitDecision{Limit: 60, RetryAfter: 200 * time.Millisecond}
+	resp, err = app.Test(httptest.NewRequest("POST", "/transactions", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != fiber.StatusTooManyRequests || resp.Header.Get(fiber.HeaderRetryAfter) != "1" {
+		t.Errorf("throttled request: %d, Retry-After %q", resp.StatusCode, resp.Header.Get(fiber.HeaderRetryAfter))
+	}
+
+	l.decision, l.err = nil, errors.New("redis down")
+	for _, path := range []string{"/transactions", "/disputes/", "/admin/customers/export"} {
+		resp, err = app.Test(httptest.NewRequest("GET", path, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != fiber.StatusOK {
+			t.Errorf("GET %s while limiting is unavailable: %d", path, resp.StatusCode)
+		}
+	}
+
+	want := []string{models.RateLimitReads, models.RateLimitWrites, models.RateLimitExports, models.RateLimitExports, models.RateLimitExports}
+	for i, class := range want {
+		if l.classes[i] != class {
+			t.Errorf("request %d classed as %s, want %s", i, l.classes[i], class)
+		}
+	}
+}
@@ -5,15 +5,20 @@ import (
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+
+	"github.com/kodra-pay/transaction-service/internal/requestctx"
 )
 
 func RequestID() fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		requestID := c.Get("X-Request-ID")
+		// Copied because it outlives the request in spans and background work.
+		requestID := utils.CopyString(c.Get("X-Request-ID"))
 		if requestID == "" {
 			requestID = fmt.Sprintf("%d", time.Now().UnixNano())
 		}
 		c.Set("X-Request-ID", requestID)
+		c.SetUserContext(requestctx.WithRequestID(c.UserContext(), requestID))
 		return c.Next()
 	}
 }
@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/kodra-pay/transaction-service/internal/apperr"
+)
+
+// responseStatus is the status the request will be answered with. When a
+// handler returned an error the error handler has not written it yet.
+func responseStatus(c *fiber.Ctx, err error) int {
+	if err == nil {
+		return c.Response().StatusCode()
+	}
+	return apperr.Status(err)
+}
+
+// routeTemplate is the matched route's path, such as /transactions/:reference,
+// or "unmatched" when no route handled the request. own is the calling
+// middleware's route, taken before c.Next: c.Route stays on it when nothing
+// matched.
+func routeTemplate(c *fiber.Ctx, own *fiber.Route) string {
+	if r := c.Route(); r != nil && r != own {
+		return r.Path
+	}
+	return "unmatched"
+}
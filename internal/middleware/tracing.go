@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/kodra-pay/transaction-service/internal/requestctx"
+	"github.com/kodra-pay/transaction-service/internal/tracing"
+)
+
+// Tracing starts a server span per request, continuing any W3C trace context
+// sent by the caller. The span carries the request ID, and the trace ID is
+// returned in X-Trace-ID so either can be used to find the other. It must
+// run after RequestID.
+func Tracing() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		method, path := c.Method(), utils.CopyString(c.Path())
+		header := http.Header{}
+		c.Request().Header.VisitAll(func(k, v []byte) {
+			header.Add(string(k), string(v))
+		})
+		ctx := otel.GetTextMapPropagator().Extract(c.UserContext(), propagation.HeaderCarrier(header))
+
+		ctx, span := tracing.Start(ctx, method+" "+path,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(method),
+				semconv.URLPath(path),
+				attribute.String("request.id", requestctx.RequestID(ctx)),
+			))
+		defer span.End()
+
+		c.SetUserContext(ctx)
+		if id := tracing.TraceID(ctx); id != "" {
+			c.Set("X-Trace-ID", id)
+		}
+
+		own := c.Route()
+		err := c.Next()
+
+		status := responseStatus(c, err)
+		if route := routeTemplate(c, own); route != "unmatched" {
+			span.SetName(method + " " + route)
+			span.SetAttributes(semconv.HTTPRoute(route))
+		}
+		span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+		if status >= fiber.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+			if err != nil {
+				span.RecordError(err)
+			}
+		}
+		return err
+	}
+}
@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func TestTracingContinuesCallerTrace(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTracerProvider(noop.NewTracerProvider()) })
+
+	app := fiber.New()
+	app.Use(Tracing())
+	app.Get("/transactions/:reference", func(c *fiber.Ctx) error {
+		return fiber.NewError(fiber.StatusBadGateway, "upstream failed")
+	})
+
+	req := httptest.NewRequest("GET", "/transactions/ref-1", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := resp.Header.Get("X-Trace-ID"); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("X-Trace-ID %q, want the caller's trace", got)
+	}
+	if _, err := app.Test(httptest.NewRequest("GET", "/nowhere", nil)); err != nil {
+		t.Fatal(err)
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("%d spans ended, want 2", len(spans))
+	}
+	matched, unmatched := spans[0], spans[1]
+	if matched.Name() != "GET /transactions/:reference" || matched.Parent().SpanID().String() != "00f067aa0ba902b7" {
+		t.Errorf("matched span %q with parent %s", matched.Name(), matched.Parent().SpanID())
+	}
+	if matched.Status().Code != codes.Error {
+		t.Errorf("502 response recorded with status %v", matched.Status())
+	}
+	if unmatched.Name() != "GET /nowhere" || unmatched.Status().Code == codes.Error {
+		t.Errorf("unmatched span %q with status %v", unmatched.Name(), unmatched.Status())
+	}
+}
@@ -0,0 +1,96 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	_ "github.com/lib/pq"
+
+	"github.com/kodra-pay/transaction-service/migrations"
+)
+
+func TestLoadEmbeddedMigrations(t *testing.T) {
+	list, err := load(migrations.FS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, m := range list {
+		if m.Version != i+1 {
+			t.Fatalf("migration %d is version %d; versions must be contiguous from 1", i, m.Version)
+		}
+	}
+}
+
+func TestLoadRejectsBadSets(t *testing.T) {
+	file := func(body string) *fstest.MapFile { return &fstest.MapFile{Data: []byte(body)} }
+	tests := map[string]fstest.MapFS{
+		"missing down": {"0001_init.up.sql": file("SELECT 1")},
+		"two names":    {"0001_init.up.sql": file("SELECT 1"), "0001_other.down.sql": file("SELECT 1")},
+		"no version":   {"init.up.sql": file("SELECT 1"), "init.down.sql": file("SELECT 1")},
+		"no direction": {"0001_init.sql": file("SELECT 1")},
+	}
+	for name, fsys := range tests {
+		if _, err := load(fsys); err == nil {
+			t.Errorf("%s: loaded", name)
+		}
+	}
+
+	list, err := load(fstest.MapFS{
+		"0002_b.up.sql": file("B"), "0002_b.down.sql": file("-B"),
+		"0001_a.up.sql": file("A"), "0001_a.down.sql": file("-A"),
+	})
+	if err != nil || len(list) != 2 || list[0].Name != "a" || list[1].Up != "B" || list[1].Down != "-B" {
+		t.Errorf("load: %+v, %v", list, err)
+	}
+}
+
+// TestUpDownUp runs against TEST_DATABASE_URL. It reverts the newest
+// migration, so the database must not be shared with a running service.
+func TestUpDownUp(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	ctx := context.Background()
+	m, err := New(db, migrations.FS, slog.Default())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := m.Up(ctx); err != nil {
+		t.Fatalf("up: %v", err)
+	}
+	if applied, err := m.Up(ctx); err != nil || len(applied) != 0 {
+		t.Fatalf("second up applied %d migrations, %v", len(applied), err)
+	}
+	reverted, err := m.Down(ctx, 1)
+	if err != nil || len(reverted) != 1 {
+		t.Fatalf("down: %d reverted, %v", len(reverted), err)
+	}
+	if applied, err := m.Up(ctx); err != nil || len(applied) != 1 || applied[0].Version != reverted[0].Version {
+		t.Fatalf("up after down: %+v, %v", applied, err)
+	}
+
+	status, err := m.Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range status {
+		if s.AppliedAt == nil {
+			t.Errorf("%04d_%s is pending after up", s.Version, s.Name)
+		}
+		if !strings.Contains(s.Up, ";") {
+			t.Errorf("%04d_%s has no statements", s.Version, s.Name)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+const (
+	AdjustmentStatusPending  = "pending"
+	AdjustmentStatusApproved = "approved"
+	AdjustmentStatusRejected = "rejected"
+)
+
+// Adjustment is a manual credit or debit of a merchant's balance, proposed by
+// one operator and posted only once a different operator approves it. Its
+// transaction, of type adjustment, carries it through the ledger, settlement
+// and the audit log.
+type Adjustment struct {
+	ID            int        `json:"id"`
+	TransactionID int        `json:"transaction_id"`
+	Reference     string     `json:"reference"` // transaction reference
+	MerchantID    int        `json:"merchant_id"`
+	Direction     string     `json:"direction"` // LedgerEntryCredit or LedgerEntryDebit
+	Amount        int64      `json:"amount"`
+	Currency      string     `json:"currency"`
+	Reason        string     `json:"reason"`
+	Status        string     `json:"status"`
+	ProposedBy    string     `json:"proposed_by"`
+	DecidedBy     string     `json:"decided_by,omitempty"`
+	DecisionNotes string     `json:"decision_notes,omitempty"`
+	DecidedAt     *time.Time `json:"decided_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+const (
+	APIKeyStatusActive  = "active"
+	APIKeyStatusRevoked = "revoked"
+)
+
+// APIKey is a merchant credential. Only the SHA-256 hash of the key is
+// stored; Prefix is kept so merchants can tell their keys apart.
+type APIKey struct {
+	ID         int        `json:"id"`
+	MerchantID int        `json:"merchant_id"`
+	Prefix     string     `json:"prefix"`
+	KeyHash    string     `json:"-"`
+	Mode       string     `json:"mode"`
+	Status     string     `json:"status"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// Usable reports whether the key may authenticate requests at the given time.
+func (k *APIKey) Usable(now time.Time) bool {
+	if k.Status != APIKeyStatusActive {
+		return false
+	}
+	return k.ExpiresAt == nil || now.Before(*k.ExpiresAt)
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+const (
+	AuditActionCreate        = "create"
+	AuditActionCapture       = "capture"
+	AuditActionRefund        = "refund"
+	AuditActionVoid          = "void"
+	AuditActionStatusUpdate  = "status_update"
+	AuditActionAdminEdit     = "admin_edit"
+	AuditActionReviewApprove = "review_approve"
+	AuditActionReviewDecline = "review_decline"
+
+	AuditActionAdjustmentPropose = "adjustment_propose"
+	AuditActionAdjustmentApprove = "adjustment_approve"
+	AuditActionAdjustmentReject  = "adjustment_reject"
+)
+
+// AuditEntry is one append-only record of a change to a transaction. Entries
+// form a hash chain: each Hash covers the entry's content and the previous
+// entry's Hash, so editing or deleting a row breaks every hash after it.
+type AuditEntry struct {
+	ID            int             `json:"id"`
+	TransactionID int             `json:"transaction_id"`
+	Reference     string          `json:"reference"`
+	Action        string          `json:"action"`
+	ActorKind     string          `json:"actor_kind"`
+	Actor         string          `json:"actor"`
+	ActorMerchant int             `json:"actor_merchant_id,omitempty"`
+	RequestID     string          `json:"request_id,omitempty"`
+	Before        json.RawMessage `json:"before,omitempty"`
+	After         json.RawMessage `json:"after,omitempty"`
+	PrevHash      string          `json:"prev_hash"`
+	Hash          string          `json:"hash"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+// ComputeHash returns the chain hash of the entry given its PrevHash.
+func (e *AuditEntry) ComputeHash() string {
+	h := sha256.New()
+	for _, part := range []string{
+		e.PrevHash,
+		strconv.Itoa(e.TransactionID),
+		e.Reference,
+		e.Action,
+		e.ActorKind,
+		e.Actor,
+		strconv.Itoa(e.ActorMerchant),
+		e.RequestID,
+		string(e.Before),
+		string(e.After),
+		e.CreatedAt.UTC().Format(time.RFC3339Nano),
+	} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,56 @@
+package models
+
+import "time"
+
+const (
+	DisputeStatusNeedsResponse = "needs_response"
+	DisputeStatusUnderReview   = "under_review"
+	DisputeStatusWon           = "won"
+	DisputeStatusLost          = "lost"
+)
+
+// Dispute is a chargeback raised against a captured transaction.
+type Dispute struct {
+	ID            int        `json:"id"`
+	TransactionID int        `json:"transaction_id"`
+	Reference     string     `json:"reference"` // transaction reference
+	MerchantID    int        `json:"merchant_id"`
+	Amount        int64      `json:"amount"`
+	Currency      string     `json:"currency"`
+	ReasonCode    string     `json:"reason_code"`
+	Status        string     `json:"status"`
+	EvidenceDueBy time.Time  `json:"evidence_due_by"`
+	Livemode      bool       `json:"livemode"` // the transaction's mode
+	ResolvedAt    *time.Time `json:"resolved_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// DisputeEvidence is metadata about a document submitted against a dispute.
+// The document itself lives in object storage; only its location is kept here.
+type DisputeEvidence struct {
+	ID          int       `json:"id"`
+	DisputeID   int       `json:"dispute_id"`
+	Type        string    `json:"type"`
+	Description string    `json:"description,omitempty"`
+	FileURL     string    `json:"file_url,omitempty"`
+	SubmittedBy string    `json:"submitted_by,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// DisputeChargedBack reports whether a dispute in status has the disputed
+// amount debited from the merchant: it is still open or was lost.
+func DisputeChargedBack(status string) bool {
+	return status == DisputeStatusNeedsResponse || status == DisputeStatusUnderReview || status == DisputeStatusLost
+}
+
+// CanTransitionDispute reports whether a dispute may move from one status to another.
+func CanTransitionDispute(from, to string) bool {
+	switch from {
+	case DisputeStatusNeedsResponse:
+		return to == DisputeStatusUnderReview || to == DisputeStatusLost
+	case DisputeStatusUnderReview:
+		return to == DisputeStatusWon || to == DisputeStatusLost
+	}
+	return false
+}
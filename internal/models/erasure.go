@@ -0,0 +1,39 @@
+package models
+
+import (
+	"strconv"
+	"time"
+)
+
+const (
+	ErasureStatusPending   = "pending"
+	ErasureStatusRunning   = "running"
+	ErasureStatusCompleted = "completed"
+	ErasureStatusFailed    = "failed"
+)
+
+// ErasureRequest asks for a customer's PII to be pseudonymised across
+// transactions and wallet_ledger. The email is only held until the request
+// has run; EmailIndex is kept so completion can be verified afterwards.
+type ErasureRequest struct {
+	ID                    int        `json:"id"`
+	CustomerID            int        `json:"customer_id,omitempty"`
+	Email                 string     `json:"-"`
+	EmailIndex            string     `json:"-"`
+	Status                string     `json:"status"`
+	RequestedBy           string     `json:"requested_by"`
+	Reason                string     `json:"reason,omitempty"`
+	TransactionsAffected  int        `json:"transactions_affected"`
+	LedgerEntriesAffected int        `json:"ledger_entries_affected"`
+	Error                 string     `json:"error,omitempty"`
+	StartedAt             *time.Time `json:"started_at,omitempty"`
+	CompletedAt           *time.Time `json:"completed_at,omitempty"`
+	CreatedAt             time.Time  `json:"created_at"`
+}
+
+// ErasedEmail is the pseudonym that replaces a customer's email once their
+// erasure request has run. It keeps the customer's transactions grouped for
+// accounting without identifying them.
+func ErasedEmail(requestID int) string {
+	return "erased-" + strconv.Itoa(requestID) + "@erased.invalid"
+}
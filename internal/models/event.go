@@ -0,0 +1,26 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Where a status transition originated.
+const (
+	EventSourceAPI     = "api"
+	EventSourceWorker  = "worker"
+	EventSourceWebhook = "webhook"
+)
+
+// TransactionEvent records one status transition of a transaction.
+// FromStatus is empty for the event written when the transaction is created.
+type TransactionEvent struct {
+	ID            int             `json:"id"`
+	TransactionID int             `json:"transaction_id"`
+	Reference     string          `json:"reference"`
+	FromStatus    string          `json:"from_status,omitempty"`
+	ToStatus      string          `json:"to_status"`
+	Source        string          `json:"source"`
+	Metadata      json.RawMessage `json:"metadata,omitempty"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+const (
+	LedgerEntryCredit = "credit"
+	LedgerEntryDebit  = "debit"
+)
+
+// LedgerEntry is a single movement on a merchant's wallet_ledger.
+// Amount is always positive; EntryType decides the direction.
+type LedgerEntry struct {
+	ID            int       `json:"id"`
+	MerchantID    int       `json:"merchant_id"`
+	TransactionID int       `json:"transaction_id"`
+	EntryType     string    `json:"entry_type"`
+	Amount        int64     `json:"amount"`
+	BalanceAfter  int64     `json:"balance_after"`
+	Currency      string    `json:"currency"`
+	Description   string    `json:"description,omitempty"`
+	Reference     string    `json:"reference"`
+	CreatedAt     time.Time `json:"created_at"`
+}
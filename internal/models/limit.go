@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// MerchantLimit caps what a merchant may collect in one currency.
+// Amounts are in minor units; zero means the cap is not enforced.
+type MerchantLimit struct {
+	MerchantID      int       `json:"merchant_id"`
+	Currency        string    `json:"currency"`
+	Tier            string    `json:"tier"`
+	MaxSingleAmount int64     `json:"max_single_amount"`
+	DailyVolume     int64     `json:"daily_volume"`
+	MonthlyVolume   int64     `json:"monthly_volume"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
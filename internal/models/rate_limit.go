@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// Route classes rate limited separately, so bulk reads cannot starve writes.
+const (
+	RateLimitReads   = "reads"
+	RateLimitWrites  = "writes"
+	RateLimitExports = "exports"
+)
+
+// RateLimitDecision is the outcome of taking a token from a caller's bucket.
+type RateLimitDecision struct {
+	Allowed    bool
+	Limit      int           // bucket capacity, in requests per minute
+	Remaining  int           // whole tokens left after this request
+	RetryAfter time.Duration // until a token is available, when denied
+	Reset      time.Duration // until the bucket is full again
+}
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+const (
+	ReviewStatusPending  = "pending"
+	ReviewStatusClaimed  = "claimed"
+	ReviewStatusApproved = "approved"
+	ReviewStatusDeclined = "declined"
+)
+
+// Review is a manual review task for a transaction held by risk screening.
+type Review struct {
+	ID            int        `json:"id"`
+	TransactionID int        `json:"transaction_id"`
+	Reference     string     `json:"reference"`
+	MerchantID    int        `json:"merchant_id"`
+	Status        string     `json:"status"`
+	Reviewer      string     `json:"reviewer,omitempty"`
+	Notes         string     `json:"notes,omitempty"`
+	ClaimedAt     *time.Time `json:"claimed_at,omitempty"`
+	DecidedAt     *time.Time `json:"decided_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
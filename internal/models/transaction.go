@@ -2,10 +2,39 @@ package models
 
 import "time"
 
+const (
+	TransactionStatusPending       = "pending"
+	TransactionStatusAuthorized    = "authorized"
+	TransactionStatusSuccess       = "success"
+	TransactionStatusFailed        = "failed"
+	TransactionStatusRefunded      = "refunded"
+	TransactionStatusVoided        = "voided"
+	TransactionStatusBlocked       = "blocked"
+	TransactionStatusPendingReview = "pending_review"
+)
+
+// Transaction types. Payments are collected from customers; the others move
+// money on the merchant's balance.
+const (
+	TransactionTypePayment    = "payment"
+	TransactionTypeRefund     = "refund"
+	TransactionTypePayout     = "payout"
+	TransactionTypeAdjustment = "adjustment"
+	TransactionTypeFee        = "fee"
+	TransactionTypeChargeback = "chargeback"
+)
+
+const (
+	RiskDecisionAllow  = "allow"
+	RiskDecisionReview = "review"
+	RiskDecisionBlock  = "block"
+)
+
 type Transaction struct {
 	ID            int       `json:"id"`
 	Reference     string    `json:"reference"` // Changed from int to string
 	MerchantID    int       `json:"merchant_id"`
+	Type          string    `json:"type"`
 	CustomerEmail string    `json:"customer_email,omitempty"`
 	CustomerID    int       `json:"customer_id,omitempty"` // Added CustomerID
 	CustomerName  string    `json:"customer_name,omitempty"`
@@ -14,6 +43,22 @@ type Transaction struct {
 	Status        string    `json:"status"`
 	PaymentMethod string    `json:"payment_method,omitempty"`
 	Description   string    `json:"description,omitempty"`
+	RiskScore     int       `json:"risk_score"`
+	RiskDecision  string    `json:"risk_decision"`
+	RiskReasons   []string  `json:"risk_reasons,omitempty"`
+	Livemode      bool      `json:"livemode"`
 	CreatedAt     time.Time `json:"created_at"`
 	UpdatedAt     time.Time `json:"updated_at"`
+
+	// LimitReservedAt is when the transaction's amount was added to the
+	// merchant's volume counters in LimitCurrency, or nil if it was not
+	// counted. Releasing the volume uses these, not the limits in force then.
+	LimitReservedAt *time.Time `json:"-"`
+	LimitCurrency   string     `json:"-"`
+}
+
+// IsPayment reports whether the transaction collects money from a customer,
+// and so counts towards limits, credits the ledger and can be disputed.
+func (t *Transaction) IsPayment() bool {
+	return t.Type == TransactionTypePayment
 }
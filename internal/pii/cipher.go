@@ -0,0 +1,206 @@
+package pii
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// prefix marks an encrypted value; anything else is treated as legacy plaintext.
+const prefix = "pii:v1:"
+
+var ErrMalformed = errors.New("malformed encrypted value")
+
+// Cipher applies envelope encryption to PII: every value gets a fresh data
+// key, which is stored wrapped by the provider's current KEK next to the
+// ciphertext. Stored values look like
+//
+//	pii:v1:<kek id>:<wrapped data key>:<ciphertext>
+//
+// A nil *Cipher leaves values in plaintext, for local setups without a keyfile.
+type Cipher struct {
+	keys KeyProvider
+}
+
+func NewCipher(keys KeyProvider) *Cipher {
+	return &Cipher{keys: keys}
+}
+
+// Enabled reports whether values are actually encrypted.
+func (c *Cipher) Enabled() bool {
+	return c != nil
+}
+
+// CurrentKeyID names the KEK that new values are wrapped with, or "" when disabled.
+func (c *Cipher) CurrentKeyID() string {
+	if c == nil {
+		return ""
+	}
+	return c.keys.CurrentKeyID()
+}
+
+// Encrypt seals plaintext under a new data key. Empty values stay empty.
+func (c *Cipher) Encrypt(plaintext string) (string, error) {
+	if c == nil || plaintext == "" {
+		return plaintext, nil
+	}
+	dek := make([]byte, 32)
+	if _, err := rand.Read(dek); err != nil {
+		return "", err
+	}
+	sealed, err := seal(dek, []byte(plaintext))
+	if err != nil {
+		return "", err
+	}
+	id := c.keys.CurrentKeyID()
+	wrapped, err := c.wrap(id, dek)
+	if err != nil {
+		return "", err
+	}
+	return prefix + id + ":" + wrapped + ":" + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt opens a value produced by Encrypt. Values without the encryption
+// prefix were written before encryption was enabled and are returned as is.
+func (c *Cipher) Decrypt(value string) (string, error) {
+	id, wrapped, sealed, ok := split(value)
+	if !ok {
+		return value, nil
+	}
+	if c == nil {
+		return "", fmt.Errorf("%w: PII encryption is not configured", ErrUnknownKey)
+	}
+	dek, err := c.unwrap(id, wrapped)
+	if err != nil {
+		return "", err
+	}
+	raw, err := base64.RawStdEncoding.DecodeString(sealed)
+	if err != nil {
+		return "", ErrMalformed
+	}
+	plaintext, err := open(dek, raw)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// Rewrap re-wraps the data key of value under the current KEK, encrypting
+// legacy plaintext on the way. Only the small wrapped key changes; the
+// ciphertext itself is kept. It reports whether the value changed.
+func (c *Cipher) Rewrap(value string) (string, bool, error) {
+	if c == nil || value == "" {
+		return value, false, nil
+	}
+	id, wrapped, sealed, ok := split(value)
+	if !ok {
+		encrypted, err := c.Encrypt(value)
+		return encrypted, err == nil, err
+	}
+	current := c.keys.CurrentKeyID()
+	if id == current {
+		return value, false, nil
+	}
+	dek, err := c.unwrap(id, wrapped)
+	if err != nil {
+		return "", false, err
+	}
+	rewrapped, err := c.wrap(current, dek)
+	if err != nil {
+		return "", false, err
+	}
+	return prefix + current + ":" + rewrapped + ":" + sealed, true, nil
+}
+
+// BlindIndex returns a deterministic keyed hash of an email address, so rows
+// can be looked up by email without storing or querying it in plaintext.
+// Without a keyfile it falls back to an unkeyed hash.
+func (c *Cipher) BlindIndex(email string) string {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email == "" {
+		return ""
+	}
+	if c == nil {
+		sum := sha256.Sum256([]byte(email))
+		return hex.EncodeToString(sum[:])
+	}
+	mac := hmac.New(sha256.New, c.keys.IndexKey())
+	mac.Write([]byte(email))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (c *Cipher) wrap(id string, dek []byte) (string, error) {
+	kek, err := c.keys.Key(id)
+	if err != nil {
+		return "", err
+	}
+	sealed, err := seal(kek, dek)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+func (c *Cipher) unwrap(id, wrapped string) ([]byte, error) {
+	kek, err := c.keys.Key(id)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := base64.RawStdEncoding.DecodeString(wrapped)
+	if err != nil {
+		return nil, ErrMalformed
+	}
+	return open(kek, raw)
+}
+
+// split breaks an encrypted value into its key ID, wrapped data key and ciphertext.
+func split(value string) (id, wrapped, sealed string, ok bool) {
+	if !strings.HasPrefix(value, prefix) {
+		return "", "", "", false
+	}
+	parts := strings.Split(strings.TrimPrefix(value, prefix), ":")
+	if len(parts) != 3 {
+		return "", "", "", false
+	}
+	return parts[0], parts[1], parts[2], true
+}
+
+// seal encrypts with AES-256-GCM, prefixing the random nonce.
+func seal(key, plaintext []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func open(key, sealed []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return nil, ErrMalformed
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, nil)
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,127 @@
+package pii
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeKeyFile writes a keyfile holding the given key IDs, each with a
+// distinct key, and current pointing at current.
+func writeKeyFile(t *testing.T, current string, ids ...string) string {
+	t.Helper()
+	keys := []string{}
+	for i, id := range ids {
+		keys = append(keys, `"`+id+`": "`+base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{byte(i + 1)}, 32))+`"`)
+	}
+	index := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{0xee}, 32))
+	path := filepath.Join(t.TempDir(), "keys.json")
+	body := `{"current": "` + current + `", "keys": {` + strings.Join(keys, ", ") + `}, "index_key": "` + index + `"}`
+	if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func loadCipher(t *testing.T, path string) *Cipher {
+	t.Helper()
+	keys, err := LoadKeyFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewCipher(keys)
+}
+
+func TestCipherRoundTrip(t *testing.T) {
+	c := loadCipher(t, writeKeyFile(t, "k1", "k1"))
+
+	sealed, err := c.Encrypt("ada@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(sealed, prefix+"k1:") || strings.Contains(sealed, "ada") {
+		t.Errorf("encrypted value %q", sealed)
+	}
+	again, _ := c.Encrypt("ada@example.com")
+	if again == sealed {
+		t.Error("encrypting twice gave the same ciphertext")
+	}
+	if got, err := c.Decrypt(sealed); err != nil || got != "ada@example.com" {
+		t.Errorf("decrypt: %q, %v", got, err)
+	}
+	if got, err := c.Decrypt("legacy@example.com"); err != nil || got != "legacy@example.com" {
+		t.Errorf("legacy plaintext: %q, %v", got, err)
+	}
+	if got, err := c.Encrypt(""); err != nil || got != "" {
+		t.Errorf("empty value: %q, %v", got, err)
+	}
+	if _, err := c.Decrypt(sealed[:len(sealed)-4] + "AAAA"); err == nil {
+		t.Error("tampered ciphertext decrypted")
+	}
+}
+
+func TestCipherRotation(t *testing.T) {
+	old := loadCipher(t, writeKeyFile(t, "k1", "k1"))
+	sealed, err := old.Encrypt("Ada Lovelace")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rotated := loadCipher(t, writeKeyFile(t, "k2", "k1", "k2"))
+	rewrapped, changed, err := rotated.Rewrap(sealed)
+	if err != nil || !changed || !strings.HasPrefix(rewrapped, prefix+"k2:") {
+		t.Fatalf("rewrap: %q, %v, %v", rewrapped, changed, err)
+	}
+	if got, err := rotated.Decrypt(rewrapped); err != nil || got != "Ada Lovelace" {
+		t.Errorf("decrypt after rewrap: %q, %v", got, err)
+	}
+	if _, changed, _ := rotated.Rewrap(rewrapped); changed {
+		t.Error("value already under the current key was rewrapped")
+	}
+	if legacy, changed, err := rotated.Rewrap("Ada Lovelace"); err != nil || !changed || !strings.HasPrefix(legacy, prefix+"k2:") {
+		t.Errorf("legacy plaintext rewrap: %q, %v, %v", legacy, changed, err)
+	}
+
+	retired := loadCipher(t, writeKeyFile(t, "k2", "k2"))
+	if _, err := retired.Decrypt(sealed); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("value under a retired key: %v, want ErrUnknownKey", err)
+	}
+}
+
+func TestBlindIndex(t *testing.T) {
+	c := loadCipher(t, writeKeyFile(t, "k1", "k1"))
+	if c.BlindIndex("Ada@Example.com ") != c.BlindIndex("ada@example.com") {
+		t.Error("blind index is not case and space insensitive")
+	}
+	if c.BlindIndex("ada@example.com") == c.BlindIndex("bob@example.com") {
+		t.Error("different emails share a blind index")
+	}
+	var disabled *Cipher
+	if disabled.BlindIndex("ada@example.com") == c.BlindIndex("ada@example.com") {
+		t.Error("keyed and unkeyed blind indexes match")
+	}
+	if c.BlindIndex("") != "" {
+		t.Error("empty email has a blind index")
+	}
+}
+
+func TestDisabledCipher(t *testing.T) {
+	var c *Cipher
+	if got, err := c.Encrypt("ada@example.com"); err != nil || got != "ada@example.com" {
+		t.Errorf("encrypt without keys: %q, %v", got, err)
+	}
+	sealed, _ := loadCipher(t, writeKeyFile(t, "k1", "k1")).Encrypt("ada@example.com")
+	if _, err := c.Decrypt(sealed); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("decrypt without keys: %v, want ErrUnknownKey", err)
+	}
+}
+
+func TestLoadKeyFileRejectsMissingCurrentKey(t *testing.T) {
+	if _, err := LoadKeyFile(writeKeyFile(t, "k9", "k1")); err == nil {
+		t.Error("keyfile without its current key loaded")
+	}
+}
@@ -0,0 +1,110 @@
+package pii
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/kodra-pay/transaction-service/internal/config"
+)
+
+// ErrUnknownKey is returned when data was wrapped with a key the provider no longer holds.
+var ErrUnknownKey = errors.New("unknown key-encryption key")
+
+// KeyProvider supplies the key-encryption keys (KEKs) that wrap per-value
+// data keys. A KMS-backed provider can replace the local keyfile without
+// touching stored data, as long as it serves the same key IDs.
+type KeyProvider interface {
+	// CurrentKeyID names the KEK new values are wrapped with.
+	CurrentKeyID() string
+	// Key returns the 32-byte KEK with the given ID.
+	Key(id string) ([]byte, error)
+	// IndexKey returns the HMAC key for blind indexes. It is not rotated with
+	// the KEKs, since every index would have to be recomputed at once.
+	IndexKey() []byte
+}
+
+// keyFile is the JSON layout of a local keyfile. Keys are base64-encoded.
+//
+//	{"current": "2026-10", "keys": {"2026-10": "..."}, "index_key": "..."}
+type keyFile struct {
+	Current  string            `json:"current"`
+	Keys     map[string]string `json:"keys"`
+	IndexKey string            `json:"index_key"`
+}
+
+// LocalKeyProvider serves keys from a file on disk. It is meant for
+// development; production deployments should plug in a KMS.
+type LocalKeyProvider struct {
+	current  string
+	keys     map[string][]byte
+	indexKey []byte
+}
+
+// LoadKeyFile reads a local keyfile. Rotating means adding a new key, pointing
+// current at it and re-encrypting; old keys stay until no data uses them.
+func LoadKeyFile(path string) (*LocalKeyProvider, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read PII keyfile: %w", err)
+	}
+	var f keyFile
+	if err := json.Unmarshal(raw, &f); err != nil {
+		return nil, fmt.Errorf("parse PII keyfile: %w", err)
+	}
+
+	p := &LocalKeyProvider{current: f.Current, keys: map[string][]byte{}}
+	for id, encoded := range f.Keys {
+		key, err := decodeKey(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("PII key %q: %w", id, err)
+		}
+		p.keys[id] = key
+	}
+	if _, ok := p.keys[p.current]; !ok {
+		return nil, fmt.Errorf("PII keyfile: current key %q is not defined", p.current)
+	}
+	if p.indexKey, err = decodeKey(f.IndexKey); err != nil {
+		return nil, fmt.Errorf("PII index key: %w", err)
+	}
+	return p, nil
+}
+
+func decodeKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("must be 32 bytes, got %d", len(key))
+	}
+	return key, nil
+}
+
+func (p *LocalKeyProvider) CurrentKeyID() string { return p.current }
+
+func (p *LocalKeyProvider) Key(id string) ([]byte, error) {
+	key, ok := p.keys[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKey, id)
+	}
+	return key, nil
+}
+
+func (p *LocalKeyProvider) IndexKey() []byte { return p.indexKey }
+
+// NewKeyProvider builds the configured key provider. It returns nil when no
+// keys are configured, which leaves PII unencrypted.
+func NewKeyProvider(cfg config.PIIConfig) (KeyProvider, error) {
+	switch cfg.KeyProvider {
+	case "", "local":
+		if cfg.KeyFile == "" {
+			return nil, nil
+		}
+		return LoadKeyFile(cfg.KeyFile)
+	default:
+		return nil, fmt.Errorf("unknown PII key provider %q", cfg.KeyProvider)
+	}
+}
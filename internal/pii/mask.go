@@ -0,0 +1,30 @@
+package pii
+
+import "strings"
+
+// MaskEmail keeps the first character of the local part and the domain:
+// john@example.com becomes j***@example.com.
+func MaskEmail(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at <= 0 {
+		return maskWord(email)
+	}
+	return maskWord(email[:at]) + email[at:]
+}
+
+// MaskName keeps the initial of each word: John Doe becomes J*** D***.
+func MaskName(name string) string {
+	words := strings.Fields(name)
+	for i, w := range words {
+		words[i] = maskWord(w)
+	}
+	return strings.Join(words, " ")
+}
+
+func maskWord(w string) string {
+	if w == "" {
+		return ""
+	}
+	r := []rune(w)
+	return string(r[0]) + "***"
+}
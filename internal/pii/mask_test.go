@@ -0,0 +1,23 @@
+package pii
+
+import "testing"
+
+func TestMask(t *testing.T) {
+	tests := []struct {
+		fn   func(string) string
+		in   string
+		want string
+	}{
+		{MaskEmail, "john@example.com", "j***@example.com"},
+		{MaskEmail, "not-an-email", "n***"},
+		{MaskEmail, "", ""},
+		{MaskName, "John Doe", "J*** D***"},
+		{MaskName, "Émile", "É***"},
+		{MaskName, "", ""},
+	}
+	for _, tt := range tests {
+		if got := tt.fn(tt.in); got != tt.want {
+			t.Errorf("mask(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// notificationsKey is the Redis list consumed by the notification service.
+const notificationsKey = "notifications:transactions"
+
+// TransactionNotification is a merchant-facing event about a transaction.
+type TransactionNotification struct {
+	Event      string    `json:"event"`
+	Reference  string    `json:"reference"`
+	MerchantID int       `json:"merchant_id"`
+	Status     string    `json:"status"`
+	Reason     string    `json:"reason,omitempty"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// NotificationPublisher pushes transaction events for delivery to merchants
+type NotificationPublisher struct {
+	client *redis.Client
+}
+
+// NewNotificationPublisher creates a new notification publisher
+func NewNotificationPublisher(client *redis.Client) *NotificationPublisher {
+	return &NotificationPublisher{client: client}
+}
+
+// Publish queues a notification for delivery
+func (p *NotificationPublisher) Publish(ctx context.Context, n TransactionNotification) error {
+	if p.client == nil {
+		return fmt.Errorf("redis client not initialized")
+	}
+	if n.OccurredAt.IsZero() {
+		n.OccurredAt = time.Now().UTC()
+	}
+	payload, err := json.Marshal(n)
+	if err != nil {
+		return fmt.Errorf("failed to encode notification: %w", err)
+	}
+	if err := p.client.LPush(ctx, notificationsKey, payload).Err(); err != nil {
+		return fmt.Errorf("failed to queue notification for %s: %w", n.Reference, err)
+	}
+	return nil
+}
+
+// Backlog returns how many notifications are waiting for delivery
+func (p *NotificationPublisher) Backlog(ctx context.Context) (int64, error) {
+	return p.client.LLen(ctx, notificationsKey).Result()
+}
@@ -2,53 +2,80 @@ package queue
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
-	"log"
-	"os"
+	"log/slog"
 	"strconv"
 	"time"
 
 	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/kodra-pay/transaction-service/internal/config"
+	"github.com/kodra-pay/transaction-service/internal/metrics"
+	"github.com/kodra-pay/transaction-service/internal/tracing"
 )
 
 // SettlementPublisher publishes transaction events to Redis for settlement processing
 type SettlementPublisher struct {
 	client *redis.Client
+	log    *slog.Logger
 }
 
-// NewSettlementPublisher creates a new settlement event publisher
-func NewSettlementPublisher() *SettlementPublisher {
-	redisURL := os.Getenv("REDIS_URL")
-	if redisURL == "" {
-		redisURL = "redis:6379"
+// NewRedisClient connects to the Redis instance shared by the settlement and
+// notification publishers. A failed ping is logged, not fatal, so the service
+// still starts while Redis is unavailable.
+func NewRedisClient(cfg config.RedisConfig, logger *slog.Logger) *redis.Client {
+	opts := &redis.Options{
+		Addr:        cfg.Addr,
+		Password:    cfg.Password,
+		DB:          cfg.DB,
+		DialTimeout: cfg.DialTimeout,
 	}
-
-	// Strip redis:// scheme if present
-	if len(redisURL) > 8 && redisURL[:8] == "redis://" {
-		redisURL = redisURL[8:]
+	if cfg.TLS {
+		opts.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
 	}
-
-	client := redis.NewClient(&redis.Options{
-		Addr:     redisURL,
-		Password: "", // no password set
-		DB:       0,  // use default DB
-	})
+	client := redis.NewClient(opts)
+	client.AddHook(tracingHook{})
 
 	// Test connection
-	ctx := context.Background()
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.DialTimeout)
+	defer cancel()
 	if err := client.Ping(ctx).Err(); err != nil {
-		log.Printf("Warning: Failed to connect to Redis at %s: %v", redisURL, err)
+		logger.Warn("failed to connect to Redis", "addr", cfg.Addr, "db", cfg.DB, "tls", cfg.TLS, "error", err)
 	} else {
-		log.Printf("Successfully connected to Redis at %s", redisURL)
+		logger.Info("connected to Redis", "addr", cfg.Addr, "db", cfg.DB, "tls", cfg.TLS)
 	}
 
+	return client
+}
+
+// NewSettlementPublisher creates a new settlement event publisher
+func NewSettlementPublisher(client *redis.Client, logger *slog.Logger) *SettlementPublisher {
 	return &SettlementPublisher{
 		client: client,
+		log:    logger,
 	}
 }
 
 // PublishTransaction publishes a transaction event for settlement processing
 func (p *SettlementPublisher) PublishTransaction(ctx context.Context, merchantID int, amount int64, currency string, txID int) error {
+	ctx, span := tracing.Start(ctx, "settlement.publish", trace.WithAttributes(
+		attribute.Int("merchant.id", merchantID),
+		attribute.Int("transaction.id", txID),
+	))
+	err := p.publishTransaction(ctx, merchantID, amount, currency, txID)
+	tracing.End(span, err)
+	result := "success"
+	if err != nil {
+		result = "failure"
+	}
+	metrics.SettlementPublishes.WithLabelValues(result).Inc()
+	return err
+}
+
+func (p *SettlementPublisher) publishTransaction(ctx context.Context, merchantID int, amount int64, currency string, txID int) error {
 	if p.client == nil {
 		return fmt.Errorf("redis client not initialized")
 	}
@@ -77,8 +104,8 @@ func (p *SettlementPublisher) PublishTransaction(ctx context.Context, merchantID
 	p.client.Expire(ctx, key, 30*24*time.Hour)
 	p.client.Expire(ctx, txKey, 30*24*time.Hour)
 
-	log.Printf("Published settlement event: merchant=%s, amount=%d, currency=%s, tx=%s",
-		merchantKey, amount, currency, txKeyValue)
+	p.log.InfoContext(ctx, "published settlement event",
+		"merchant_id", merchantKey, "amount", amount, "currency", currency, "transaction_id", txKeyValue)
 
 	return nil
 }
@@ -92,6 +119,11 @@ func (p *SettlementPublisher) GetPendingMerchants(ctx context.Context) ([]string
 	return members, nil
 }
 
+// PendingCount returns how many merchants have unsettled amounts
+func (p *SettlementPublisher) PendingCount(ctx context.Context) (int64, error) {
+	return p.client.SCard(ctx, "settlements:merchants:pending").Result()
+}
+
 // GetMerchantAmount returns the unsettled amount for a merchant
 func (p *SettlementPublisher) GetMerchantAmount(ctx context.Context, merchantID string) (int64, error) {
 	key := "settlements:amounts:" + merchantID
@@ -126,7 +158,7 @@ func (p *SettlementPublisher) ClearMerchantPending(ctx context.Context, merchant
 
 	_, err := pipe.Exec(ctx)
 	if err != nil {
-		log.Printf("Failed to clear Redis data for merchant %s: %v", merchantID, err)
+		p.log.ErrorContext(ctx, "failed to clear settlement data", "merchant_id", merchantID, "error", err)
 	}
 	return err
 }
@@ -0,0 +1,53 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"net"
+
+	"github.com/redis/go-redis/v9"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/kodra-pay/transaction-service/internal/tracing"
+)
+
+// tracingHook gives every Redis command and pipeline its own client span.
+type tracingHook struct{}
+
+func (tracingHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return next(ctx, network, addr)
+	}
+}
+
+func (tracingHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		ctx, span := startRedisSpan(ctx, "redis "+cmd.Name(), cmd.Name())
+		err := next(ctx, cmd)
+		tracing.End(span, redisError(err))
+		return err
+	}
+}
+
+func (tracingHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		ctx, span := startRedisSpan(ctx, "redis pipeline", "pipeline")
+		err := next(ctx, cmds)
+		tracing.End(span, redisError(err))
+		return err
+	}
+}
+
+func startRedisSpan(ctx context.Context, name, operation string) (context.Context, trace.Span) {
+	return tracing.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.DBSystemRedis, semconv.DBOperationName(operation)))
+}
+
+// redisError drops redis.Nil, which only means a key was missing.
+func redisError(err error) error {
+	if errors.Is(err, redis.Nil) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,119 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/kodra-pay/transaction-service/internal/models"
+)
+
+type AdjustmentRepository struct {
+	db *sql.DB
+}
+
+func NewAdjustmentRepository(db *sql.DB) *AdjustmentRepository {
+	return &AdjustmentRepository{db: db}
+}
+
+const adjustmentColumns = `id, transaction_id, reference, merchant_id, direction, amount, currency, reason, status, proposed_by, COALESCE(decided_by, ''), COALESCE(decision_notes, ''), decided_at, created_at, updated_at`
+
+func scanAdjustment(row interface{ Scan(...interface{}) error }) (*models.Adjustment, error) {
+	var adj models.Adjustment
+	var decidedAt sql.NullTime
+	if err := row.Scan(
+		&adj.ID, &adj.TransactionID, &adj.Reference, &adj.MerchantID, &adj.Direction, &adj.Amount, &adj.Currency,
+		&adj.Reason, &adj.Status, &adj.ProposedBy, &adj.DecidedBy, &adj.DecisionNotes,
+		&decidedAt, &adj.CreatedAt, &adj.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	if decidedAt.Valid {
+		adj.DecidedAt = &decidedAt.Time
+	}
+	return &adj, nil
+}
+
+func (r *AdjustmentRepository) GetByID(ctx context.Context, id int) (*models.Adjustment, error) {
+	adj, err := scanAdjustment(r.db.QueryRowContext(ctx, `SELECT `+adjustmentColumns+` FROM ledger_adjustments WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return adj, err
+}
+
+// List returns adjustments oldest first, so approvers work them in the order
+// they were proposed. A zero merchantID or empty status matches all.
+func (r *AdjustmentRepository) List(ctx context.Context, merchantID int, status string, limit int) ([]*models.Adjustment, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+adjustmentColumns+`
+		FROM ledger_adjustments
+		WHERE ($1 = 0 OR merchant_id = $1)
+		  AND ($2 = '' OR status = $2)
+		ORDER BY created_at, id
+		LIMIT $3
+	`, merchantID, status, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []*models.Adjustment
+	for rows.Next() {
+		adj, err := scanAdjustment(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, adj)
+	}
+	return list, rows.Err()
+}
+
+// Decide records adj.DecidedBy's decision, moves the adjustment's pending
+// transaction to tx.Status with its timeline event and posts entry, if not
+// nil, in one database transaction. It returns false if the adjustment is no
+// longer pending or adj.DecidedBy proposed it.
+func (r *AdjustmentRepository) Decide(ctx context.Context, adj *models.Adjustment, tx *models.Transaction, ev *models.TransactionEvent, entry *models.LedgerEntry) (bool, error) {
+	dbTx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer dbTx.Rollback()
+
+	var decidedAt sql.NullTime
+	err = dbTx.QueryRowContext(ctx, `
+		UPDATE ledger_adjustments
+		SET status = $1, decided_by = $2, decision_notes = $3, decided_at = NOW(), updated_at = NOW()
+		WHERE id = $4 AND status = $5 AND proposed_by <> $2
+		RETURNING decided_at, updated_at
+	`, adj.Status, adj.DecidedBy, adj.DecisionNotes, adj.ID, models.AdjustmentStatusPending).Scan(&decidedAt, &adj.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if decidedAt.Valid {
+		adj.DecidedAt = &decidedAt.Time
+	}
+
+	err = dbTx.QueryRowContext(ctx, `
+		UPDATE transactions SET status = $1, updated_at = NOW()
+		WHERE id = $2 AND status = $3
+		RETURNING updated_at
+	`, tx.Status, tx.ID, models.TransactionStatusPending).Scan(&tx.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if err := insertStatusEvent(ctx, dbTx, tx, ev); err != nil {
+		return false, err
+	}
+	if entry != nil {
+		if err := insertLedgerEntry(ctx, dbTx, entry); err != nil {
+			return false, err
+		}
+	}
+	return true, dbTx.Commit()
+}
@@ -0,0 +1,126 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/kodra-pay/transaction-service/internal/models"
+)
+
+type APIKeyRepository struct {
+	db *sql.DB
+}
+
+func NewAPIKeyRepository(db *sql.DB) *APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+const apiKeyColumns = `id, merchant_id, prefix, key_hash, mode, status, expires_at, last_used_at, revoked_at, created_at`
+
+func scanAPIKey(row interface{ Scan(...interface{}) error }) (*models.APIKey, error) {
+	var k models.APIKey
+	var expiresAt, lastUsedAt, revokedAt sql.NullTime
+	if err := row.Scan(&k.ID, &k.MerchantID, &k.Prefix, &k.KeyHash, &k.Mode, &k.Status, &expiresAt, &lastUsedAt, &revokedAt, &k.CreatedAt); err != nil {
+		return nil, err
+	}
+	k.ExpiresAt = nullTimePtr(expiresAt)
+	k.LastUsedAt = nullTimePtr(lastUsedAt)
+	k.RevokedAt = nullTimePtr(revokedAt)
+	return &k, nil
+}
+
+func (r *APIKeyRepository) Create(ctx context.Context, k *models.APIKey) error {
+	return r.db.QueryRowContext(ctx, `
+		INSERT INTO api_keys (merchant_id, prefix, key_hash, mode, status, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW())
+		RETURNING id, created_at
+	`, k.MerchantID, k.Prefix, k.KeyHash, k.Mode, k.Status, k.ExpiresAt).Scan(&k.ID, &k.CreatedAt)
+}
+
+func (r *APIKeyRepository) GetByHash(ctx context.Context, hash string) (*models.APIKey, error) {
+	k, err := scanAPIKey(r.db.QueryRowContext(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE key_hash = $1`, hash))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return k, err
+}
+
+func (r *APIKeyRepository) GetByID(ctx context.Context, id int) (*models.APIKey, error) {
+	k, err := scanAPIKey(r.db.QueryRowContext(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return k, err
+}
+
+func (r *APIKeyRepository) ListByMerchant(ctx context.Context, merchantID int) ([]*models.APIKey, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+apiKeyColumns+`
+		FROM api_keys
+		WHERE merchant_id = $1
+		ORDER BY created_at DESC
+	`, merchantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []*models.APIKey
+	for rows.Next() {
+		k, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, k)
+	}
+	return list, rows.Err()
+}
+
+// Rotate stores the replacement key and schedules the old key to expire at
+// graceUntil, in one database transaction. An old key that already expires
+// sooner keeps its earlier expiry.
+func (r *APIKeyRepository) Rotate(ctx context.Context, oldID int, graceUntil time.Time, replacement *models.APIKey) error {
+	dbTx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer dbTx.Rollback()
+
+	if _, err := dbTx.ExecContext(ctx, `
+		UPDATE api_keys
+		SET expires_at = LEAST(COALESCE(expires_at, $2), $2)
+		WHERE id = $1
+	`, oldID, graceUntil); err != nil {
+		return err
+	}
+	if err := dbTx.QueryRowContext(ctx, `
+		INSERT INTO api_keys (merchant_id, prefix, key_hash, mode, status, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW())
+		RETURNING id, created_at
+	`, replacement.MerchantID, replacement.Prefix, replacement.KeyHash, replacement.Mode, replacement.Status, replacement.ExpiresAt,
+	).Scan(&replacement.ID, &replacement.CreatedAt); err != nil {
+		return err
+	}
+	return dbTx.Commit()
+}
+
+func (r *APIKeyRepository) Revoke(ctx context.Context, id int) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE api_keys SET status = $1, revoked_at = NOW()
+		WHERE id = $2 AND status <> $1
+	`, models.APIKeyStatusRevoked, id)
+	return err
+}
+
+func (r *APIKeyRepository) TouchLastUsed(ctx context.Context, id int) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE api_keys SET last_used_at = NOW() WHERE id = $1`, id)
+	return err
+}
+
+func nullTimePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
@@ -0,0 +1,96 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/kodra-pay/transaction-service/internal/models"
+)
+
+// auditChainLock serialises appends so every entry links to the one before it.
+const auditChainLock = 7004001
+
+type AuditRepository struct {
+	db *sql.DB
+}
+
+func NewAuditRepository(db *sql.DB) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+// Append links the entry to the end of the chain, computes its hash and
+// stores it. The chain head is read under an advisory lock so concurrent
+// appends cannot fork the chain.
+func (r *AuditRepository) Append(ctx context.Context, e *models.AuditEntry) error {
+	dbTx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer dbTx.Rollback()
+
+	if _, err := dbTx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, auditChainLock); err != nil {
+		return err
+	}
+	err = dbTx.QueryRowContext(ctx, `SELECT hash FROM audit_log ORDER BY id DESC LIMIT 1`).Scan(&e.PrevHash)
+	if err == sql.ErrNoRows {
+		e.PrevHash = ""
+	} else if err != nil {
+		return err
+	}
+
+	// Postgres keeps microseconds; truncate first so the stored row hashes the same.
+	e.CreatedAt = time.Now().UTC().Truncate(time.Microsecond)
+	e.Hash = e.ComputeHash()
+
+	if err := dbTx.QueryRowContext(ctx, `
+		INSERT INTO audit_log (transaction_id, reference, action, actor_kind, actor, actor_merchant_id, request_id, before, after, prev_hash, hash, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING id
+	`, e.TransactionID, e.Reference, e.Action, e.ActorKind, e.Actor, e.ActorMerchant, e.RequestID,
+		nullableText(e.Before), nullableText(e.After), e.PrevHash, e.Hash, e.CreatedAt,
+	).Scan(&e.ID); err != nil {
+		return err
+	}
+	return dbTx.Commit()
+}
+
+// ListByReference returns a transaction's entries oldest first.
+func (r *AuditRepository) ListByReference(ctx context.Context, reference string) ([]*models.AuditEntry, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, transaction_id, reference, action, actor_kind, actor, actor_merchant_id, request_id,
+		       COALESCE(before, ''), COALESCE(after, ''), prev_hash, hash, created_at
+		FROM audit_log
+		WHERE reference = $1
+		ORDER BY id
+	`, reference)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []*models.AuditEntry
+	for rows.Next() {
+		var e models.AuditEntry
+		var before, after string
+		if err := rows.Scan(&e.ID, &e.TransactionID, &e.Reference, &e.Action, &e.ActorKind, &e.Actor, &e.ActorMerchant,
+			&e.RequestID, &before, &after, &e.PrevHash, &e.Hash, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		if before != "" {
+			e.Before = []byte(before)
+		}
+		if after != "" {
+			e.After = []byte(after)
+		}
+		list = append(list, &e)
+	}
+	return list, rows.Err()
+}
+
+func nullableText(b []byte) interface{} {
+	if len(b) == 0 {
+		return nil
+	}
+	return string(b)
+}
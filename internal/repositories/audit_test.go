@@ -0,0 +1,48 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/kodra-pay/transaction-service/internal/models"
+)
+
+func TestAuditEntriesFormAVerifiableChain(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+	repo := NewAuditRepository(db)
+	reference := fmt.Sprintf("audit-%d", time.Now().UnixNano())
+
+	for _, action := range []string{models.AuditActionCreate, models.AuditActionCapture, models.AuditActionRefund} {
+		e := &models.AuditEntry{
+			TransactionID: 1,
+			Reference:     reference,
+			Action:        action,
+			ActorKind:     "api_key",
+			Actor:         "kp_live_test",
+			After:         []byte(`{"status":"` + action + `"}`),
+		}
+		if err := repo.Append(ctx, e); err != nil {
+			t.Fatalf("append %s: %v", action, err)
+		}
+	}
+
+	entries, err := repo.ListByReference(ctx, reference)
+	if err != nil || len(entries) != 3 {
+		t.Fatalf("list: %d entries, %v", len(entries), err)
+	}
+	for i, e := range entries {
+		if e.ComputeHash() != e.Hash {
+			t.Errorf("entry %d does not verify after a round trip", e.ID)
+		}
+		if i > 0 && e.PrevHash != entries[i-1].Hash {
+			t.Errorf("entry %d is not linked to entry %d", e.ID, entries[i-1].ID)
+		}
+	}
+
+	if _, err := db.ExecContext(ctx, `UPDATE audit_log SET actor = 'someone' WHERE id = $1`, entries[0].ID); err == nil {
+		t.Error("audit_log accepted an update")
+	}
+}
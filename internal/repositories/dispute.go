@@ -0,0 +1,200 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/kodra-pay/transaction-service/internal/models"
+)
+
+type DisputeRepository struct {
+	db *sql.DB
+}
+
+func NewDisputeRepository(db *sql.DB) *DisputeRepository {
+	return &DisputeRepository{db: db}
+}
+
+const disputeColumns = `id, transaction_id, reference, merchant_id, amount, currency, reason_code, status, evidence_due_by, livemode, resolved_at, created_at, updated_at`
+
+func scanDispute(row interface{ Scan(...interface{}) error }) (*models.Dispute, error) {
+	var d models.Dispute
+	var resolvedAt sql.NullTime
+	if err := row.Scan(
+		&d.ID, &d.TransactionID, &d.Reference, &d.MerchantID, &d.Amount, &d.Currency,
+		&d.ReasonCode, &d.Status, &d.EvidenceDueBy, &d.Livemode, &resolvedAt, &d.CreatedAt, &d.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	if resolvedAt.Valid {
+		d.ResolvedAt = &resolvedAt.Time
+	}
+	return &d, nil
+}
+
+// Results of opening a dispute.
+const (
+	DisputeCreated     = 0
+	DisputeNotCaptured = 1
+	DisputeAlreadyOpen = 2
+	DisputeAlreadyLost = 3
+)
+
+// Create opens a dispute and records the ledger debit for it in a single
+// database transaction, so a dispute never exists without its chargeback. It
+// locks the transaction row, as refunds do, and returns one of the Dispute*
+// codes: a transaction that is no longer captured, or that already has an
+// open or lost dispute, is not charged back again. With at most one dispute
+// holding funds, the amount taken back never exceeds the transaction's.
+func (r *DisputeRepository) Create(ctx context.Context, d *models.Dispute, debit *models.LedgerEntry) (int, error) {
+	dbTx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer dbTx.Rollback()
+
+	var status string
+	err = dbTx.QueryRowContext(ctx, `SELECT status FROM transactions WHERE id = $1 FOR UPDATE`, d.TransactionID).Scan(&status)
+	if err == sql.ErrNoRows || (err == nil && status != models.TransactionStatusSuccess) {
+		return DisputeNotCaptured, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	var open, lost bool
+	if err := dbTx.QueryRowContext(ctx, `
+		SELECT
+			EXISTS (SELECT 1 FROM disputes WHERE transaction_id = $1 AND status IN ($2, $3)),
+			EXISTS (SELECT 1 FROM disputes WHERE transaction_id = $1 AND status = $4)
+	`, d.TransactionID, models.DisputeStatusNeedsResponse, models.DisputeStatusUnderReview, models.DisputeStatusLost,
+	).Scan(&open, &lost); err != nil {
+		return 0, err
+	}
+	switch {
+	case open:
+		return DisputeAlreadyOpen, nil
+	case lost:
+		return DisputeAlreadyLost, nil
+	}
+
+	if err := dbTx.QueryRowContext(ctx, `
+		INSERT INTO disputes (transaction_id, reference, merchant_id, amount, currency, reason_code, status, evidence_due_by, livemode, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW(), NOW())
+		RETURNING id, created_at, updated_at
+	`, d.TransactionID, d.Reference, d.MerchantID, d.Amount, d.Currency, d.ReasonCode, d.Status, d.EvidenceDueBy, d.Livemode,
+	).Scan(&d.ID, &d.CreatedAt, &d.UpdatedAt); err != nil {
+		return 0, err
+	}
+
+	if debit != nil {
+		if err := insertLedgerEntry(ctx, dbTx, debit); err != nil {
+			return 0, err
+		}
+	}
+	return DisputeCreated, dbTx.Commit()
+}
+
+func (r *DisputeRepository) GetByID(ctx context.Context, id int) (*models.Dispute, error) {
+	d, err := scanDispute(r.db.QueryRowContext(ctx, `SELECT `+disputeColumns+` FROM disputes WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return d, err
+}
+
+// List returns disputes, optionally filtered by merchant (0 for all), status
+// ("" for all) and mode (nil for both).
+func (r *DisputeRepository) List(ctx context.Context, merchantID int, status string, livemode *bool, limit int) ([]*models.Dispute, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+disputeColumns+`
+		FROM disputes
+		WHERE ($1 = 0 OR merchant_id = $1)
+		  AND ($2 = '' OR status = $2)
+		  AND ($3::boolean IS NULL OR livemode = $3)
+		ORDER BY created_at DESC
+		LIMIT $4
+	`, merchantID, status, livemode, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []*models.Dispute
+	for rows.Next() {
+		d, err := scanDispute(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, d)
+	}
+	return list, rows.Err()
+}
+
+// UpdateStatus moves a dispute to a new status, guarding against concurrent
+// updates by requiring the current status to still be `from`. A ledger entry,
+// if given, is written in the same database transaction. It returns false if
+// the dispute was no longer in `from`.
+func (r *DisputeRepository) UpdateStatus(ctx context.Context, d *models.Dispute, from string, entry *models.LedgerEntry) (bool, error) {
+	dbTx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer dbTx.Rollback()
+
+	var resolvedAt sql.NullTime
+	err = dbTx.QueryRowContext(ctx, `
+		UPDATE disputes
+		SET status = $1,
+		    resolved_at = CASE WHEN $1 IN ($4, $5) THEN NOW() ELSE resolved_at END,
+		    updated_at = NOW()
+		WHERE id = $2 AND status = $3
+		RETURNING resolved_at, updated_at
+	`, d.Status, d.ID, from, models.DisputeStatusWon, models.DisputeStatusLost).Scan(&resolvedAt, &d.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if resolvedAt.Valid {
+		d.ResolvedAt = &resolvedAt.Time
+	}
+
+	if entry != nil {
+		if err := insertLedgerEntry(ctx, dbTx, entry); err != nil {
+			return false, err
+		}
+	}
+	return true, dbTx.Commit()
+}
+
+func (r *DisputeRepository) AddEvidence(ctx context.Context, e *models.DisputeEvidence) error {
+	return r.db.QueryRowContext(ctx, `
+		INSERT INTO dispute_evidence (dispute_id, type, description, file_url, submitted_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+		RETURNING id, created_at
+	`, e.DisputeID, e.Type, e.Description, e.FileURL, e.SubmittedBy).Scan(&e.ID, &e.CreatedAt)
+}
+
+func (r *DisputeRepository) ListEvidence(ctx context.Context, disputeID int) ([]*models.DisputeEvidence, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, dispute_id, type, COALESCE(description, ''), COALESCE(file_url, ''), COALESCE(submitted_by, ''), created_at
+		FROM dispute_evidence
+		WHERE dispute_id = $1
+		ORDER BY created_at
+	`, disputeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []*models.DisputeEvidence
+	for rows.Next() {
+		var e models.DisputeEvidence
+		if err := rows.Scan(&e.ID, &e.DisputeID, &e.Type, &e.Description, &e.FileURL, &e.SubmittedBy, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, &e)
+	}
+	return list, rows.Err()
+}
@@ -0,0 +1,145 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kodra-pay/transaction-service/internal/models"
+)
+
+func newTestDispute(tx *models.Transaction) (*models.Dispute, *models.LedgerEntry) {
+	d := &models.Dispute{
+		TransactionID: tx.ID,
+		Reference:     tx.Reference,
+		MerchantID:    tx.MerchantID,
+		Amount:        tx.Amount,
+		Currency:      tx.Currency,
+		ReasonCode:    "fraud",
+		Status:        models.DisputeStatusNeedsResponse,
+		EvidenceDueBy: time.Now().Add(time.Hour),
+	}
+	debit := &models.LedgerEntry{
+		MerchantID:    tx.MerchantID,
+		TransactionID: tx.ID,
+		EntryType:     models.LedgerEntryDebit,
+		Amount:        tx.Amount,
+		Currency:      tx.Currency,
+		Reference:     tx.Reference,
+	}
+	return d, debit
+}
+
+func TestDisputeChargesBackOnce(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+	repo := NewDisputeRepository(db)
+	merchantID := testMerchantID()
+	tx := insertTestTransaction(t, db, merchantID, 5000, "NGN", "success")
+
+	var wg sync.WaitGroup
+	codes := make(chan int, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			d, debit := newTestDispute(tx)
+			code, err := repo.Create(ctx, d, debit)
+			if err != nil {
+				t.Errorf("create dispute: %v", err)
+			}
+			codes <- code
+		}()
+	}
+	wg.Wait()
+	close(codes)
+	created := 0
+	for code := range codes {
+		switch code {
+		case DisputeCreated:
+			created++
+		case DisputeAlreadyOpen:
+		default:
+			t.Errorf("concurrent create returned %d", code)
+		}
+	}
+	if created != 1 {
+		t.Fatalf("%d disputes created, want 1", created)
+	}
+
+	var debits int
+	if err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM wallet_ledger WHERE transaction_id = $1 AND entry_type = $2`,
+		tx.ID, models.LedgerEntryDebit).Scan(&debits); err != nil {
+		t.Fatal(err)
+	}
+	if debits != 1 {
+		t.Errorf("%d chargeback debits, want 1", debits)
+	}
+
+	list, err := repo.List(ctx, merchantID, "", nil, 10)
+	if err != nil || len(list) != 1 {
+		t.Fatalf("list disputes: %d, %v", len(list), err)
+	}
+	lost := list[0]
+	lost.Status = models.DisputeStatusLost
+	if ok, err := repo.UpdateStatus(ctx, lost, models.DisputeStatusNeedsResponse, nil); !ok || err != nil {
+		t.Fatalf("lose dispute: %v, %v", ok, err)
+	}
+	d, debit := newTestDispute(tx)
+	if code, err := repo.Create(ctx, d, debit); code != DisputeAlreadyLost || err != nil {
+		t.Errorf("dispute after a lost one: %d, %v, want DisputeAlreadyLost", code, err)
+	}
+	checkRunningBalances(t, db, merchantID)
+}
+
+func TestDisputeRequiresCapturedTransaction(t *testing.T) {
+	db := openTestDB(t)
+	repo := NewDisputeRepository(db)
+	tx := insertTestTransaction(t, db, testMerchantID(), 5000, "NGN", "refunded")
+
+	d, debit := newTestDispute(tx)
+	if code, err := repo.Create(context.Background(), d, debit); code != DisputeNotCaptured || err != nil {
+		t.Errorf("dispute on a refunded transaction: %d, %v, want DisputeNotCaptured", code, err)
+	}
+}
+
+func TestRefundAndChargebackAreExclusive(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+	disputes := NewDisputeRepository(db)
+	transactions := NewTransactionRepository(db, nil)
+	merchantID := testMerchantID()
+
+	for i := 0; i < 10; i++ {
+		tx := insertTestTransaction(t, db, merchantID, 5000, "NGN", "success")
+		var wg sync.WaitGroup
+		var code int
+		var refunded bool
+		var refundErr error
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			d, debit := newTestDispute(tx)
+			var err error
+			if code, err = disputes.Create(ctx, d, debit); err != nil {
+				t.Errorf("create dispute: %v", err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			refund := *tx
+			refund.Status = models.TransactionStatusRefunded
+			refunded, refundErr = transactions.Refund(ctx, &refund, nil, models.TransactionStatusSuccess)
+		}()
+		wg.Wait()
+
+		switch {
+		case code == DisputeCreated && !refunded && errors.Is(refundErr, ErrTransactionDisputed):
+		case code == DisputeNotCaptured && refunded && refundErr == nil:
+		default:
+			t.Errorf("transaction %d: dispute %d, refund %v, %v; want exactly one to take the money back", tx.ID, code, refunded, refundErr)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+
+	"github.com/kodra-pay/transaction-service/internal/models"
+	"github.com/kodra-pay/transaction-service/internal/pii"
+)
+
+type ErasureRepository struct {
+	db  *sql.DB
+	pii *pii.Cipher
+}
+
+func NewErasureRepository(db *sql.DB, cipher *pii.Cipher) *ErasureRepository {
+	return &ErasureRepository{db: db, pii: cipher}
+}
+
+const erasureColumns = `id, customer_id, COALESCE(email, ''), COALESCE(email_index, ''), status, requested_by, COALESCE(reason, ''),
+	transactions_affected, ledger_entries_affected, COALESCE(error, ''), started_at, completed_at, created_at`
+
+func (r *ErasureRepository) scan(row interface{ Scan(...interface{}) error }) (*models.ErasureRequest, error) {
+	var e models.ErasureRequest
+	var startedAt, completedAt sql.NullTime
+	if err := row.Scan(
+		&e.ID, &e.CustomerID, &e.Email, &e.EmailIndex, &e.Status, &e.RequestedBy, &e.Reason,
+		&e.TransactionsAffected, &e.LedgerEntriesAffected, &e.Error, &startedAt, &completedAt, &e.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	var err error
+	if e.Email, err = r.pii.Decrypt(e.Email); err != nil {
+		return nil, fmt.Errorf("decrypt email of erasure request %d: %w", e.ID, err)
+	}
+	if startedAt.Valid {
+		e.StartedAt = &startedAt.Time
+	}
+	if completedAt.Valid {
+		e.CompletedAt = &completedAt.Time
+	}
+	return &e, nil
+}
+
+// Create records a pending erasure request.
+func (r *ErasureRepository) Create(ctx context.Context, e *models.ErasureRequest) error {
+	email, err := r.pii.Encrypt(e.Email)
+	if err != nil {
+		return err
+	}
+	e.EmailIndex = r.pii.BlindIndex(e.Email)
+	e.Status = models.ErasureStatusPending
+	return r.db.QueryRowContext(ctx, `
+		INSERT INTO erasure_requests (customer_id, email, email_index, status, requested_by, reason, created_at)
+		VALUES ($1, NULLIF($2, ''), NULLIF($3, ''), $4, $5, NULLIF($6, ''), NOW())
+		RETURNING id, created_at
+	`, e.CustomerID, email, e.EmailIndex, e.Status, e.RequestedBy, e.Reason).Scan(&e.ID, &e.CreatedAt)
+}
+
+func (r *ErasureRepository) GetByID(ctx context.Context, id int) (*models.ErasureRequest, error) {
+	e, err := r.scan(r.db.QueryRowContext(ctx, `SELECT `+erasureColumns+` FROM erasure_requests WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return e, err
+}
+
+// ClaimNext marks the oldest pending request as running and returns it, or
+// nil if there is none. Requests left running by a crashed worker are picked
+// up again once stale, which is safe because erasure is idempotent.
+func (r *ErasureRepository) ClaimNext(ctx context.Context) (*models.ErasureRequest, error) {
+	e, err := r.scan(r.db.QueryRowContext(ctx, `
+		UPDATE erasure_requests
+		SET status = $1, started_at = NOW()
+		WHERE id = (
+			SELECT id FROM erasure_requests
+			WHERE status = $2 OR (status = $1 AND started_at < NOW() - INTERVAL '15 minutes')
+			ORDER BY id
+			FOR UPDATE SKIP LOCKED
+			LIMIT 1
+		)
+		RETURNING `+erasureColumns,
+		models.ErasureStatusRunning, models.ErasureStatusPending))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return e, err
+}
+
+// Complete records the outcome of a request and forgets its email.
+func (r *ErasureRepository) Complete(ctx context.Context, e *models.ErasureRequest) error {
+	e.Status = models.ErasureStatusCompleted
+	e.Email = ""
+	var completedAt sql.NullTime
+	err := r.db.QueryRowContext(ctx, `
+		UPDATE erasure_requests
+		SET status = $1, email = NULL, transactions_affected = $2, ledger_entries_affected = $3,
+		    error = NULL, completed_at = NOW()
+		WHERE id = $4
+		RETURNING completed_at
+	`, e.Status, e.TransactionsAffected, e.LedgerEntriesAffected, e.ID).Scan(&completedAt)
+	if completedAt.Valid {
+		e.CompletedAt = &completedAt.Time
+	}
+	return err
+}
+
+// Fail records why a request could not run. It stays failed until resubmitted.
+func (r *ErasureRepository) Fail(ctx context.Context, id int, reason string) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE erasure_requests SET status = $1, error = $2 WHERE id = $3
+	`, models.ErasureStatusFailed, reason, id)
+	return err
+}
+
+// CountByStatus counts requests in any of the given statuses.
+func (r *ErasureRepository) CountByStatus(ctx context.Context, statuses ...string) (int64, error) {
+	var count int64
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM erasure_requests WHERE status = ANY($1)`, pq.Array(statuses)).Scan(&count)
+	return count, err
+}
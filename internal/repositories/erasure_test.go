@@ -0,0 +1,81 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/kodra-pay/transaction-service/internal/models"
+	"github.com/kodra-pay/transaction-service/internal/pii"
+)
+
+func TestPseudonymiseErasesCustomer(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+	repo := NewTransactionRepository(db, nil)
+	merchantID := testMerchantID()
+	customerID := testMerchantID()
+	email := fmt.Sprintf("erase-%d@example.com", customerID)
+
+	tx := &models.Transaction{Reference: fmt.Sprintf("erase-%d", customerID), MerchantID: merchantID, Amount: 100, Currency: "NGN",
+		Status: "pending", CustomerID: customerID, CustomerEmail: email, CustomerName: "Ada Lovelace"}
+	if err := repo.Create(ctx, tx, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := NewLedgerRepository(db).Record(ctx, &models.LedgerEntry{MerchantID: merchantID, TransactionID: tx.ID, EntryType: models.LedgerEntryCredit,
+		Amount: 100, Currency: "NGN", Reference: tx.Reference, Description: "Payment from " + email}); err != nil {
+		t.Fatal(err)
+	}
+
+	pseudonym := models.ErasedEmail(customerID)
+	txCount, ledgerCount, err := repo.Pseudonymise(ctx, customerID, email, pseudonym)
+	if err != nil || txCount != 1 || ledgerCount != 1 {
+		t.Fatalf("pseudonymise: %d transactions, %d ledger entries, %v", txCount, ledgerCount, err)
+	}
+
+	got, err := repo.GetByID(ctx, tx.ID)
+	if err != nil || got.CustomerEmail != pseudonym || got.CustomerName != "" || got.CustomerID != 0 || got.Amount != 100 {
+		t.Errorf("erased transaction: %+v, %v", got, err)
+	}
+	var description string
+	if err := db.QueryRowContext(ctx, `SELECT description FROM wallet_ledger WHERE transaction_id = $1`, tx.ID).Scan(&description); err != nil {
+		t.Fatal(err)
+	}
+	if description != "Payment from "+pseudonym {
+		t.Errorf("ledger description %q", description)
+	}
+	var plain *pii.Cipher
+	if n, err := repo.CountByCustomer(ctx, customerID, plain.BlindIndex(email)); n != 0 || err != nil {
+		t.Errorf("%d transactions still linked to the customer, %v", n, err)
+	}
+}
+
+func TestPseudonymSealedUnderTheCurrentKey(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+	repo := NewTransactionRepository(db, testCipher(t))
+	merchantID := testMerchantID()
+	customerID := testMerchantID()
+	email := fmt.Sprintf("erase-%d@example.com", customerID)
+
+	tx := &models.Transaction{Reference: fmt.Sprintf("erase-sealed-%d", customerID), MerchantID: merchantID, Amount: 100, Currency: "NGN",
+		Status: "pending", CustomerID: customerID, CustomerEmail: email}
+	if err := repo.Create(ctx, tx, nil); err != nil {
+		t.Fatal(err)
+	}
+	pseudonym := models.ErasedEmail(customerID)
+	if _, _, err := repo.Pseudonymise(ctx, customerID, email, pseudonym); err != nil {
+		t.Fatal(err)
+	}
+
+	var stored, keyID string
+	if err := db.QueryRowContext(ctx, `SELECT customer_email, pii_key_id FROM transactions WHERE id = $1`, tx.ID).Scan(&stored, &keyID); err != nil {
+		t.Fatal(err)
+	}
+	if stored == pseudonym || keyID != "test" {
+		t.Errorf("pseudonym stored as %q under key %q, want it sealed under test", stored, keyID)
+	}
+	if got, err := repo.GetByID(ctx, tx.ID); err != nil || got.CustomerEmail != pseudonym {
+		t.Errorf("read back %+v, %v", got, err)
+	}
+}
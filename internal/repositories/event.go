@@ -0,0 +1,58 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/kodra-pay/transaction-service/internal/models"
+)
+
+type EventRepository struct {
+	db *sql.DB
+}
+
+func NewEventRepository(db *sql.DB) *EventRepository {
+	return &EventRepository{db: db}
+}
+
+// ListByTransaction returns the transaction's timeline, oldest first.
+func (r *EventRepository) ListByTransaction(ctx context.Context, transactionID int) ([]*models.TransactionEvent, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, transaction_id, reference, from_status, to_status, source, COALESCE(metadata::text, ''), created_at
+		FROM transaction_events
+		WHERE transaction_id = $1
+		ORDER BY id
+	`, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []*models.TransactionEvent
+	for rows.Next() {
+		var e models.TransactionEvent
+		var metadata string
+		if err := rows.Scan(&e.ID, &e.TransactionID, &e.Reference, &e.FromStatus, &e.ToStatus, &e.Source, &metadata, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		if metadata != "" {
+			e.Metadata = []byte(metadata)
+		}
+		list = append(list, &e)
+	}
+	return list, rows.Err()
+}
+
+// insertTransactionEvent writes a timeline entry, normally inside the database
+// transaction that changed the status.
+func insertTransactionEvent(ctx context.Context, db execer, e *models.TransactionEvent) error {
+	var metadata interface{}
+	if len(e.Metadata) > 0 {
+		metadata = string(e.Metadata)
+	}
+	return db.QueryRowContext(ctx, `
+		INSERT INTO transaction_events (transaction_id, reference, from_status, to_status, source, metadata, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW())
+		RETURNING id, created_at
+	`, e.TransactionID, e.Reference, e.FromStatus, e.ToStatus, e.Source, metadata).Scan(&e.ID, &e.CreatedAt)
+}
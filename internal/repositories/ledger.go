@@ -0,0 +1,91 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/kodra-pay/transaction-service/internal/models"
+)
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// ledgerLock is the advisory lock namespace held per merchant while reading
+// and extending the merchant's ledger, so payouts checking the balance and
+// entries deriving balance_after never see a half-written running balance.
+const ledgerLock = 7004002
+
+type LedgerRepository struct {
+	db *sql.DB
+}
+
+func NewLedgerRepository(db *sql.DB) *LedgerRepository {
+	return &LedgerRepository{db: db}
+}
+
+func (r *LedgerRepository) Record(ctx context.Context, entry *models.LedgerEntry) error {
+	dbTx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer dbTx.Rollback()
+
+	if err := insertLedgerEntry(ctx, dbTx, entry); err != nil {
+		return err
+	}
+	return dbTx.Commit()
+}
+
+// insertLedgerEntry appends an entry to wallet_ledger, deriving balance_after
+// from the merchant's most recent entry in the same currency. db must be a
+// transaction: the merchant's ledger lock is held until it ends. The most
+// recent entry is the one with the highest id, which is drawn after the lock
+// is taken; created_at is the start time of the writing transaction, so an
+// entry can carry an older timestamp than the one it builds on.
+func insertLedgerEntry(ctx context.Context, db execer, entry *models.LedgerEntry) error {
+	signed := entry.Amount
+	if entry.EntryType == models.LedgerEntryDebit {
+		signed = -entry.Amount
+	}
+	if _, err := db.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1, $2)`, ledgerLock, entry.MerchantID); err != nil {
+		return err
+	}
+	return db.QueryRowContext(ctx, `
+		INSERT INTO wallet_ledger (
+			merchant_id, transaction_id, entry_type, amount, balance_after,
+			currency, description, reference, created_at
+		)
+		VALUES (
+			$1,
+			$2,
+			$3,
+			$4,
+			COALESCE((
+				SELECT balance_after FROM wallet_ledger
+				WHERE merchant_id = $1 AND currency = $6
+				ORDER BY id DESC
+				LIMIT 1
+			), 0) + $5,
+			$6,
+			$7,
+			$8,
+			NOW()
+		)
+		RETURNING id, balance_after, created_at
+	`, entry.MerchantID, entry.TransactionID, entry.EntryType, entry.Amount, signed,
+		entry.Currency, entry.Description, entry.Reference,
+	).Scan(&entry.ID, &entry.BalanceAfter, &entry.CreatedAt)
+}
+
+// ledgerBalance is the merchant's ledger balance in currency, in minor units.
+func ledgerBalance(ctx context.Context, db execer, merchantID int, currency string) (int64, error) {
+	var balance int64
+	err := db.QueryRowContext(ctx, `
+		SELECT COALESCE(SUM(CASE WHEN entry_type = $3 THEN amount ELSE -amount END), 0)
+		FROM wallet_ledger
+		WHERE merchant_id = $1 AND currency = $2
+	`, merchantID, currency, models.LedgerEntryCredit).Scan(&balance)
+	return balance, err
+}
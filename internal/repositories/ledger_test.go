@@ -0,0 +1,51 @@
+package repositories
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/kodra-pay/transaction-service/internal/models"
+)
+
+func TestLedgerBalanceUnderConcurrentInserts(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+	repo := NewLedgerRepository(db)
+	merchantID := testMerchantID()
+	ngn := insertTestTransaction(t, db, merchantID, 100000, "NGN", "success")
+	usd := insertTestTransaction(t, db, merchantID, 100000, "USD", "success")
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 40)
+	for i := 0; i < 40; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			tx, entryType := ngn, models.LedgerEntryCredit
+			if i%4 == 1 {
+				tx = usd
+			}
+			if i%3 == 2 {
+				entryType = models.LedgerEntryDebit
+			}
+			errs <- repo.Record(ctx, &models.LedgerEntry{
+				MerchantID:    merchantID,
+				TransactionID: tx.ID,
+				EntryType:     entryType,
+				Amount:        int64(100 + i),
+				Currency:      tx.Currency,
+				Reference:     tx.Reference,
+			})
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("record ledger entry: %v", err)
+		}
+	}
+
+	checkRunningBalances(t, db, merchantID)
+}
@@ -0,0 +1,69 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/kodra-pay/transaction-service/internal/models"
+)
+
+type LimitRepository struct {
+	db *sql.DB
+}
+
+func NewLimitRepository(db *sql.DB) *LimitRepository {
+	return &LimitRepository{db: db}
+}
+
+func (r *LimitRepository) Get(ctx context.Context, merchantID int, currency string) (*models.MerchantLimit, error) {
+	var l models.MerchantLimit
+	err := r.db.QueryRowContext(ctx, `
+		SELECT merchant_id, currency, tier, max_single_amount, daily_volume, monthly_volume, updated_at
+		FROM merchant_limits
+		WHERE merchant_id = $1 AND currency = $2
+	`, merchantID, currency).Scan(&l.MerchantID, &l.Currency, &l.Tier, &l.MaxSingleAmount, &l.DailyVolume, &l.MonthlyVolume, &l.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+func (r *LimitRepository) ListByMerchant(ctx context.Context, merchantID int) ([]*models.MerchantLimit, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT merchant_id, currency, tier, max_single_amount, daily_volume, monthly_volume, updated_at
+		FROM merchant_limits
+		WHERE merchant_id = $1
+		ORDER BY currency
+	`, merchantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []*models.MerchantLimit
+	for rows.Next() {
+		var l models.MerchantLimit
+		if err := rows.Scan(&l.MerchantID, &l.Currency, &l.Tier, &l.MaxSingleAmount, &l.DailyVolume, &l.MonthlyVolume, &l.UpdatedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, &l)
+	}
+	return list, rows.Err()
+}
+
+func (r *LimitRepository) Upsert(ctx context.Context, l *models.MerchantLimit) error {
+	return r.db.QueryRowContext(ctx, `
+		INSERT INTO merchant_limits (merchant_id, currency, tier, max_single_amount, daily_volume, monthly_volume, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW())
+		ON CONFLICT (merchant_id, currency) DO UPDATE
+		SET tier = EXCLUDED.tier,
+		    max_single_amount = EXCLUDED.max_single_amount,
+		    daily_volume = EXCLUDED.daily_volume,
+		    monthly_volume = EXCLUDED.monthly_volume,
+		    updated_at = NOW()
+		RETURNING updated_at
+	`, l.MerchantID, l.Currency, l.Tier, l.MaxSingleAmount, l.DailyVolume, l.MonthlyVolume).Scan(&l.UpdatedAt)
+}
@@ -1,3 +1,49 @@
 package repositories
 
-// TODO: implement persistence layer for this microservice
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"time"
+
+	_ "github.com/lib/pq"
+
+	"github.com/kodra-pay/transaction-service/internal/config"
+)
+
+// Open connects to Postgres and configures the shared connection pool used by
+// every repository in this service.
+func Open(ctx context.Context, cfg config.PostgresConfig) (*sql.DB, error) {
+	db, err := sql.Open("postgres", cfg.DSN)
+	if err != nil {
+		return nil, fmt.Errorf("open db: %w", err)
+	}
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("ping db: %w", err)
+	}
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	return db, nil
+}
+
+// Connect retries Open with exponential backoff until it succeeds or ctx
+// ends, so a database that is still starting does not crash the service.
+func Connect(ctx context.Context, cfg config.PostgresConfig, logger *slog.Logger) (*sql.DB, error) {
+	backoff := 500 * time.Millisecond
+	for attempt := 1; ; attempt++ {
+		db, err := Open(ctx, cfg)
+		if err == nil {
+			return db, nil
+		}
+		logger.Warn("database unavailable, retrying", "attempt", attempt, "retry_in", backoff, "error", err)
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("gave up connecting to database after %d attempts: %w", attempt, err)
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, 10*time.Second)
+	}
+}
@@ -0,0 +1,91 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math/rand"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/kodra-pay/transaction-service/internal/config"
+	"github.com/kodra-pay/transaction-service/internal/models"
+)
+
+// openTestDB connects to the database named by TEST_DATABASE_URL, which must
+// already have the service's schema, and skips the test when it is unset.
+// Tests write under a random merchant ID so they can share one database.
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	db, err := Open(context.Background(), config.PostgresConfig{DSN: dsn})
+	if err != nil {
+		t.Fatalf("open test database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func testMerchantID() int {
+	return 900_000_000 + rand.Intn(100_000_000)
+}
+
+// insertTestTransaction adds a transaction row directly, bypassing the
+// repository's ledger postings.
+func insertTestTransaction(t *testing.T, db *sql.DB, merchantID int, amount int64, currency, status string) *models.Transaction {
+	t.Helper()
+	tx := &models.Transaction{
+		Reference:  fmt.Sprintf("test-%d-%d", merchantID, time.Now().UnixNano()),
+		MerchantID: merchantID,
+		Amount:     amount,
+		Currency:   currency,
+		Status:     status,
+	}
+	if err := db.QueryRowContext(context.Background(), `
+		INSERT INTO transactions (reference, merchant_id, amount, currency, status)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at, updated_at
+	`, tx.Reference, tx.MerchantID, tx.Amount, tx.Currency, tx.Status).Scan(&tx.ID, &tx.CreatedAt, &tx.UpdatedAt); err != nil {
+		t.Fatalf("insert transaction: %v", err)
+	}
+	return tx
+}
+
+// checkRunningBalances fails the test unless every ledger entry of the
+// merchant carries the running sum of its currency's entries up to it.
+func checkRunningBalances(t *testing.T, db *sql.DB, merchantID int) {
+	t.Helper()
+	rows, err := db.QueryContext(context.Background(), `
+		SELECT id, entry_type, amount, balance_after, currency
+		FROM wallet_ledger
+		WHERE merchant_id = $1
+		ORDER BY id
+	`, merchantID)
+	if err != nil {
+		t.Fatalf("list ledger: %v", err)
+	}
+	defer rows.Close()
+
+	sums := map[string]int64{}
+	for rows.Next() {
+		var e models.LedgerEntry
+		if err := rows.Scan(&e.ID, &e.EntryType, &e.Amount, &e.BalanceAfter, &e.Currency); err != nil {
+			t.Fatalf("scan ledger entry: %v", err)
+		}
+		if e.EntryType == models.LedgerEntryCredit {
+			sums[e.Currency] += e.Amount
+		} else {
+			sums[e.Currency] -= e.Amount
+		}
+		if e.BalanceAfter != sums[e.Currency] {
+			t.Errorf("entry %d: balance_after %d, running sum %d %s", e.ID, e.BalanceAfter, sums[e.Currency], e.Currency)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatalf("list ledger: %v", err)
+	}
+}
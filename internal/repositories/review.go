@@ -0,0 +1,146 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/lib/pq"
+
+	"github.com/kodra-pay/transaction-service/internal/models"
+)
+
+type ReviewRepository struct {
+	db *sql.DB
+}
+
+func NewReviewRepository(db *sql.DB) *ReviewRepository {
+	return &ReviewRepository{db: db}
+}
+
+const reviewColumns = `id, transaction_id, reference, merchant_id, status, COALESCE(reviewer, ''), COALESCE(notes, ''), claimed_at, decided_at, created_at, updated_at`
+
+func scanReview(row interface{ Scan(...interface{}) error }) (*models.Review, error) {
+	var rv models.Review
+	var claimedAt, decidedAt sql.NullTime
+	if err := row.Scan(
+		&rv.ID, &rv.TransactionID, &rv.Reference, &rv.MerchantID, &rv.Status, &rv.Reviewer, &rv.Notes,
+		&claimedAt, &decidedAt, &rv.CreatedAt, &rv.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	if claimedAt.Valid {
+		rv.ClaimedAt = &claimedAt.Time
+	}
+	if decidedAt.Valid {
+		rv.DecidedAt = &decidedAt.Time
+	}
+	return &rv, nil
+}
+
+func (r *ReviewRepository) GetByID(ctx context.Context, id int) (*models.Review, error) {
+	rv, err := scanReview(r.db.QueryRowContext(ctx, `SELECT `+reviewColumns+` FROM transaction_reviews WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return rv, err
+}
+
+// ListByStatus returns reviews oldest first, so the queue is worked in arrival order.
+func (r *ReviewRepository) ListByStatus(ctx context.Context, status string, limit int) ([]*models.Review, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+reviewColumns+`
+		FROM transaction_reviews
+		WHERE status = $1
+		ORDER BY created_at
+		LIMIT $2
+	`, status, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []*models.Review
+	for rows.Next() {
+		rv, err := scanReview(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, rv)
+	}
+	return list, rows.Err()
+}
+
+// CountByStatus counts reviews in any of the given statuses.
+func (r *ReviewRepository) CountByStatus(ctx context.Context, statuses ...string) (int64, error) {
+	var count int64
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM transaction_reviews WHERE status = ANY($1)`, pq.Array(statuses)).Scan(&count)
+	return count, err
+}
+
+// Claim assigns a pending review to a reviewer. It returns false if the
+// review is no longer pending.
+func (r *ReviewRepository) Claim(ctx context.Context, rv *models.Review) (bool, error) {
+	var claimedAt sql.NullTime
+	err := r.db.QueryRowContext(ctx, `
+		UPDATE transaction_reviews
+		SET status = $1, reviewer = $2, claimed_at = NOW(), updated_at = NOW()
+		WHERE id = $3 AND status = $4
+		RETURNING claimed_at, updated_at
+	`, models.ReviewStatusClaimed, rv.Reviewer, rv.ID, models.ReviewStatusPending).Scan(&claimedAt, &rv.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	rv.Status = models.ReviewStatusClaimed
+	if claimedAt.Valid {
+		rv.ClaimedAt = &claimedAt.Time
+	}
+	return true, nil
+}
+
+// Decide records the reviewer's decision and moves the held transaction to
+// tx.Status, with its timeline event, in one database transaction. It returns
+// false if the review is not claimed by rv.Reviewer or the transaction is no
+// longer held.
+func (r *ReviewRepository) Decide(ctx context.Context, rv *models.Review, tx *models.Transaction, ev *models.TransactionEvent) (bool, error) {
+	dbTx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer dbTx.Rollback()
+
+	var decidedAt sql.NullTime
+	err = dbTx.QueryRowContext(ctx, `
+		UPDATE transaction_reviews
+		SET status = $1, notes = $2, decided_at = NOW(), updated_at = NOW()
+		WHERE id = $3 AND status = $4 AND reviewer = $5
+		RETURNING decided_at, updated_at
+	`, rv.Status, rv.Notes, rv.ID, models.ReviewStatusClaimed, rv.Reviewer).Scan(&decidedAt, &rv.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if decidedAt.Valid {
+		rv.DecidedAt = &decidedAt.Time
+	}
+
+	err = dbTx.QueryRowContext(ctx, `
+		UPDATE transactions SET status = $1, updated_at = NOW()
+		WHERE id = $2 AND status = $3
+		RETURNING updated_at
+	`, tx.Status, tx.ID, models.TransactionStatusPendingReview).Scan(&tx.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if err := insertStatusEvent(ctx, dbTx, tx, ev); err != nil {
+		return false, err
+	}
+	return true, dbTx.Commit()
+}
@@ -0,0 +1,77 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/kodra-pay/transaction-service/internal/models"
+)
+
+func TestReviewClaimedOnceAndDecidedByClaimer(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+	txRepo, repo := NewTransactionRepository(db, nil), NewReviewRepository(db)
+	merchantID := testMerchantID()
+	tx := &models.Transaction{
+		Reference:    fmt.Sprintf("review-%d", merchantID),
+		MerchantID:   merchantID,
+		Amount:       100,
+		Currency:     "NGN",
+		Status:       models.TransactionStatusPendingReview,
+		RiskDecision: models.RiskDecisionReview,
+	}
+	if err := txRepo.CreateForReview(ctx, tx, &models.TransactionEvent{Source: models.EventSourceAPI}); err != nil {
+		t.Fatalf("create for review: %v", err)
+	}
+	var id int
+	if err := db.QueryRowContext(ctx, `SELECT id FROM transaction_reviews WHERE transaction_id = $1`, tx.ID).Scan(&id); err != nil {
+		t.Fatalf("find review: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	claimed := make(chan string, 5)
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func(reviewer string) {
+			defer wg.Done()
+			ok, err := repo.Claim(ctx, &models.Review{ID: id, Reviewer: reviewer})
+			if err != nil {
+				t.Errorf("claim: %v", err)
+			}
+			if ok {
+				claimed <- reviewer
+			}
+		}(fmt.Sprintf("reviewer-%d", i))
+	}
+	wg.Wait()
+	close(claimed)
+	if len(claimed) != 1 {
+		t.Fatalf("review claimed %d times, want once", len(claimed))
+	}
+	reviewer := <-claimed
+
+	rv := &models.Review{ID: id, TransactionID: tx.ID, Reviewer: "someone-else", Status: models.ReviewStatusApproved}
+	tx.Status = models.TransactionStatusSuccess
+	ev := &models.TransactionEvent{FromStatus: models.TransactionStatusPendingReview, Source: models.EventSourceAPI}
+	if ok, err := repo.Decide(ctx, rv, tx, ev); ok || err != nil {
+		t.Errorf("decided by a reviewer who did not claim it: %v, %v", ok, err)
+	}
+	rv.Reviewer = reviewer
+	if ok, err := repo.Decide(ctx, rv, tx, ev); !ok || err != nil {
+		t.Fatalf("decide: %v, %v", ok, err)
+	}
+	if got, err := txRepo.GetByID(ctx, tx.ID); err != nil || got.Status != models.TransactionStatusSuccess {
+		t.Errorf("transaction after approval: %+v, %v", got, err)
+	}
+
+	events, err := NewEventRepository(db).ListByTransaction(ctx, tx.ID)
+	if err != nil || len(events) != 2 {
+		t.Fatalf("timeline: %d events, %v", len(events), err)
+	}
+	if events[0].ToStatus != models.TransactionStatusPendingReview ||
+		events[1].FromStatus != models.TransactionStatusPendingReview || events[1].ToStatus != models.TransactionStatusSuccess {
+		t.Errorf("timeline %s -> %s, %s -> %s", events[0].FromStatus, events[0].ToStatus, events[1].FromStatus, events[1].ToStatus)
+	}
+}
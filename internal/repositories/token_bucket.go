@@ -0,0 +1,68 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// takeTokenScript refills a bucket for the time elapsed since it was last
+// touched, then takes one token if available. Time comes from Redis so every
+// instance sees the same clock. It returns whether the token was taken, the
+// whole tokens left, and the milliseconds until a token is available and
+// until the bucket is full.
+var takeTokenScript = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1]) or capacity
+local ts = tonumber(state[2]) or now
+if now > ts then
+  tokens = math.min(capacity, tokens + (now - ts) * rate)
+  ts = now
+end
+local allowed = 0
+local retry = 0
+if tokens >= 1 then
+  tokens = tokens - 1
+  allowed = 1
+else
+  retry = math.ceil((1 - tokens) / rate)
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', ts)
+redis.call('PEXPIRE', KEYS[1], math.ceil(capacity / rate) + 1000)
+return {allowed, math.floor(tokens), retry, math.ceil((capacity - tokens) / rate)}
+`)
+
+// TokenBucket keeps request rate limit buckets in Redis, shared by every
+// instance of the service.
+type TokenBucket struct {
+	client *redis.Client
+}
+
+func NewTokenBucket(client *redis.Client) *TokenBucket {
+	return &TokenBucket{client: client}
+}
+
+// Take removes one token from the bucket at key, which holds up to capacity
+// tokens and refills at capacity per window. It returns whether a token was
+// taken, the tokens left, and the time until a token is available and until
+// the bucket is full.
+func (b *TokenBucket) Take(ctx context.Context, key string, capacity int, window time.Duration) (bool, int, time.Duration, time.Duration, error) {
+	perMilli := float64(capacity) / float64(window.Milliseconds())
+	res, err := takeTokenScript.Run(ctx, b.client, []string{key},
+		capacity, strconv.FormatFloat(perMilli, 'g', -1, 64),
+	).Int64Slice()
+	if err != nil {
+		return false, 0, 0, 0, fmt.Errorf("take rate limit token %s: %w", key, err)
+	}
+	if len(res) != 4 {
+		return false, 0, 0, 0, fmt.Errorf("take rate limit token %s: unexpected reply %v", key, res)
+	}
+	return res[0] == 1, int(res[1]), time.Duration(res[2]) * time.Millisecond, time.Duration(res[3]) * time.Millisecond, nil
+}
@@ -0,0 +1,29 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestTokenBucketTakesUpToCapacity(t *testing.T) {
+	bucket := NewTokenBucket(openTestRedis(t))
+	ctx := context.Background()
+	key := fmt.Sprintf("ratelimit:test:%d", testMerchantID())
+
+	for i := 0; i < 3; i++ {
+		allowed, remaining, _, _, err := bucket.Take(ctx, key, 3, time.Minute)
+		if err != nil || !allowed || remaining != 2-i {
+			t.Fatalf("take %d: %v, %d remaining, %v", i, allowed, remaining, err)
+		}
+	}
+	allowed, remaining, retryAfter, reset, err := bucket.Take(ctx, key, 3, time.Minute)
+	if err != nil || allowed || remaining != 0 {
+		t.Fatalf("take from an empty bucket: %v, %d remaining, %v", allowed, remaining, err)
+	}
+	// One token refills every 20s, and the bucket is full after a minute.
+	if retryAfter <= 0 || retryAfter > 20*time.Second || reset <= retryAfter || reset > time.Minute {
+		t.Errorf("retry after %v, reset %v", retryAfter, reset)
+	}
+}
@@ -3,129 +3,604 @@ package repositories
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 
+	"github.com/kodra-pay/transaction-service/internal/apperr"
+	"github.com/kodra-pay/transaction-service/internal/logging"
 	"github.com/kodra-pay/transaction-service/internal/models"
+	"github.com/kodra-pay/transaction-service/internal/pii"
+	"github.com/kodra-pay/transaction-service/internal/tracing"
 )
 
+// ErrDuplicateReference is returned when a transaction is created with a
+// reference that is already taken.
+var ErrDuplicateReference = apperr.New(apperr.CodeDuplicateReference, "transaction reference already exists")
+
+// ErrTransactionDisputed is returned when a refund is refused because a
+// chargeback has already taken the money back.
+var ErrTransactionDisputed = apperr.New(apperr.CodeConflict, "transaction has an open or lost dispute")
+
+// uniqueViolation is Postgres' SQLSTATE for a unique constraint violation.
+const uniqueViolation = "23505"
+
+// TransactionRepository stores customer email and name encrypted with pii,
+// and looks customers up by the email's blind index.
 type TransactionRepository struct {
-	db *sql.DB
+	db  *sql.DB
+	pii *pii.Cipher
+}
+
+func NewTransactionRepository(db *sql.DB, cipher *pii.Cipher) *TransactionRepository {
+	return &TransactionRepository{db: db, pii: cipher}
+}
+
+const transactionColumns = `id, reference, merchant_id, type, customer_email, customer_id, customer_name, amount, currency, status, payment_method, description, risk_score, risk_decision, risk_reasons, livemode, limit_reserved_at, limit_currency, created_at, updated_at`
+
+func (r *TransactionRepository) scan(row interface{ Scan(...interface{}) error }) (*models.Transaction, error) {
+	var tx models.Transaction
+	var limitReservedAt sql.NullTime
+	if err := row.Scan(
+		&tx.ID, &tx.Reference, &tx.MerchantID, &tx.Type, &tx.CustomerEmail, &tx.CustomerID, &tx.CustomerName,
+		&tx.Amount, &tx.Currency, &tx.Status, &tx.PaymentMethod, &tx.Description,
+		&tx.RiskScore, &tx.RiskDecision, pq.Array(&tx.RiskReasons), &tx.Livemode,
+		&limitReservedAt, &tx.LimitCurrency, &tx.CreatedAt, &tx.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	if limitReservedAt.Valid {
+		tx.LimitReservedAt = &limitReservedAt.Time
+	}
+	var err error
+	if tx.CustomerEmail, err = r.pii.Decrypt(tx.CustomerEmail); err != nil {
+		return nil, fmt.Errorf("decrypt customer email of %s: %w", tx.Reference, err)
+	}
+	if tx.CustomerName, err = r.pii.Decrypt(tx.CustomerName); err != nil {
+		return nil, fmt.Errorf("decrypt customer name of %s: %w", tx.Reference, err)
+	}
+	return &tx, nil
+}
+
+// sealCustomer returns the stored forms of the transaction's customer email
+// and name, plus the email's blind index. tx itself keeps the plaintext.
+func (r *TransactionRepository) sealCustomer(tx *models.Transaction) (email, name, index string, err error) {
+	if email, err = r.pii.Encrypt(tx.CustomerEmail); err != nil {
+		return "", "", "", err
+	}
+	if name, err = r.pii.Encrypt(tx.CustomerName); err != nil {
+		return "", "", "", err
+	}
+	return email, name, r.pii.BlindIndex(tx.CustomerEmail), nil
+}
+
+// Create persists the transaction row and the first event of its timeline.
+// Ledger postings are the caller's decision, since blocked or held
+// transactions must never reach the ledger.
+func (r *TransactionRepository) Create(ctx context.Context, tx *models.Transaction, ev *models.TransactionEvent) (err error) {
+	ctx, span := tracing.StartDB(ctx, "transactions.create")
+	defer func() { tracing.End(span, err) }()
+
+	dbTx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer dbTx.Rollback()
+
+	if err := r.insert(ctx, dbTx, tx); err != nil {
+		return err
+	}
+	if err := insertStatusEvent(ctx, dbTx, tx, ev); err != nil {
+		return err
+	}
+	return dbTx.Commit()
 }
 
-func NewTransactionRepository(dsn string) (*TransactionRepository, error) {
-	db, err := sql.Open("postgres", dsn)
+// CreateForReview persists a transaction held by risk screening together with
+// its pending review, so a held transaction is never missing from the queue.
+func (r *TransactionRepository) CreateForReview(ctx context.Context, tx *models.Transaction, ev *models.TransactionEvent) (err error) {
+	ctx, span := tracing.StartDB(ctx, "transactions.create_for_review")
+	defer func() { tracing.End(span, err) }()
+
+	dbTx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
-		return nil, fmt.Errorf("open db: %w", err)
+		return err
 	}
-	if err := db.Ping(); err != nil {
-		return nil, fmt.Errorf("ping db: %w", err)
+	defer dbTx.Rollback()
+
+	if err := r.insert(ctx, dbTx, tx); err != nil {
+		return err
 	}
-	db.SetMaxOpenConns(10)
-	db.SetMaxIdleConns(5)
-	db.SetConnMaxLifetime(5 * time.Minute)
-	return &TransactionRepository{db: db}, nil
+	if err := insertStatusEvent(ctx, dbTx, tx, ev); err != nil {
+		return err
+	}
+	if _, err := dbTx.ExecContext(ctx, `
+		INSERT INTO transaction_reviews (transaction_id, reference, merchant_id, status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, NOW(), NOW())
+	`, tx.ID, tx.Reference, tx.MerchantID, models.ReviewStatusPending); err != nil {
+		return err
+	}
+	return dbTx.Commit()
 }
 
-func (r *TransactionRepository) Create(ctx context.Context, tx *models.Transaction) error {
-	query := `
-		INSERT INTO transactions (reference, merchant_id, customer_email, customer_id, customer_name, amount, currency, status, payment_method, description, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NOW(), NOW())
-		RETURNING id, reference, created_at, updated_at -- Also return reference
-	`
-	if err := r.db.QueryRowContext(ctx, query,
-		tx.Reference, tx.MerchantID, tx.CustomerEmail, tx.CustomerID, tx.CustomerName,
-		tx.Amount, tx.Currency, tx.Status, tx.PaymentMethod, tx.Description,
-	).Scan(&tx.ID, &tx.Reference, &tx.CreatedAt, &tx.UpdatedAt); err != nil { // Scan into reference
+// CreateAdjustment persists the transaction of a proposed ledger adjustment
+// together with the adjustment awaiting approval, filling in adj's ID,
+// TransactionID and timestamps.
+func (r *TransactionRepository) CreateAdjustment(ctx context.Context, tx *models.Transaction, ev *models.TransactionEvent, adj *models.Adjustment) (err error) {
+	ctx, span := tracing.StartDB(ctx, "transactions.create_adjustment")
+	defer func() { tracing.End(span, err) }()
+
+	dbTx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer dbTx.Rollback()
+
+	if err := r.insert(ctx, dbTx, tx); err != nil {
+		return err
+	}
+	if err := insertStatusEvent(ctx, dbTx, tx, ev); err != nil {
 		return err
 	}
+	adj.TransactionID = tx.ID
+	adj.Reference = tx.Reference
+	if err := dbTx.QueryRowContext(ctx, `
+		INSERT INTO ledger_adjustments (
+			transaction_id, reference, merchant_id, direction, amount, currency,
+			reason, status, proposed_by, created_at, updated_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW(), NOW())
+		RETURNING id, created_at, updated_at
+	`, adj.TransactionID, adj.Reference, adj.MerchantID, adj.Direction, adj.Amount, adj.Currency,
+		adj.Reason, adj.Status, adj.ProposedBy,
+	).Scan(&adj.ID, &adj.CreatedAt, &adj.UpdatedAt); err != nil {
+		return err
+	}
+	return dbTx.Commit()
+}
+
+// CreatePayout records a payout together with its ledger debit, provided the
+// merchant's ledger balance in the payout currency covers it, and reports
+// false without recording anything when it does not. Payouts of one merchant
+// are serialised so two cannot spend the same balance. A nil debit records
+// the payout without touching the ledger.
+func (r *TransactionRepository) CreatePayout(ctx context.Context, tx *models.Transaction, ev *models.TransactionEvent, debit *models.LedgerEntry) (_ bool, err error) {
+	ctx, span := tracing.StartDB(ctx, "transactions.create_payout")
+	defer func() { tracing.End(span, err) }()
+
+	dbTx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer dbTx.Rollback()
 
-	// Record ledger credit for this merchant to feed settlement calculations, skip payout rows.
-	if tx.Status != "payout" && tx.PaymentMethod != "payout" {
-		_, err := r.db.ExecContext(ctx, `
-			INSERT INTO wallet_ledger (
-				merchant_id, transaction_id, entry_type, amount, balance_after,
-				currency, description, reference, created_at
-			)
-			VALUES (
-				$1,
-				$2,
-				'credit',
-				$3,
-				(SELECT COALESCE(MAX(balance_after), 0) + $3 FROM wallet_ledger WHERE merchant_id = $1),
-				$4,
-				$5,
-				$6,
-				NOW()
-			)
-		`, tx.MerchantID, tx.ID, tx.Amount, tx.Currency, "Transaction credit", tx.Reference)
+	if debit != nil {
+		if _, err := dbTx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1, $2)`, ledgerLock, tx.MerchantID); err != nil {
+			return false, err
+		}
+		balance, err := ledgerBalance(ctx, dbTx, tx.MerchantID, tx.Currency)
 		if err != nil {
-			// Log the error but don't fail the transaction creation
-			fmt.Printf("failed to record ledger entry: %v\n", err)
+			return false, err
+		}
+		if balance < debit.Amount {
+			return false, nil
 		}
 	}
+	if err := r.insert(ctx, dbTx, tx); err != nil {
+		return false, err
+	}
+	if err := insertStatusEvent(ctx, dbTx, tx, ev); err != nil {
+		return false, err
+	}
+	if debit != nil {
+		debit.TransactionID = tx.ID
+		if err := insertLedgerEntry(ctx, dbTx, debit); err != nil {
+			return false, err
+		}
+	}
+	return true, dbTx.Commit()
+}
 
-	return nil
+// insertStatusEvent records tx's current status on its timeline. A nil event is skipped.
+func insertStatusEvent(ctx context.Context, db execer, tx *models.Transaction, ev *models.TransactionEvent) error {
+	if ev == nil {
+		return nil
+	}
+	ev.TransactionID = tx.ID
+	ev.Reference = tx.Reference
+	ev.ToStatus = tx.Status
+	return insertTransactionEvent(ctx, db, ev)
+}
+
+func (r *TransactionRepository) insert(ctx context.Context, db execer, tx *models.Transaction) error {
+	email, name, index, err := r.sealCustomer(tx)
+	if err != nil {
+		return err
+	}
+	query := `
+		INSERT INTO transactions (reference, merchant_id, type, customer_email, customer_id, customer_name, amount, currency, status, payment_method, description, risk_score, risk_decision, risk_reasons, livemode, limit_reserved_at, limit_currency, customer_email_index, pii_key_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, NOW(), NOW())
+		RETURNING id, reference, created_at, updated_at -- Also return reference
+	`
+	err = db.QueryRowContext(ctx, query,
+		tx.Reference, tx.MerchantID, tx.Type, email, tx.CustomerID, name,
+		tx.Amount, tx.Currency, tx.Status, tx.PaymentMethod, tx.Description,
+		tx.RiskScore, tx.RiskDecision, pq.Array(tx.RiskReasons), tx.Livemode,
+		tx.LimitReservedAt, tx.LimitCurrency, index, r.pii.CurrentKeyID(),
+	).Scan(&tx.ID, &tx.Reference, &tx.CreatedAt, &tx.UpdatedAt) // Scan into reference
+	// reference is the only unique column callers supply.
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+		return fmt.Errorf("%w: %s", ErrDuplicateReference, tx.Reference)
+	}
+	return err
 }
 
-func (r *TransactionRepository) GetByReference(ctx context.Context, reference string) (*models.Transaction, error) {
+func (r *TransactionRepository) GetByReference(ctx context.Context, reference string) (_ *models.Transaction, err error) {
+	ctx, span := tracing.StartDB(ctx, "transactions.get_by_reference")
+	defer func() { tracing.End(span, err) }()
+
 	query := `
-		SELECT id, reference, merchant_id, customer_email, customer_id, customer_name, amount, currency, status, payment_method, description, created_at, updated_at
+		SELECT ` + transactionColumns + `
 		FROM transactions
 		WHERE reference = $1
 	`
-	var tx models.Transaction
-	err := r.db.QueryRowContext(ctx, query, reference).Scan(
-		&tx.ID, &tx.Reference, &tx.MerchantID, &tx.CustomerEmail, &tx.CustomerID, &tx.CustomerName,
-		&tx.Amount, &tx.Currency, &tx.Status, &tx.PaymentMethod, &tx.Description,
-		&tx.CreatedAt, &tx.UpdatedAt,
-	)
+	tx, err := r.scan(r.db.QueryRowContext(ctx, query, reference))
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
-	return &tx, err
+	return tx, err
 }
 
-func (r *TransactionRepository) ListByMerchant(ctx context.Context, merchantID int, limit int) ([]*models.Transaction, error) {
+func (r *TransactionRepository) GetByID(ctx context.Context, id int) (_ *models.Transaction, err error) {
+	ctx, span := tracing.StartDB(ctx, "transactions.get_by_id")
+	defer func() { tracing.End(span, err) }()
+
+	tx, err := r.scan(r.db.QueryRowContext(ctx, `SELECT `+transactionColumns+` FROM transactions WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return tx, err
+}
+
+// ListByMerchant returns the merchant's transactions, optionally narrowed to
+// a status ("" for all) and to live or test mode (nil for both).
+func (r *TransactionRepository) ListByMerchant(ctx context.Context, merchantID int, status string, livemode *bool, limit int) (_ []*models.Transaction, err error) {
+	ctx, span := tracing.StartDB(ctx, "transactions.list_by_merchant")
+	defer func() { tracing.End(span, err) }()
+
 	query := `
-		SELECT id, reference, merchant_id, customer_email, customer_id, customer_name, amount, currency, status, payment_method, description, created_at, updated_at
+		SELECT ` + transactionColumns + `
 		FROM transactions
 		WHERE merchant_id = $1
+		  AND ($2 = '' OR status = $2)
+		  AND ($3::boolean IS NULL OR livemode = $3)
+		ORDER BY created_at DESC
+		LIMIT $4
+	`
+	return r.list(ctx, query, merchantID, status, livemode, limit)
+}
+
+func (r *TransactionRepository) ListByStatus(ctx context.Context, status string, limit int) (_ []*models.Transaction, err error) {
+	ctx, span := tracing.StartDB(ctx, "transactions.list_by_status")
+	defer func() { tracing.End(span, err) }()
+
+	query := `
+		SELECT ` + transactionColumns + `
+		FROM transactions
+		WHERE status = $1
 		ORDER BY created_at DESC
 		LIMIT $2
 	`
-	rows, err := r.db.QueryContext(ctx, query, merchantID, limit)
+	return r.list(ctx, query, status, limit)
+}
+
+// UpdateStatus moves the transaction to tx.Status if its current status is one
+// of from, recording the transition on its timeline. It returns false if the
+// transaction was in some other state.
+func (r *TransactionRepository) UpdateStatus(ctx context.Context, tx *models.Transaction, ev *models.TransactionEvent, from ...string) (_ bool, err error) {
+	ctx, span := tracing.StartDB(ctx, "transactions.update_status")
+	defer func() { tracing.End(span, err) }()
+
+	dbTx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
-		return nil, err
+		return false, err
 	}
-	defer rows.Close()
+	defer dbTx.Rollback()
 
-	var list []*models.Transaction
+	err = dbTx.QueryRowContext(ctx, `
+		UPDATE transactions SET status = $1, updated_at = NOW()
+		WHERE id = $2 AND status = ANY($3)
+		RETURNING updated_at
+	`, tx.Status, tx.ID, pq.Array(from)).Scan(&tx.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if err := insertStatusEvent(ctx, dbTx, tx, ev); err != nil {
+		return false, err
+	}
+	return true, dbTx.Commit()
+}
+
+// Refund is UpdateStatus for a move to refunded. It fails with
+// ErrTransactionDisputed, changing nothing, if the transaction has an open or
+// lost dispute. The status update locks the transaction row, which disputes
+// also lock before they are opened, so a refund and a chargeback cannot both
+// take the money back.
+func (r *TransactionRepository) Refund(ctx context.Context, tx *models.Transaction, ev *models.TransactionEvent, from ...string) (_ bool, err error) {
+	ctx, span := tracing.StartDB(ctx, "transactions.refund")
+	defer func() { tracing.End(span, err) }()
+
+	dbTx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer dbTx.Rollback()
+
+	err = dbTx.QueryRowContext(ctx, `
+		UPDATE transactions SET status = $1, updated_at = NOW()
+		WHERE id = $2 AND status = ANY($3)
+		RETURNING updated_at
+	`, tx.Status, tx.ID, pq.Array(from)).Scan(&tx.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	var disputed bool
+	if err := dbTx.QueryRowContext(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM disputes
+			WHERE transaction_id = $1 AND status IN ($2, $3, $4)
+		)
+	`, tx.ID, models.DisputeStatusNeedsResponse, models.DisputeStatusUnderReview, models.DisputeStatusLost).Scan(&disputed); err != nil {
+		return false, err
+	}
+	if disputed {
+		return false, fmt.Errorf("%w: %s", ErrTransactionDisputed, tx.Reference)
+	}
+	if err := insertStatusEvent(ctx, dbTx, tx, ev); err != nil {
+		return false, err
+	}
+	return true, dbTx.Commit()
+}
+
+// UpdateDetails saves the descriptive fields an operator may correct. Amounts,
+// currency and status are deliberately not editable.
+func (r *TransactionRepository) UpdateDetails(ctx context.Context, tx *models.Transaction) (err error) {
+	ctx, span := tracing.StartDB(ctx, "transactions.update_details")
+	defer func() { tracing.End(span, err) }()
+
+	email, name, index, err := r.sealCustomer(tx)
+	if err != nil {
+		return err
+	}
+	return r.db.QueryRowContext(ctx, `
+		UPDATE transactions
+		SET description = $1, customer_email = $2, customer_name = $3, customer_id = $4,
+		    customer_email_index = $5, pii_key_id = $6, updated_at = NOW()
+		WHERE id = $7
+		RETURNING updated_at
+	`, tx.Description, email, name, tx.CustomerID, index, r.pii.CurrentKeyID(), tx.ID).Scan(&tx.UpdatedAt)
+}
+
+// ReencryptPII moves up to batch rows whose customer fields are not yet under
+// the current key onto it: legacy plaintext is encrypted, older data keys are
+// re-wrapped and the blind index is recomputed. It returns the number of rows
+// updated; zero means the table is done. Without encryption configured it
+// only backfills the blind index.
+func (r *TransactionRepository) ReencryptPII(ctx context.Context, batch int) (_ int, err error) {
+	ctx, span := tracing.StartDB(ctx, "transactions.reencrypt_pii")
+	defer func() { tracing.End(span, err) }()
+
+	current := r.pii.CurrentKeyID()
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, reference, COALESCE(customer_email, ''), COALESCE(customer_name, '')
+		FROM transactions
+		WHERE pii_key_id IS DISTINCT FROM $1
+		ORDER BY id
+		LIMIT $2
+	`, current, batch)
+	if err != nil {
+		return 0, err
+	}
+	type pending struct {
+		id                     int
+		reference, email, name string
+	}
+	var work []pending
 	for rows.Next() {
-		var tx models.Transaction
-		if err := rows.Scan(
-			&tx.ID, &tx.Reference, &tx.MerchantID, &tx.CustomerEmail, &tx.CustomerID, &tx.CustomerName,
-			&tx.Amount, &tx.Currency, &tx.Status, &tx.PaymentMethod, &tx.Description,
-			&tx.CreatedAt, &tx.UpdatedAt,
-		); err != nil {
-			return nil, err
+		var p pending
+		if err := rows.Scan(&p.id, &p.reference, &p.email, &p.name); err != nil {
+			rows.Close()
+			return 0, err
 		}
-		list = append(list, &tx)
+		work = append(work, p)
 	}
-	return list, rows.Err()
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, p := range work {
+		plainEmail, err := r.pii.Decrypt(p.email)
+		if err != nil {
+			return 0, fmt.Errorf("decrypt customer email of %s: %w", p.reference, err)
+		}
+		email, _, err := r.pii.Rewrap(p.email)
+		if err != nil {
+			return 0, err
+		}
+		name, _, err := r.pii.Rewrap(p.name)
+		if err != nil {
+			return 0, err
+		}
+		// Only rows still on the old key are touched, so a concurrent edit wins.
+		if _, err := r.db.ExecContext(ctx, `
+			UPDATE transactions
+			SET customer_email = $1, customer_name = $2, customer_email_index = $3, pii_key_id = $4
+			WHERE id = $5 AND pii_key_id IS DISTINCT FROM $4
+		`, email, name, r.pii.BlindIndex(plainEmail), current, p.id); err != nil {
+			return 0, err
+		}
+	}
+	logging.FromContext(ctx).DebugContext(ctx, "re-encrypted customer PII batch", "rows", len(work), "key_id", current)
+	return len(work), nil
+}
+
+// CountRecentByCustomer counts the merchant's transactions in the given mode
+// created since the given time by the customer, matched on customer ID or the
+// blind index of their email.
+func (r *TransactionRepository) CountRecentByCustomer(ctx context.Context, merchantID int, livemode bool, customerID int, email string, since time.Time) (_ int, err error) {
+	ctx, span := tracing.StartDB(ctx, "transactions.count_recent_by_customer")
+	defer func() { tracing.End(span, err) }()
+
+	var count int
+	err = r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*)
+		FROM transactions
+		WHERE merchant_id = $1
+		  AND livemode = $2
+		  AND created_at >= $3
+		  AND (($4 <> 0 AND customer_id = $4) OR ($5 <> '' AND customer_email_index = $5))
+	`, merchantID, livemode, since, customerID, r.pii.BlindIndex(email)).Scan(&count)
+	return count, err
 }
 
-func (r *TransactionRepository) ListByStatus(ctx context.Context, status string, limit int) ([]*models.Transaction, error) {
+// customerMatch selects a customer's transactions by customer ID ($1), email
+// blind index ($2) or, for rows not yet re-encrypted, plaintext email ($3).
+const customerMatch = `(($1 <> 0 AND customer_id = $1)
+	OR ($2 <> '' AND customer_email_index = $2)
+	OR ($3 <> '' AND customer_email_index IS NULL AND lower(customer_email) = lower($3)))`
+
+// ListByCustomer returns every transaction of a customer, oldest first, for
+// data subject exports.
+func (r *TransactionRepository) ListByCustomer(ctx context.Context, customerID int, email string) (_ []*models.Transaction, err error) {
+	ctx, span := tracing.StartDB(ctx, "transactions.list_by_customer")
+	defer func() { tracing.End(span, err) }()
+
 	query := `
-		SELECT id, reference, merchant_id, customer_email, customer_id, customer_name, amount, currency, status, payment_method, description, created_at, updated_at
+		SELECT ` + transactionColumns + `
 		FROM transactions
-		WHERE status = $1
-		ORDER BY created_at DESC
-		LIMIT $2
+		WHERE ` + customerMatch + `
+		ORDER BY created_at, id
 	`
-	rows, err := r.db.QueryContext(ctx, query, status, limit)
+	return r.list(ctx, query, customerID, r.pii.BlindIndex(email), email)
+}
+
+// CountByCustomer counts the transactions still linked to a customer by ID or
+// email blind index.
+func (r *TransactionRepository) CountByCustomer(ctx context.Context, customerID int, emailIndex string) (_ int, err error) {
+	ctx, span := tracing.StartDB(ctx, "transactions.count_by_customer")
+	defer func() { tracing.End(span, err) }()
+
+	var count int
+	err = r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM transactions WHERE `+customerMatch,
+		customerID, emailIndex, "").Scan(&count)
+	return count, err
+}
+
+// Pseudonymise replaces the customer PII of every matching transaction with
+// pseudonym, and scrubs any copy of it from the transactions' wallet_ledger
+// descriptions. Amounts, references and ledger balances are untouched; the
+// audit log needs no scrubbing, since its snapshots hold no PII. It returns
+// the number of transactions and ledger entries changed.
+func (r *TransactionRepository) Pseudonymise(ctx context.Context, customerID int, email, pseudonym string) (_ int, _ int, err error) {
+	ctx, span := tracing.StartDB(ctx, "transactions.pseudonymise")
+	defer func() { tracing.End(span, err) }()
+
+	dbTx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer dbTx.Rollback()
+
+	index := r.pii.BlindIndex(email)
+	rows, err := dbTx.QueryContext(ctx, `
+		SELECT id, reference, COALESCE(customer_email, ''), COALESCE(customer_name, '')
+		FROM transactions
+		WHERE `+customerMatch+`
+		FOR UPDATE
+	`, customerID, index, email)
+	if err != nil {
+		return 0, 0, err
+	}
+	var ids []int64
+	identifiers := map[string]bool{}
+	for rows.Next() {
+		var id int64
+		var reference, storedEmail, storedName string
+		if err := rows.Scan(&id, &reference, &storedEmail, &storedName); err != nil {
+			rows.Close()
+			return 0, 0, err
+		}
+		ids = append(ids, id)
+		for _, v := range []string{storedEmail, storedName} {
+			plain, err := r.pii.Decrypt(v)
+			if err != nil {
+				rows.Close()
+				return 0, 0, fmt.Errorf("decrypt customer PII of %s: %w", reference, err)
+			}
+			if plain != "" {
+				identifiers[plain] = true
+			}
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, 0, err
+	}
+	if len(ids) == 0 {
+		return 0, 0, dbTx.Commit()
+	}
+	if email != "" {
+		identifiers[email] = true
+	}
+
+	// The pseudonym is sealed like any customer email, so the row's key ID
+	// stays true and key rotation still rewraps it.
+	sealed, err := r.pii.Encrypt(pseudonym)
+	if err != nil {
+		return 0, 0, err
+	}
+	if _, err := dbTx.ExecContext(ctx, `
+		UPDATE transactions
+		SET customer_email = $1, customer_name = '', customer_id = 0,
+		    customer_email_index = $2, pii_key_id = $3, updated_at = NOW()
+		WHERE id = ANY($4)
+	`, sealed, r.pii.BlindIndex(pseudonym), r.pii.CurrentKeyID(), pq.Array(ids)); err != nil {
+		return 0, 0, err
+	}
+
+	ledgerEntries := 0
+	for identifier := range identifiers {
+		res, err := dbTx.ExecContext(ctx, `
+			UPDATE wallet_ledger
+			SET description = replace(description, $1, $2)
+			WHERE transaction_id = ANY($3) AND strpos(description, $1) > 0
+		`, identifier, pseudonym, pq.Array(ids))
+		if err != nil {
+			return 0, 0, err
+		}
+		n, _ := res.RowsAffected()
+		ledgerEntries += int(n)
+	}
+	if err := dbTx.Commit(); err != nil {
+		return 0, 0, err
+	}
+	logging.FromContext(ctx).DebugContext(ctx, "pseudonymised customer transactions",
+		"customer_id", customerID, "transactions", len(ids), "ledger_entries", ledgerEntries)
+	return len(ids), ledgerEntries, nil
+}
+
+func (r *TransactionRepository) list(ctx context.Context, query string, args ...interface{}) ([]*models.Transaction, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -133,15 +608,11 @@ func (r *TransactionRepository) ListByStatus(ctx context.Context, status string,
 
 	var list []*models.Transaction
 	for rows.Next() {
-		var tx models.Transaction
-		if err := rows.Scan(
-			&tx.ID, &tx.Reference, &tx.MerchantID, &tx.CustomerEmail, &tx.CustomerID, &tx.CustomerName,
-			&tx.Amount, &tx.Currency, &tx.Status, &tx.PaymentMethod, &tx.Description,
-			&tx.CreatedAt, &tx.UpdatedAt,
-		); err != nil {
+		tx, err := r.scan(rows)
+		if err != nil {
 			return nil, err
 		}
-		list = append(list, &tx)
+		list = append(list, tx)
 	}
 	return list, rows.Err()
 }
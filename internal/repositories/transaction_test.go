@@ -0,0 +1,174 @@
+package repositories
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kodra-pay/transaction-service/internal/models"
+	"github.com/kodra-pay/transaction-service/internal/pii"
+)
+
+// setTestCustomer sets a transaction's customer as a repository without PII
+// encryption would store it.
+func setTestCustomer(t *testing.T, db *sql.DB, id, customerID int, email string) {
+	t.Helper()
+	var plain *pii.Cipher
+	if _, err := db.ExecContext(context.Background(), `
+		UPDATE transactions SET customer_id = $2, customer_email = $3, customer_email_index = $4 WHERE id = $1
+	`, id, customerID, email, plain.BlindIndex(email)); err != nil {
+		t.Fatalf("set customer: %v", err)
+	}
+}
+
+func TestCountRecentByCustomerIsPerMerchantAndMode(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+	repo := NewTransactionRepository(db, nil)
+	merchantID, other := testMerchantID(), testMerchantID()
+	customerID := testMerchantID()
+	since := time.Now().Add(-time.Minute)
+
+	rows := []struct {
+		merchantID int
+		livemode   bool
+	}{{merchantID, true}, {merchantID, true}, {merchantID, false}, {other, true}}
+	for _, row := range rows {
+		tx := insertTestTransaction(t, db, row.merchantID, 100, "NGN", "success")
+		setTestCustomer(t, db, tx.ID, customerID, "velocity@example.com")
+		if _, err := db.ExecContext(ctx, `UPDATE transactions SET livemode = $2 WHERE id = $1`, tx.ID, row.livemode); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if n, err := repo.CountRecentByCustomer(ctx, merchantID, true, customerID, "", since); n != 2 || err != nil {
+		t.Errorf("live by customer ID: %d, %v, want 2", n, err)
+	}
+	if n, err := repo.CountRecentByCustomer(ctx, merchantID, false, 0, "velocity@example.com", since); n != 1 || err != nil {
+		t.Errorf("test mode by email: %d, %v, want 1", n, err)
+	}
+	if n, err := repo.CountRecentByCustomer(ctx, other, true, customerID, "", since); n != 1 || err != nil {
+		t.Errorf("other merchant: %d, %v, want 1", n, err)
+	}
+}
+
+func TestLimitReservationRoundTrip(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+	repo := NewTransactionRepository(db, nil)
+	merchantID := testMerchantID()
+	reservedAt := time.Date(2026, 3, 31, 23, 59, 59, 999_999_000, time.UTC)
+
+	counted := &models.Transaction{Reference: fmt.Sprintf("counted-%d", merchantID), MerchantID: merchantID, Amount: 100, Currency: "ngn", Status: "success",
+		LimitReservedAt: &reservedAt, LimitCurrency: "NGN"}
+	uncounted := &models.Transaction{Reference: fmt.Sprintf("uncounted-%d", merchantID), MerchantID: merchantID, Amount: 100, Currency: "NGN", Status: "blocked"}
+	for _, tx := range []*models.Transaction{counted, uncounted} {
+		if err := repo.Create(ctx, tx, nil); err != nil {
+			t.Fatalf("create %s: %v", tx.Reference, err)
+		}
+	}
+
+	got, err := repo.GetByID(ctx, counted.ID)
+	if err != nil || got.LimitReservedAt == nil || !got.LimitReservedAt.Equal(reservedAt) || got.LimitCurrency != "NGN" {
+		t.Errorf("counted transaction read back as %v %q, %v", got.LimitReservedAt, got.LimitCurrency, err)
+	}
+	if got, err := repo.GetByID(ctx, uncounted.ID); err != nil || got.LimitReservedAt != nil || got.LimitCurrency != "" {
+		t.Errorf("uncounted transaction read back as %v %q, %v", got.LimitReservedAt, got.LimitCurrency, err)
+	}
+}
+
+// testCipher returns a cipher sealing under a single key with ID "test".
+func testCipher(t *testing.T) *pii.Cipher {
+	t.Helper()
+	keyFile := filepath.Join(t.TempDir(), "keys.json")
+	key := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{7}, 32))
+	if err := os.WriteFile(keyFile, []byte(`{"current": "test", "keys": {"test": "`+key+`"}, "index_key": "`+key+`"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	keys, err := pii.LoadKeyFile(keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pii.NewCipher(keys)
+}
+
+func TestCustomerPIIIsStoredEncrypted(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+	repo := NewTransactionRepository(db, testCipher(t))
+	merchantID := testMerchantID()
+
+	tx := &models.Transaction{Reference: fmt.Sprintf("pii-%d", merchantID), MerchantID: merchantID, Amount: 100, Currency: "NGN", Status: "pending",
+		CustomerEmail: "Ada@Example.com", CustomerName: "Ada Lovelace", Livemode: true}
+	if err := repo.Create(ctx, tx, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	var email, name, keyID string
+	if err := db.QueryRowContext(ctx, `SELECT customer_email, customer_name, pii_key_id FROM transactions WHERE id = $1`, tx.ID).Scan(&email, &name, &keyID); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(email, "Ada") || strings.Contains(name, "Ada") || keyID != "test" {
+		t.Errorf("stored customer %q, %q under key %q", email, name, keyID)
+	}
+	if got, err := repo.GetByID(ctx, tx.ID); err != nil || got.CustomerEmail != "Ada@Example.com" || got.CustomerName != "Ada Lovelace" {
+		t.Errorf("read back %+v, %v", got, err)
+	}
+	if n, err := repo.CountRecentByCustomer(ctx, merchantID, true, 0, "ada@example.com", time.Now().Add(-time.Minute)); n != 1 || err != nil {
+		t.Errorf("lookup by blind index: %d, %v, want 1", n, err)
+	}
+}
+
+func TestPayoutsCannotOverspend(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+	merchantID := testMerchantID()
+	sale := insertTestTransaction(t, db, merchantID, 1000, "NGN", "success")
+	if err := NewLedgerRepository(db).Record(ctx, &models.LedgerEntry{
+		MerchantID: merchantID, TransactionID: sale.ID, EntryType: models.LedgerEntryCredit,
+		Amount: 1000, Currency: "NGN", Reference: sale.Reference,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	repo := NewTransactionRepository(db, nil)
+	var wg sync.WaitGroup
+	paid := make(chan bool, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			tx := &models.Transaction{
+				Reference: fmt.Sprintf("payout-%d-%d", merchantID, i), MerchantID: merchantID, Type: models.TransactionTypePayout,
+				Amount: 300, Currency: "NGN", Status: models.TransactionStatusPending, Livemode: true,
+			}
+			debit := &models.LedgerEntry{MerchantID: merchantID, EntryType: models.LedgerEntryDebit, Amount: 300, Currency: "NGN", Reference: tx.Reference}
+			ok, err := repo.CreatePayout(ctx, tx, nil, debit)
+			if err != nil {
+				t.Error(err)
+			}
+			paid <- ok
+		}(i)
+	}
+	wg.Wait()
+	close(paid)
+
+	n := 0
+	for ok := range paid {
+		if ok {
+			n++
+		}
+	}
+	if n != 3 {
+		t.Errorf("%d payouts of 300 recorded against a balance of 1000, want 3", n)
+	}
+	checkRunningBalances(t, db, merchantID)
+}
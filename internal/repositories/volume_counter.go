@@ -0,0 +1,99 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Results of a volume reservation.
+const (
+	VolumeReserved        = 0
+	VolumeDailyExceeded   = 1
+	VolumeMonthlyExceeded = 2
+)
+
+// reserveScript adds an amount to the daily and monthly counters only if
+// neither would exceed its limit (0 = no limit). It returns the outcome code
+// and the daily and monthly totals before the reservation.
+var reserveScript = redis.NewScript(`
+local amount = tonumber(ARGV[1])
+local dailyLimit = tonumber(ARGV[2])
+local monthlyLimit = tonumber(ARGV[3])
+local daily = tonumber(redis.call('GET', KEYS[1]) or '0')
+local monthly = tonumber(redis.call('GET', KEYS[2]) or '0')
+if dailyLimit > 0 and daily + amount > dailyLimit then
+  return {1, daily, monthly}
+end
+if monthlyLimit > 0 and monthly + amount > monthlyLimit then
+  return {2, daily, monthly}
+end
+redis.call('INCRBY', KEYS[1], amount)
+redis.call('EXPIRE', KEYS[1], ARGV[4])
+redis.call('INCRBY', KEYS[2], amount)
+redis.call('EXPIRE', KEYS[2], ARGV[5])
+return {0, daily, monthly}
+`)
+
+// VolumeCounter tracks daily and monthly collected volume per merchant and
+// currency in Redis, so limits are enforced atomically across instances.
+type VolumeCounter struct {
+	client *redis.Client
+}
+
+func NewVolumeCounter(client *redis.Client) *VolumeCounter {
+	return &VolumeCounter{client: client}
+}
+
+// Reserve atomically adds amount to the current day and month if both stay
+// within their limits. It returns one of the Volume* codes and the totals
+// that were in place before the call.
+func (c *VolumeCounter) Reserve(ctx context.Context, merchantID int, currency string, amount, dailyLimit, monthlyLimit int64, at time.Time) (int, int64, int64, error) {
+	dailyKey, monthlyKey := volumeKeys(merchantID, currency, at)
+	res, err := reserveScript.Run(ctx, c.client, []string{dailyKey, monthlyKey},
+		amount, dailyLimit, monthlyLimit,
+		int((48 * time.Hour).Seconds()), int((62 * 24 * time.Hour).Seconds()),
+	).Int64Slice()
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("reserve volume for merchant %d: %w", merchantID, err)
+	}
+	if len(res) != 3 {
+		return 0, 0, 0, fmt.Errorf("reserve volume for merchant %d: unexpected reply %v", merchantID, res)
+	}
+	return int(res[0]), res[1], res[2], nil
+}
+
+// Release gives back a reservation for a transaction that was not completed.
+func (c *VolumeCounter) Release(ctx context.Context, merchantID int, currency string, amount int64, at time.Time) error {
+	dailyKey, monthlyKey := volumeKeys(merchantID, currency, at)
+	pipe := c.client.Pipeline()
+	pipe.DecrBy(ctx, dailyKey, amount)
+	pipe.DecrBy(ctx, monthlyKey, amount)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// Usage returns the volume collected so far today and this month.
+func (c *VolumeCounter) Usage(ctx context.Context, merchantID int, currency string, at time.Time) (int64, int64, error) {
+	dailyKey, monthlyKey := volumeKeys(merchantID, currency, at)
+	values, err := c.client.MGet(ctx, dailyKey, monthlyKey).Result()
+	if err != nil {
+		return 0, 0, err
+	}
+	var totals [2]int64
+	for i, v := range values {
+		if s, ok := v.(string); ok {
+			totals[i], _ = strconv.ParseInt(s, 10, 64)
+		}
+	}
+	return totals[0], totals[1], nil
+}
+
+func volumeKeys(merchantID int, currency string, at time.Time) (string, string) {
+	at = at.UTC()
+	prefix := fmt.Sprintf("limits:volume:%d:%s:", merchantID, currency)
+	return prefix + "day:" + at.Format("20060102"), prefix + "month:" + at.Format("200601")
+}
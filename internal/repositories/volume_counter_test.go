@@ -0,0 +1,58 @@
+package repositories
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// openTestRedis connects to the Redis at TEST_REDIS_ADDR and skips the test
+// when it is unset.
+func openTestRedis(t *testing.T) *redis.Client {
+	t.Helper()
+	addr := os.Getenv("TEST_REDIS_ADDR")
+	if addr == "" {
+		t.Skip("TEST_REDIS_ADDR is not set")
+	}
+	client := redis.NewClient(&redis.Options{Addr: addr})
+	if err := client.Ping(context.Background()).Err(); err != nil {
+		t.Fatalf("ping test redis: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func TestVolumeCounterReserveAndRelease(t *testing.T) {
+	counter := NewVolumeCounter(openTestRedis(t))
+	ctx := context.Background()
+	merchantID := testMerchantID()
+	// The last moment of a month, so the day and month both roll over next.
+	at := time.Date(2026, 1, 31, 23, 59, 59, 999_999_000, time.UTC)
+
+	if code, _, _, err := counter.Reserve(ctx, merchantID, "NGN", 600, 1000, 1500, at); code != VolumeReserved || err != nil {
+		t.Fatalf("first reservation: %d, %v", code, err)
+	}
+	if code, daily, _, err := counter.Reserve(ctx, merchantID, "NGN", 500, 1000, 1500, at); code != VolumeDailyExceeded || daily != 600 || err != nil {
+		t.Errorf("over the daily limit: %d with %d used, %v", code, daily, err)
+	}
+	next := at.Add(time.Microsecond)
+	if code, _, _, err := counter.Reserve(ctx, merchantID, "NGN", 900, 1000, 1500, next); code != VolumeReserved || err != nil {
+		t.Errorf("next day and month: %d, %v", code, err)
+	}
+	if code, _, _, err := counter.Reserve(ctx, merchantID, "USD", 1000, 1000, 1500, at); code != VolumeReserved || err != nil {
+		t.Errorf("other currency: %d, %v", code, err)
+	}
+
+	if err := counter.Release(ctx, merchantID, "NGN", 600, at); err != nil {
+		t.Fatal(err)
+	}
+	if daily, monthly, err := counter.Usage(ctx, merchantID, "NGN", at); daily != 0 || monthly != 0 || err != nil {
+		t.Errorf("after release: %d daily, %d monthly, %v, want 0", daily, monthly, err)
+	}
+	if daily, monthly, err := counter.Usage(ctx, merchantID, "NGN", next); daily != 900 || monthly != 900 || err != nil {
+		t.Errorf("next day: %d daily, %d monthly, %v, want 900", daily, monthly, err)
+	}
+}
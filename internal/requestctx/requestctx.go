@@ -0,0 +1,17 @@
+// Package requestctx carries per-request values, such as the request ID,
+// from the HTTP layer down to services without threading extra parameters.
+package requestctx
+
+import "context"
+
+type requestIDKey struct{}
+
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the ID stored by WithRequestID, or "".
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
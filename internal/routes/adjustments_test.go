@@ -0,0 +1,151 @@
+package routes_test
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/kodra-pay/transaction-service/internal/auth"
+	"github.com/kodra-pay/transaction-service/internal/dto"
+	"github.com/kodra-pay/transaction-service/internal/models"
+)
+
+func TestAdjustmentNeedsSecondOperator(t *testing.T) {
+	h := newHarness(t)
+	alice := h.staffToken("alice", auth.RoleFinance)
+	bob := h.staffToken("bob", auth.RoleFinance)
+
+	var proposed dto.AdjustmentResponse
+	credit := dto.AdjustmentCreateRequest{Reference: "adj-1", MerchantID: 42, Direction: "credit", Amount: 25, Currency: "NGN", Reason: "Goodwill credit"}
+	if code := h.do(http.MethodPost, "/adjustments", alice, credit, &proposed); code != http.StatusCreated {
+		t.Fatalf("propose: status %d", code)
+	}
+	if proposed.Status != models.AdjustmentStatusPending || proposed.ProposedBy != "alice" ||
+		proposed.Transaction.Type != models.TransactionTypeAdjustment || proposed.Transaction.Status != models.TransactionStatusPending {
+		t.Errorf("proposed %+v, want a pending adjustment by alice", proposed)
+	}
+	if entries := h.ledgerEntries(42); len(entries) != 0 {
+		t.Errorf("proposal posted %d ledger entries before approval", len(entries))
+	}
+
+	approve := fmt.Sprintf("/adjustments/%d/approve", proposed.ID)
+	if code, e := h.fail(http.MethodPost, approve, alice, nil); code != http.StatusForbidden || e.Code != "forbidden" {
+		t.Errorf("self-approval: got %d %s, want 403 forbidden", code, e.Code)
+	}
+	// Service tokens may propose but are not people, so they cannot approve.
+	if code, _ := h.fail(http.MethodPost, approve, internalToken, nil); code != http.StatusForbidden {
+		t.Errorf("approval with a service token: status %d, want 403", code)
+	}
+	// Processors have no say over adjustments.
+	if code, _ := h.fail(http.MethodPost, "/internal/transactions/adj-1/events", internalToken, dto.TransactionEventRequest{Status: models.TransactionStatusSuccess, Source: models.EventSourceWebhook}); code != http.StatusConflict {
+		t.Errorf("processor event on an adjustment: status %d, want 409", code)
+	}
+
+	var approved dto.AdjustmentResponse
+	if code := h.do(http.MethodPost, approve, bob, dto.AdjustmentDecisionRequest{Notes: "checked ticket"}, &approved); code != http.StatusOK {
+		t.Fatalf("approve: status %d", code)
+	}
+	if approved.Status != models.AdjustmentStatusApproved || approved.DecidedBy != "bob" || approved.DecisionNotes != "checked ticket" ||
+		approved.Transaction.Status != models.TransactionStatusSuccess {
+		t.Errorf("approved %+v, want approved by bob with a successful transaction", approved)
+	}
+	entries := h.ledgerEntries(42)
+	if len(entries) != 1 {
+		t.Fatalf("got %d ledger entries, want the adjustment credit", len(entries))
+	}
+	if e := entries[0]; e.EntryType != models.LedgerEntryCredit || e.Amount != 2500 || e.BalanceAfter != 2500 || e.TransactionID != approved.Transaction.ID {
+		t.Errorf("approval posted %+v, want a 2500 credit", e)
+	}
+	if settled := h.waitForSettlements(1); settled[0].Amount != 2500 || settled[0].MerchantID != 42 {
+		t.Errorf("approval published %+v, want 2500 for merchant 42", settled[0])
+	}
+	if code, e := h.fail(http.MethodPost, approve, bob, nil); code != http.StatusConflict || e.Code != "invalid_state_transition" {
+		t.Errorf("second approval: got %d %s, want 409 invalid_state_transition", code, e.Code)
+	}
+
+	// A debit proposed by one operator and approved by the other is posted too.
+	var debit dto.AdjustmentResponse
+	if code := h.do(http.MethodPost, "/adjustments", bob, dto.AdjustmentCreateRequest{Reference: "adj-2", MerchantID: 42, Direction: "debit", Amount: 5, Currency: "NGN", Reason: "Fee correction"}, &debit); code != http.StatusCreated {
+		t.Fatalf("propose debit: status %d", code)
+	}
+	if code := h.do(http.MethodPost, fmt.Sprintf("/adjustments/%d/approve", debit.ID), alice, nil, nil); code != http.StatusOK {
+		t.Fatalf("approve debit: status %d", code)
+	}
+	entries = h.ledgerEntries(42)
+	if e := entries[len(entries)-1]; e.EntryType != models.LedgerEntryDebit || e.Amount != 500 || e.BalanceAfter != 2000 {
+		t.Errorf("debit posted %+v, want a 500 debit leaving 2000", e)
+	}
+	if settled := h.waitForSettlements(2); settled[1].Amount != -500 {
+		t.Errorf("debit published %+v, want -500", settled[1])
+	}
+
+	var history dto.TransactionHistoryResponse
+	if code := h.do(http.MethodGet, "/transactions/adj-1/history", internalToken, nil, &history); code != http.StatusOK {
+		t.Fatalf("history: status %d", code)
+	}
+	if !history.Verified || len(history.Entries) != 2 {
+		t.Fatalf("history %+v, want two verified entries", history)
+	}
+	for i, want := range []struct{ action, actor string }{
+		{models.AuditActionAdjustmentPropose, "alice"},
+		{models.AuditActionAdjustmentApprove, "bob"},
+	} {
+		if got := history.Entries[i]; got.Action != want.action || got.Actor != want.actor {
+			t.Errorf("history entry %d is %s by %s, want %s by %s", i, got.Action, got.Actor, want.action, want.actor)
+		}
+	}
+}
+
+func TestAdjustmentRejection(t *testing.T) {
+	h := newHarness(t)
+	alice := h.staffToken("alice", auth.RoleFinance)
+	support := h.staffToken("sam", auth.RoleSupport)
+	key := h.apiKey(42, "live")
+
+	req := dto.AdjustmentCreateRequest{Reference: "adj-1", MerchantID: 42, Direction: "debit", Amount: 10, Currency: "NGN", Reason: "Fee correction"}
+	if code, _ := h.fail(http.MethodPost, "/adjustments", support, req); code != http.StatusForbidden {
+		t.Errorf("proposal by support: status %d, want 403", code)
+	}
+	if code, _ := h.fail(http.MethodPost, "/adjustments", key, req); code != http.StatusForbidden {
+		t.Errorf("proposal with a merchant key: status %d, want 403", code)
+	}
+	bad := req
+	bad.Direction = "sideways"
+	if code, e := h.fail(http.MethodPost, "/adjustments", internalToken, bad); code != http.StatusBadRequest || len(e.Fields) != 1 || e.Fields[0].Field != "direction" {
+		t.Errorf("invalid direction: got %d %+v, want 400 on direction", code, e)
+	}
+
+	var proposed dto.AdjustmentResponse
+	if code := h.do(http.MethodPost, "/adjustments", internalToken, req, &proposed); code != http.StatusCreated {
+		t.Fatalf("propose: status %d", code)
+	}
+	if !strings.HasPrefix(proposed.ProposedBy, "internal:") {
+		t.Errorf("proposed by %q, want the service token's subject", proposed.ProposedBy)
+	}
+
+	var pending dto.AdjustmentListResponse
+	if code := h.do(http.MethodGet, "/adjustments?status=pending", support, nil, &pending); code != http.StatusOK || pending.Total != 1 || pending.Adjustments[0].ID != proposed.ID {
+		t.Errorf("pending list: status %d, %+v", code, pending)
+	}
+
+	var rejected dto.AdjustmentResponse
+	if code := h.do(http.MethodPost, fmt.Sprintf("/adjustments/%d/reject", proposed.ID), alice, dto.AdjustmentDecisionRequest{Notes: "duplicate"}, &rejected); code != http.StatusOK {
+		t.Fatalf("reject: status %d", code)
+	}
+	if rejected.Status != models.AdjustmentStatusRejected || rejected.DecidedBy != "alice" || rejected.Transaction.Status != models.TransactionStatusFailed {
+		t.Errorf("rejected %+v, want rejected by alice with a failed transaction", rejected)
+	}
+	if entries := h.ledgerEntries(42); len(entries) != 0 {
+		t.Errorf("rejection posted %d ledger entries", len(entries))
+	}
+	h.waitForSettlements(0)
+
+	var got dto.AdjustmentResponse
+	if code := h.do(http.MethodGet, fmt.Sprintf("/adjustments/%d", proposed.ID), support, nil, &got); code != http.StatusOK || got.Status != models.AdjustmentStatusRejected {
+		t.Errorf("get rejected adjustment: status %d, %+v", code, got)
+	}
+	if code, e := h.fail(http.MethodGet, "/adjustments/99", support, nil); code != http.StatusNotFound || e.Code != "not_found" {
+		t.Errorf("unknown adjustment: got %d %s, want 404 not_found", code, e.Code)
+	}
+}
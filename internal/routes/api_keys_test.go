@@ -0,0 +1,44 @@
+package routes_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/kodra-pay/transaction-service/internal/dto"
+)
+
+func TestAPIKeysStayWithTheirMerchant(t *testing.T) {
+	h := newHarness(t)
+	own := h.apiKey(12, "live")
+	other := h.apiKey(15, "live")
+
+	var keys dto.APIKeyListResponse
+	h.do(http.MethodGet, "/admin/merchants/15/api-keys", internalToken, nil, &keys)
+	if len(keys.Keys) != 1 {
+		t.Fatalf("merchant 15 has keys %+v, want one", keys.Keys)
+	}
+	otherID := keys.Keys[0].ID
+
+	if code := h.do(http.MethodDelete, fmt.Sprintf("/api-keys/%d", otherID), own, nil, nil); code != http.StatusNotFound {
+		t.Errorf("revoke another merchant's key: status %d, want 404", code)
+	}
+	if code := h.do(http.MethodGet, "/transactions", other, nil, nil); code != http.StatusOK {
+		t.Errorf("merchant 15's key after the attempt: status %d, want 200", code)
+	}
+	if code := h.do(http.MethodPost, "/admin/merchants/15/api-keys", own, dto.APIKeyCreateRequest{Mode: "live"}, nil); code != http.StatusForbidden {
+		t.Errorf("merchant key minting keys: status %d, want 403", code)
+	}
+
+	keys = dto.APIKeyListResponse{}
+	h.do(http.MethodGet, "/api-keys", own, nil, &keys)
+	if len(keys.Keys) != 1 || keys.Keys[0].MerchantID != 12 {
+		t.Fatalf("merchant 12 listed %+v", keys.Keys)
+	}
+	if code := h.do(http.MethodDelete, fmt.Sprintf("/api-keys/%d", keys.Keys[0].ID), own, nil, nil); code != http.StatusNoContent {
+		t.Errorf("revoke own key: status %d, want 204", code)
+	}
+	if code := h.do(http.MethodGet, "/transactions", own, nil, nil); code != http.StatusUnauthorized {
+		t.Errorf("revoked key: status %d, want 401", code)
+	}
+}
@@ -0,0 +1,149 @@
+package routes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/kodra-pay/transaction-service/internal/config"
+	"github.com/kodra-pay/transaction-service/internal/handlers"
+	"github.com/kodra-pay/transaction-service/internal/memory"
+	"github.com/kodra-pay/transaction-service/internal/metrics"
+	"github.com/kodra-pay/transaction-service/internal/migrate"
+	"github.com/kodra-pay/transaction-service/internal/pii"
+	"github.com/kodra-pay/transaction-service/internal/queue"
+	"github.com/kodra-pay/transaction-service/internal/repositories"
+	"github.com/kodra-pay/transaction-service/internal/services"
+	"github.com/kodra-pay/transaction-service/migrations"
+)
+
+// SettlementQueue is a settlement publisher whose backlog can be measured.
+type SettlementQueue interface {
+	services.SettlementPublisher
+	PendingCount(ctx context.Context) (int64, error)
+}
+
+// NotificationQueue is a notifier whose backlog can be measured.
+type NotificationQueue interface {
+	services.Notifier
+	Backlog(ctx context.Context) (int64, error)
+}
+
+// Dependencies are the stores, queues and outside services Register wires
+// the handlers to.
+type Dependencies struct {
+	Transactions  services.TransactionStore
+	Ledger        services.LedgerStore
+	Events        services.EventStore
+	Reviews       services.ReviewStore
+	Adjustments   services.AdjustmentStore
+	Disputes      services.DisputeStore
+	Limits        services.LimitStore
+	Volumes       services.VolumeCounter
+	Audit         services.AuditStore
+	APIKeys       services.APIKeyStore
+	Erasures      services.ErasureStore
+	RateLimits    services.TokenBucket
+	Settlements   SettlementQueue
+	Notifications NotificationQueue
+	Balances      services.BalanceUpdater
+	// Checks are readiness checks for the backing services.
+	Checks []handlers.HealthCheck
+	// Close releases connections once background work has finished. It may be nil.
+	Close func() error
+}
+
+// NewDependencies builds the dependencies cfg.Storage asks for. With
+// Postgres it retries the database until ctx ends and applies migrations if
+// configured.
+func NewDependencies(ctx context.Context, cfg config.Config, logger *slog.Logger) (*Dependencies, error) {
+	keys, err := pii.NewKeyProvider(cfg.PII)
+	if err != nil {
+		return nil, err
+	}
+	var cipher *pii.Cipher
+	if keys != nil {
+		cipher = pii.NewCipher(keys)
+	}
+
+	if cfg.Storage == config.StorageMemory {
+		logger.Warn("STORAGE=memory: data is kept in process and lost on restart")
+		return InMemory(cipher), nil
+	}
+	if cipher == nil {
+		logger.Warn("PII_KEYFILE not set: customer PII will be stored unencrypted")
+	}
+
+	db, err := repositories.Connect(ctx, cfg.Postgres, logger)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.MigrateOnStart {
+		m, err := migrate.New(db, migrations.FS, logger)
+		if err != nil {
+			db.Close()
+			return nil, err
+		}
+		if _, err := m.Up(ctx); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("migrate on start: %w", err)
+		}
+	}
+	metrics.RegisterDB(db, "postgres")
+
+	// The settlement publisher owns the Redis client shared with the others.
+	redisClient := queue.NewRedisClient(cfg.Redis, logger)
+	publisher := queue.NewSettlementPublisher(redisClient, logger)
+
+	return &Dependencies{
+		Transactions:  repositories.NewTransactionRepository(db, cipher),
+		Ledger:        repositories.NewLedgerRepository(db),
+		Events:        repositories.NewEventRepository(db),
+		Reviews:       repositories.NewReviewRepository(db),
+		Adjustments:   repositories.NewAdjustmentRepository(db),
+		Disputes:      repositories.NewDisputeRepository(db),
+		Limits:        repositories.NewLimitRepository(db),
+		Volumes:       repositories.NewVolumeCounter(redisClient),
+		Audit:         repositories.NewAuditRepository(db),
+		APIKeys:       repositories.NewAPIKeyRepository(db),
+		Erasures:      repositories.NewErasureRepository(db, cipher),
+		RateLimits:    repositories.NewTokenBucket(redisClient),
+		Settlements:   publisher,
+		Notifications: queue.NewNotificationPublisher(redisClient),
+		Balances:      services.NewMerchantBalanceClient(cfg.MerchantService, logger),
+		Checks: []handlers.HealthCheck{
+			{Name: "postgres", Check: db.PingContext},
+			{Name: "redis", Check: func(ctx context.Context) error {
+				return redisClient.Ping(ctx).Err()
+			}},
+		},
+		Close: func() error {
+			return errors.Join(publisher.Close(), db.Close())
+		},
+	}, nil
+}
+
+// InMemory builds dependencies held entirely in process, for tests and local
+// demos. Settlements, Notifications and Balances are the memory package's
+// recorders, so callers may type-assert them to inspect what was sent.
+func InMemory(cipher *pii.Cipher) *Dependencies {
+	store := memory.NewStore(cipher)
+	return &Dependencies{
+		Transactions:  memory.NewTransactionRepository(store),
+		Ledger:        memory.NewLedgerRepository(store),
+		Events:        memory.NewEventRepository(store),
+		Reviews:       memory.NewReviewRepository(store),
+		Adjustments:   memory.NewAdjustmentRepository(store),
+		Disputes:      memory.NewDisputeRepository(store),
+		Limits:        memory.NewLimitRepository(store),
+		Volumes:       memory.NewVolumeCounter(),
+		Audit:         memory.NewAuditRepository(store),
+		APIKeys:       memory.NewAPIKeyRepository(store),
+		Erasures:      memory.NewErasureRepository(store),
+		RateLimits:    memory.NewTokenBucket(),
+		Settlements:   memory.NewSettlementPublisher(),
+		Notifications: memory.NewNotificationPublisher(),
+		Balances:      memory.NewBalanceRecorder(),
+	}
+}
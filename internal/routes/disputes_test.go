@@ -0,0 +1,155 @@
+package routes_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/kodra-pay/transaction-service/internal/dto"
+	"github.com/kodra-pay/transaction-service/internal/models"
+)
+
+// openDispute disputes a transaction and fails the test unless it is accepted.
+func (h *harness) openDispute(reference string, req dto.DisputeCreateRequest) dto.DisputeResponse {
+	h.t.Helper()
+	var d dto.DisputeResponse
+	if code := h.do(http.MethodPost, "/transactions/"+reference+"/disputes", internalToken, req, &d); code != http.StatusCreated {
+		h.t.Fatalf("dispute %s: status %d", reference, code)
+	}
+	return d
+}
+
+// resolveDispute moves a dispute to status and fails the test unless it is accepted.
+func (h *harness) resolveDispute(id int, status string) {
+	h.t.Helper()
+	path := fmt.Sprintf("/disputes/%d", id)
+	if code := h.do(http.MethodPatch, path, internalToken, dto.DisputeUpdateRequest{Status: status}, nil); code != http.StatusOK {
+		h.t.Fatalf("move dispute %d to %s: status %d", id, status, code)
+	}
+}
+
+func TestRefundAfterDispute(t *testing.T) {
+	h := newHarness(t)
+	key := h.apiKey(12, "live")
+	h.create(key, dto.TransactionCreateRequest{Reference: "sale-1", Amount: 100, Currency: "NGN"})
+
+	d := h.openDispute("sale-1", dto.DisputeCreateRequest{ReasonCode: "fraud"})
+	if code, e := h.fail(http.MethodPost, "/transactions/sale-1/refund", key, nil); code != http.StatusConflict || e.Code != "conflict" {
+		t.Errorf("refund with an open dispute: got %d %s, want 409 conflict", code, e.Code)
+	}
+	event := dto.TransactionEventRequest{Status: models.TransactionStatusRefunded, Source: models.EventSourceWebhook}
+	if code, _ := h.fail(http.MethodPost, "/internal/transactions/sale-1/events", internalToken, event); code != http.StatusConflict {
+		t.Errorf("refund event with an open dispute: status %d, want 409", code)
+	}
+
+	h.resolveDispute(d.ID, models.DisputeStatusLost)
+	if code, _ := h.fail(http.MethodPost, "/transactions/sale-1/refund", key, nil); code != http.StatusConflict {
+		t.Errorf("refund after a lost dispute: status %d, want 409", code)
+	}
+	var tx dto.TransactionResponse
+	h.do(http.MethodGet, "/transactions/sale-1", key, nil, &tx)
+	if tx.Status != models.TransactionStatusSuccess {
+		t.Errorf("refused refund left status %q, want success", tx.Status)
+	}
+	entries := h.ledgerEntries(12)
+	if len(entries) != 2 || entries[1].Description != "Chargeback debit" || entries[1].BalanceAfter != 0 {
+		t.Errorf("ledger %+v, want only the sale credit and the chargeback debit", entries)
+	}
+
+	// Once a dispute is won the money is back with the merchant and may be refunded.
+	h.create(key, dto.TransactionCreateRequest{Reference: "sale-2", Amount: 40, Currency: "NGN"})
+	won := h.openDispute("sale-2", dto.DisputeCreateRequest{ReasonCode: "fraud"})
+	h.resolveDispute(won.ID, models.DisputeStatusUnderReview)
+	h.resolveDispute(won.ID, models.DisputeStatusWon)
+	if code := h.do(http.MethodPost, "/transactions/sale-2/refund", key, nil, nil); code != http.StatusOK {
+		t.Errorf("refund after a won dispute: status %d, want 200", code)
+	}
+}
+
+func TestTransactionChargedBackOnce(t *testing.T) {
+	h := newHarness(t)
+	key := h.apiKey(12, "live")
+	h.create(key, dto.TransactionCreateRequest{Reference: "sale-1", Amount: 100, Currency: "NGN"})
+
+	d := h.openDispute("sale-1", dto.DisputeCreateRequest{ReasonCode: "fraud"})
+	if code, e := h.fail(http.MethodPost, "/transactions/sale-1/disputes", internalToken, dto.DisputeCreateRequest{ReasonCode: "fraud"}); code != http.StatusConflict || e.Code != "conflict" {
+		t.Errorf("second dispute while one is open: got %d %s, want 409 conflict", code, e.Code)
+	}
+	h.resolveDispute(d.ID, models.DisputeStatusLost)
+	if code, e := h.fail(http.MethodPost, "/transactions/sale-1/disputes", internalToken, dto.DisputeCreateRequest{ReasonCode: "duplicate"}); code != http.StatusConflict || e.Code != "conflict" {
+		t.Errorf("dispute after a lost one: got %d %s, want 409 conflict", code, e.Code)
+	}
+	if entries := h.ledgerEntries(12); len(entries) != 2 || entries[1].BalanceAfter != 0 {
+		t.Errorf("ledger %+v, want one chargeback leaving 0", entries)
+	}
+
+	// A won dispute gave the money back, so the transaction may be disputed again.
+	h.create(key, dto.TransactionCreateRequest{Reference: "sale-2", Amount: 40, Currency: "NGN"})
+	won := h.openDispute("sale-2", dto.DisputeCreateRequest{ReasonCode: "fraud"})
+	h.resolveDispute(won.ID, models.DisputeStatusUnderReview)
+	h.resolveDispute(won.ID, models.DisputeStatusWon)
+	h.openDispute("sale-2", dto.DisputeCreateRequest{ReasonCode: "fraud"})
+}
+
+func TestDisputeSettlement(t *testing.T) {
+	h := newHarness(t)
+	key := h.apiKey(12, "live")
+	tx := h.create(key, dto.TransactionCreateRequest{Reference: "sale-1", Amount: 100, Currency: "NGN"})
+	h.waitForSettlements(1)
+
+	d := h.openDispute("sale-1", dto.DisputeCreateRequest{Amount: 30, ReasonCode: "fraud"})
+	settled := h.waitForSettlements(2)
+	if s := settled[1]; s.Amount != -3000 || s.MerchantID != 12 || s.Currency != "NGN" || s.TransactionID != tx.ID {
+		t.Errorf("chargeback published %+v, want -3000 NGN for sale-1", s)
+	}
+	if pending := h.settlements.Pending(12); pending != 7000 {
+		t.Errorf("merchant 12 has %d unsettled with a dispute open, want 7000", pending)
+	}
+
+	h.resolveDispute(d.ID, models.DisputeStatusUnderReview)
+	h.resolveDispute(d.ID, models.DisputeStatusWon)
+	settled = h.waitForSettlements(3)
+	if s := settled[2]; s.Amount != 3000 || s.TransactionID != tx.ID {
+		t.Errorf("won dispute published %+v, want 3000 for sale-1", s)
+	}
+	if pending := h.settlements.Pending(12); pending != 10000 {
+		t.Errorf("merchant 12 has %d unsettled after winning the dispute, want 10000", pending)
+	}
+}
+
+func TestDisputesStayInTheirMode(t *testing.T) {
+	h := newHarness(t)
+	live := h.apiKey(12, "live")
+	test := h.apiKey(12, "test")
+	h.create(live, dto.TransactionCreateRequest{Reference: "sale-1", Amount: 100, Currency: "NGN"})
+	d := h.openDispute("sale-1", dto.DisputeCreateRequest{ReasonCode: "fraud"})
+	if !d.Livemode {
+		t.Fatalf("dispute on a live transaction opened as test mode: %+v", d)
+	}
+
+	path := fmt.Sprintf("/disputes/%d", d.ID)
+	for _, req := range []struct {
+		method string
+		path   string
+		body   interface{}
+	}{
+		{http.MethodGet, path, nil},
+		{http.MethodPatch, path, dto.DisputeUpdateRequest{Status: models.DisputeStatusUnderReview}},
+		{http.MethodPost, path + "/evidence", dto.DisputeEvidenceRequest{Type: "receipt"}},
+	} {
+		if code, e := h.fail(req.method, req.path, test, req.body); code != http.StatusNotFound || e.Code != "not_found" {
+			t.Errorf("test key %s %s: got %d %s, want 404 not_found", req.method, req.path, code, e.Code)
+		}
+	}
+	var list dto.DisputeListResponse
+	if code := h.do(http.MethodGet, "/disputes", test, nil, &list); code != http.StatusOK || len(list.Disputes) != 0 {
+		t.Errorf("test key listed %d disputes with status %d, want none", len(list.Disputes), code)
+	}
+	if code := h.do(http.MethodGet, path, live, nil, nil); code != http.StatusOK {
+		t.Errorf("live key reading its dispute: status %d, want 200", code)
+	}
+	list = dto.DisputeListResponse{}
+	if h.do(http.MethodGet, "/disputes", live, nil, &list); len(list.Disputes) != 1 {
+		t.Errorf("live key listed %d disputes, want 1", len(list.Disputes))
+	}
+}
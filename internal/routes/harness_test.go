@@ -0,0 +1,237 @@
+package routes_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/kodra-pay/transaction-service/internal/config"
+	"github.com/kodra-pay/transaction-service/internal/dto"
+	"github.com/kodra-pay/transaction-service/internal/handlers"
+	"github.com/kodra-pay/transaction-service/internal/memory"
+	"github.com/kodra-pay/transaction-service/internal/middleware"
+	"github.com/kodra-pay/transaction-service/internal/models"
+	"github.com/kodra-pay/transaction-service/internal/routes"
+)
+
+const (
+	internalToken = "e2e-internal-token"
+	// otherInternalToken belongs to a second internal service.
+	otherInternalToken = "e2e-other-internal-token"
+	jwtSecret          = "e2e-jwt-secret"
+)
+
+// harness is the whole service booted by routes.Register on in-memory
+// dependencies, driven through app.Test without opening a port.
+type harness struct {
+	t           *testing.T
+	app         *fiber.App
+	ledger      *memory.LedgerRepository
+	settlements *memory.SettlementPublisher
+	balances    *memory.BalanceRecorder
+	erasures    *memory.ErasureRepository
+}
+
+func newHarness(t *testing.T) *harness {
+	t.Helper()
+	return newHarnessWithEnv(t, nil)
+}
+
+// newHarnessWithEnv is newHarness with extra settings, given as environment
+// variables, applied over the pinned ones.
+func newHarnessWithEnv(t *testing.T, env map[string]string) *harness {
+	t.Helper()
+	// Pin everything the tests rely on, whatever the developer's environment says.
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("STORAGE", config.StorageMemory)
+	t.Setenv("INTERNAL_API_TOKENS", internalToken+","+otherInternalToken)
+	t.Setenv("RATE_LIMIT_ENABLED", "false")
+	t.Setenv("PII_KEYFILE", "")
+	t.Setenv("RBAC_POLICY_FILE", "")
+	t.Setenv("ERASURE_POLL_INTERVAL", "10ms")
+	t.Setenv("JWT_HS256_SECRET", jwtSecret)
+	t.Setenv("JWT_JWKS_FILE", "")
+	t.Setenv("JWT_PUBLIC_KEY_FILES", "")
+	t.Setenv("JWT_ISSUER", "")
+	t.Setenv("JWT_AUDIENCE", "")
+	for k, v := range env {
+		t.Setenv(k, v)
+	}
+	cfg, err := config.Load("transaction-service", "7004")
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	app := fiber.New(fiber.Config{ErrorHandler: handlers.ErrorHandler})
+	app.Use(middleware.RequestID())
+	deps := routes.InMemory(nil)
+	shutdown, err := routes.Register(app, cfg, logger, deps)
+	if err != nil {
+		t.Fatalf("register routes: %v", err)
+	}
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdown(ctx); err != nil {
+			t.Errorf("shutdown: %v", err)
+		}
+	})
+
+	return &harness{
+		t:           t,
+		app:         app,
+		ledger:      deps.Ledger.(*memory.LedgerRepository),
+		settlements: deps.Settlements.(*memory.SettlementPublisher),
+		balances:    deps.Balances.(*memory.BalanceRecorder),
+		erasures:    deps.Erasures.(*memory.ErasureRepository),
+	}
+}
+
+// do sends a request with credential as the bearer token and decodes a JSON
+// reply into out, if given. It returns the status code.
+func (h *harness) do(method, path, credential string, body, out interface{}) int {
+	h.t.Helper()
+	code, raw := h.send(method, path, credential, body)
+	if out != nil && code < 300 {
+		if err := json.Unmarshal(raw, out); err != nil {
+			h.t.Fatalf("decode %s %s: %v: %s", method, path, err, raw)
+		}
+	}
+	return code
+}
+
+// fail sends a request that should be rejected and returns the status code
+// and the error envelope it was answered with.
+func (h *harness) fail(method, path, credential string, body interface{}) (int, dto.ErrorResponse) {
+	h.t.Helper()
+	code, raw := h.send(method, path, credential, body)
+	var e dto.ErrorResponse
+	if err := json.Unmarshal(raw, &e); err != nil {
+		h.t.Fatalf("decode error from %s %s: %v: %s", method, path, err, raw)
+	}
+	return code, e
+}
+
+func (h *harness) send(method, path, credential string, body interface{}) (int, []byte) {
+	h.t.Helper()
+	var reader io.Reader
+	switch b := body.(type) {
+	case nil:
+	case string:
+		reader = bytes.NewBufferString(b)
+	default:
+		raw, err := json.Marshal(b)
+		if err != nil {
+			h.t.Fatalf("encode request: %v", err)
+		}
+		reader = bytes.NewReader(raw)
+	}
+	req := httptest.NewRequest(method, path, reader)
+	if reader != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if credential != "" {
+		req.Header.Set("Authorization", "Bearer "+credential)
+	}
+	resp, err := h.app.Test(req, -1)
+	if err != nil {
+		h.t.Fatalf("%s %s: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		h.t.Fatalf("read %s %s: %v", method, path, err)
+	}
+	return resp.StatusCode, raw
+}
+
+// apiKey issues a key for the merchant in mode "live" or "test".
+func (h *harness) apiKey(merchantID int, mode string) string {
+	h.t.Helper()
+	var created dto.APIKeyCreatedResponse
+	path := fmt.Sprintf("/admin/merchants/%d/api-keys", merchantID)
+	if code := h.do(http.MethodPost, path, internalToken, dto.APIKeyCreateRequest{Mode: mode}, &created); code != http.StatusCreated {
+		h.t.Fatalf("create %s api key for merchant %d: status %d", mode, merchantID, code)
+	}
+	return created.Key
+}
+
+// staffToken signs a dashboard token for a staff member, not bound to any
+// merchant, holding roles.
+func (h *harness) staffToken(subject string, roles ...string) string {
+	h.t.Helper()
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub":   subject,
+		"roles": roles,
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte(jwtSecret))
+	if err != nil {
+		h.t.Fatalf("sign token for %s: %v", subject, err)
+	}
+	return signed
+}
+
+// create makes a transaction and fails the test unless it is accepted.
+func (h *harness) create(key string, req dto.TransactionCreateRequest) dto.TransactionResponse {
+	h.t.Helper()
+	var tx dto.TransactionResponse
+	if code := h.do(http.MethodPost, "/transactions", key, req, &tx); code != http.StatusCreated {
+		h.t.Fatalf("create transaction %q: status %d", req.Reference, code)
+	}
+	return tx
+}
+
+// ledgerEntries returns the merchant's ledger postings, oldest first.
+func (h *harness) ledgerEntries(merchantID int) []*models.LedgerEntry {
+	h.t.Helper()
+	entries, err := h.ledger.ListByMerchant(context.Background(), merchantID)
+	if err != nil {
+		h.t.Fatalf("list ledger: %v", err)
+	}
+	return entries
+}
+
+// waitForSettlements waits for n settlements to be published; publishing
+// happens in the background after the response is sent.
+func (h *harness) waitForSettlements(n int) []memory.Settlement {
+	h.t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		published := h.settlements.Published()
+		if len(published) >= n || time.Now().After(deadline) {
+			if len(published) != n {
+				h.t.Fatalf("got %d settlements, want %d: %+v", len(published), n, published)
+			}
+			return published
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// waitForBalanceUpdates waits for exactly n merchant balance updates to be
+// sent, as waitForSettlements does for settlements.
+func (h *harness) waitForBalanceUpdates(n int) []memory.BalanceUpdate {
+	h.t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		updates := h.balances.Updates()
+		if len(updates) >= n || time.Now().After(deadline) {
+			if len(updates) != n {
+				h.t.Fatalf("got %d balance updates, want %d: %+v", len(updates), n, updates)
+			}
+			return updates
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
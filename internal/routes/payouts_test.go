@@ -0,0 +1,131 @@
+package routes_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/kodra-pay/transaction-service/internal/dto"
+	"github.com/kodra-pay/transaction-service/internal/models"
+)
+
+func TestPayoutDebitsAvailableBalance(t *testing.T) {
+	h := newHarness(t)
+	key := h.apiKey(12, "live")
+
+	payment := h.create(key, dto.TransactionCreateRequest{Reference: "sale-1", Amount: 100, Currency: "NGN"})
+	if payment.Type != models.TransactionTypePayment {
+		t.Errorf("created type %q, want payment", payment.Type)
+	}
+
+	if code, e := h.fail(http.MethodPost, "/payouts", key, dto.PayoutCreateRequest{Reference: "po-big", Amount: 150, Currency: "NGN"}); code != http.StatusUnprocessableEntity || e.Code != "insufficient_balance" {
+		t.Errorf("payout over balance: got %d %s, want 422 insufficient_balance", code, e.Code)
+	}
+	if code, e := h.fail(http.MethodPost, "/payouts", key, dto.PayoutCreateRequest{Reference: "po-usd", Amount: 10, Currency: "USD"}); code != http.StatusUnprocessableEntity || e.Code != "insufficient_balance" {
+		t.Errorf("payout in a currency without balance: got %d %s, want 422 insufficient_balance", code, e.Code)
+	}
+
+	var payout dto.TransactionResponse
+	if code := h.do(http.MethodPost, "/payouts", key, dto.PayoutCreateRequest{Reference: "po-1", Amount: 60, Currency: "NGN"}, &payout); code != http.StatusCreated {
+		t.Fatalf("payout: status %d", code)
+	}
+	if payout.Type != models.TransactionTypePayout || payout.Status != models.TransactionStatusPending || payout.MerchantID != 12 {
+		t.Errorf("payout %+v, want a pending payout for merchant 12", payout)
+	}
+	entries := h.ledgerEntries(12)
+	if len(entries) != 2 {
+		t.Fatalf("got %d ledger entries, want the sale credit and the payout debit", len(entries))
+	}
+	if debit := entries[1]; debit.EntryType != models.LedgerEntryDebit || debit.Amount != 6000 || debit.BalanceAfter != 4000 || debit.TransactionID != payout.ID {
+		t.Errorf("payout posted %+v, want a 6000 debit leaving 4000", debit)
+	}
+
+	// A payout is not a payment: it cannot be captured, refunded or disputed.
+	for _, action := range []string{"capture", "refund", "void"} {
+		if code, e := h.fail(http.MethodPost, "/transactions/po-1/"+action, key, nil); code != http.StatusConflict || e.Code != "invalid_state_transition" {
+			t.Errorf("%s payout: got %d %s, want 409 invalid_state_transition", action, code, e.Code)
+		}
+	}
+	if code := h.do(http.MethodPost, "/transactions/po-1/disputes", internalToken, dto.DisputeCreateRequest{ReasonCode: "fraud"}, nil); code < 400 {
+		t.Errorf("dispute on a payout accepted with status %d", code)
+	}
+	if code, _ := h.fail(http.MethodPost, "/internal/transactions/po-1/events", internalToken, dto.TransactionEventRequest{Status: models.TransactionStatusRefunded, Source: models.EventSourceWebhook}); code != http.StatusConflict {
+		t.Errorf("refund event on a payout: status %d, want 409", code)
+	}
+
+	// The second payout only fits once the first has failed and been credited back.
+	if code, _ := h.fail(http.MethodPost, "/payouts", key, dto.PayoutCreateRequest{Reference: "po-2", Amount: 50, Currency: "NGN"}); code != http.StatusUnprocessableEntity {
+		t.Errorf("second payout: status %d, want 422", code)
+	}
+	var failed dto.TransactionResponse
+	if code := h.do(http.MethodPost, "/internal/transactions/po-1/events", internalToken, dto.TransactionEventRequest{Status: models.TransactionStatusFailed, Source: models.EventSourceWebhook}, &failed); code != http.StatusOK {
+		t.Fatalf("fail payout: status %d", code)
+	}
+	entries = h.ledgerEntries(12)
+	if reversal := entries[len(entries)-1]; reversal.EntryType != models.LedgerEntryCredit || reversal.Amount != 6000 || reversal.BalanceAfter != 10000 {
+		t.Errorf("failed payout posted %+v, want a 6000 credit back to 10000", reversal)
+	}
+	if code := h.do(http.MethodPost, "/payouts", key, dto.PayoutCreateRequest{Reference: "po-2", Amount: 50, Currency: "NGN"}, nil); code != http.StatusCreated {
+		t.Errorf("payout after reversal: status %d", code)
+	}
+
+	// Payouts only move the ledger; the merchant service and settlement
+	// netting see the sale alone.
+	if settled := h.waitForSettlements(1); settled[0].Amount != 10000 {
+		t.Errorf("settlements %+v, want only the 10000 sale", settled)
+	}
+	if updates := h.waitForBalanceUpdates(1); updates[0].Amount != 100 {
+		t.Errorf("balance updates %+v, want only the 100 NGN sale", updates)
+	}
+}
+
+func TestPayoutRequestChecks(t *testing.T) {
+	h := newHarness(t)
+	key := h.apiKey(12, "live")
+	test := h.apiKey(12, "test")
+
+	if code, e := h.fail(http.MethodPost, "/payouts", key, dto.PayoutCreateRequest{Reference: "po-x", MerchantID: 15, Amount: 1, Currency: "NGN"}); code != http.StatusForbidden || e.Code != "forbidden" {
+		t.Errorf("payout for another merchant: got %d %s, want 403 forbidden", code, e.Code)
+	}
+	if code, e := h.fail(http.MethodPost, "/payouts", key, dto.PayoutCreateRequest{Reference: "po-neg", Amount: -1, Currency: "NGN"}); code != http.StatusBadRequest || e.Code != "validation_failed" {
+		t.Errorf("negative payout: got %d %s, want 400 validation_failed", code, e.Code)
+	}
+	// Payments can no longer pose as payouts.
+	if code, _ := h.fail(http.MethodPost, "/transactions", key, dto.TransactionCreateRequest{Reference: "fake", Amount: 1, Currency: "NGN", PaymentMethod: "payout"}); code != http.StatusBadRequest {
+		t.Errorf("payment with payout method: status %d, want 400", code)
+	}
+
+	var sandbox dto.TransactionResponse
+	if code := h.do(http.MethodPost, "/payouts", test, dto.PayoutCreateRequest{Reference: "po-test", Amount: 25, Currency: "NGN"}, &sandbox); code != http.StatusCreated {
+		t.Fatalf("test-mode payout: status %d", code)
+	}
+	if sandbox.Livemode {
+		t.Errorf("test-mode payout is live: %+v", sandbox)
+	}
+	if entries := h.ledgerEntries(12); len(entries) != 0 {
+		t.Errorf("test-mode payout posted %+v", entries)
+	}
+}
+
+func TestLedgerBalancePerCurrency(t *testing.T) {
+	h := newHarness(t)
+	key := h.apiKey(12, "live")
+	h.create(key, dto.TransactionCreateRequest{Reference: "ngn-1", Amount: 100, Currency: "NGN"})
+	h.create(key, dto.TransactionCreateRequest{Reference: "usd-1", Amount: 20, Currency: "USD"})
+	h.create(key, dto.TransactionCreateRequest{Reference: "ngn-2", Amount: 5, Currency: "NGN"})
+	if code := h.do(http.MethodPost, "/payouts", key, dto.PayoutCreateRequest{Reference: "po-usd", Amount: 15, Currency: "USD"}, nil); code != http.StatusCreated {
+		t.Fatalf("usd payout: status %d", code)
+	}
+
+	entries := h.ledgerEntries(12)
+	if len(entries) != 4 {
+		t.Fatalf("got %d ledger entries, want 4", len(entries))
+	}
+	for i, want := range []struct {
+		currency string
+		balance  int64
+	}{{"NGN", 10000}, {"USD", 2000}, {"NGN", 10500}, {"USD", 500}} {
+		if e := entries[i]; e.Currency != want.currency || e.BalanceAfter != want.balance {
+			t.Errorf("entry %d: %s balance %d, want %s balance %d", i, e.Currency, e.BalanceAfter, want.currency, want.balance)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package routes_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/kodra-pay/transaction-service/internal/dto"
+	"github.com/kodra-pay/transaction-service/internal/models"
+)
+
+func TestHistoryHoldsNoPIIAfterErasure(t *testing.T) {
+	h := newHarness(t)
+	key := h.apiKey(12, "live")
+	const email, name = "ada@example.com", "Ada Lovelace"
+	h.create(key, dto.TransactionCreateRequest{Reference: "sale-1", Amount: 20, Currency: "NGN", CustomerEmail: email, CustomerName: name})
+	h.do(http.MethodPost, "/transactions/sale-1/refund", key, nil, nil)
+
+	var erasure dto.ErasureResponse
+	if code := h.do(http.MethodPost, "/admin/erasures", internalToken, dto.ErasureCreateRequest{Email: email}, &erasure); code != http.StatusAccepted {
+		t.Fatalf("request erasure: status %d", code)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for erasure.Status != models.ErasureStatusCompleted {
+		if time.Now().After(deadline) {
+			t.Fatalf("erasure still %s", erasure.Status)
+		}
+		time.Sleep(5 * time.Millisecond)
+		h.do(http.MethodGet, fmt.Sprintf("/admin/erasures/%d", erasure.ID), internalToken, nil, &erasure)
+	}
+	if erasure.TransactionsAffected != 1 || erasure.Verified == nil || !*erasure.Verified {
+		t.Errorf("erasure %+v, want one verified transaction", erasure)
+	}
+	stored, err := h.erasures.GetByID(context.Background(), erasure.ID)
+	if err != nil || stored.Email != "" {
+		t.Errorf("completed erasure request still holds email %q (err %v)", stored.Email, err)
+	}
+
+	code, raw := h.send(http.MethodGet, "/transactions/sale-1/history", internalToken, nil)
+	if code != http.StatusOK {
+		t.Fatalf("history: status %d", code)
+	}
+	for _, pii := range []string{email, name} {
+		if bytes.Contains(raw, []byte(pii)) {
+			t.Errorf("history after erasure still shows %q: %s", pii, raw)
+		}
+	}
+	var history dto.TransactionHistoryResponse
+	h.do(http.MethodGet, "/transactions/sale-1/history", internalToken, nil, &history)
+	if !history.Verified || len(history.Entries) != 2 {
+		t.Errorf("history %+v, want the create and refund entries, verified", history)
+	}
+}
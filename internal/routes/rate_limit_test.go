@@ -0,0 +1,43 @@
+package routes_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/kodra-pay/transaction-service/internal/dto"
+)
+
+func TestExportsAreLimitedSeparately(t *testing.T) {
+	h := newHarnessWithEnv(t, map[string]string{
+		"RATE_LIMIT_ENABLED":            "true",
+		"RATE_LIMIT_EXPORTS":            "2",
+		"RATE_LIMIT_MERCHANT_OVERRIDES": "15:exports=3",
+	})
+	key := h.apiKey(12, "live")
+	h.create(key, dto.TransactionCreateRequest{Reference: "sale-1", Amount: 100, Currency: "NGN"})
+
+	for i := 0; i < 2; i++ {
+		if code := h.do(http.MethodGet, "/transactions", key, nil, nil); code != http.StatusOK {
+			t.Fatalf("list %d: status %d", i, code)
+		}
+	}
+	if code, e := h.fail(http.MethodGet, "/disputes", key, nil); code != http.StatusTooManyRequests || e.Code != "rate_limited" {
+		t.Errorf("third list: got %d %s, want 429 rate_limited", code, e.Code)
+	}
+	if code := h.do(http.MethodGet, "/transactions/sale-1", key, nil, nil); code != http.StatusOK {
+		t.Errorf("single read after exports ran out: status %d, want 200", code)
+	}
+	if code := h.do(http.MethodGet, "/transactions?merchant_id=12", internalToken, nil, nil); code != http.StatusOK {
+		t.Errorf("internal list: status %d, want 200", code)
+	}
+
+	other := h.apiKey(15, "live")
+	for i := 0; i < 3; i++ {
+		if code := h.do(http.MethodGet, "/transactions", other, nil, nil); code != http.StatusOK {
+			t.Fatalf("merchant 15 list %d: status %d, want the override of 3", i, code)
+		}
+	}
+	if code := h.do(http.MethodGet, "/transactions", other, nil, nil); code != http.StatusTooManyRequests {
+		t.Errorf("merchant 15 past its override: status %d, want 429", code)
+	}
+}
@@ -0,0 +1,137 @@
+package routes_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kodra-pay/transaction-service/internal/dto"
+	"github.com/kodra-pay/transaction-service/internal/models"
+)
+
+// newReviewHarness holds every live transaction of 50 NGN or more for review.
+func newReviewHarness(t *testing.T, env map[string]string) *harness {
+	t.Helper()
+	if env == nil {
+		env = map[string]string{}
+	}
+	env["RISK_AMOUNT_THRESHOLD"] = "50"
+	return newHarnessWithEnv(t, env)
+}
+
+// held creates a transaction that the risk engine sends to review and returns
+// the review's ID.
+func (h *harness) held(key, reference string, req dto.TransactionCreateRequest) int {
+	h.t.Helper()
+	req.Reference = reference
+	if tx := h.create(key, req); tx.Status != models.TransactionStatusPendingReview {
+		h.t.Fatalf("%s created as %q, want pending_review", reference, tx.Status)
+	}
+	var queue dto.ReviewListResponse
+	h.do(http.MethodGet, "/reviews?status=pending", internalToken, nil, &queue)
+	for _, rv := range queue.Reviews {
+		if rv.Transaction.Reference == reference {
+			return rv.ID
+		}
+	}
+	h.t.Fatalf("no pending review for %s in %+v", reference, queue.Reviews)
+	return 0
+}
+
+func TestReviewerIsTheCaller(t *testing.T) {
+	h := newReviewHarness(t, nil)
+	key := h.apiKey(12, "live")
+	id := h.held(key, "big-1", dto.TransactionCreateRequest{Amount: 60, Currency: "NGN"})
+	alice := h.staffToken("alice", "support")
+	bob := h.staffToken("bob", "support")
+
+	// A reviewer named in the body is ignored.
+	var claimed dto.ReviewResponse
+	path := fmt.Sprintf("/reviews/%d", id)
+	if code := h.do(http.MethodPost, path+"/claim", alice, map[string]string{"reviewer": "bob"}, &claimed); code != http.StatusOK {
+		t.Fatalf("claim: status %d", code)
+	}
+	if claimed.Reviewer != "alice" {
+		t.Errorf("claimed by %q, want alice", claimed.Reviewer)
+	}
+	if code := h.do(http.MethodPost, path+"/claim", bob, nil, nil); code != http.StatusConflict {
+		t.Errorf("second claim: status %d, want 409", code)
+	}
+	if code := h.do(http.MethodPost, path+"/approve", bob, map[string]string{"reviewer": "alice"}, nil); code != http.StatusConflict {
+		t.Errorf("approve by someone else: status %d, want 409", code)
+	}
+
+	var approved dto.ReviewResponse
+	if code := h.do(http.MethodPost, path+"/approve", alice, dto.ReviewDecisionRequest{Notes: "known customer"}, &approved); code != http.StatusOK {
+		t.Fatalf("approve: status %d", code)
+	}
+	if approved.Status != models.ReviewStatusApproved || approved.Reviewer != "alice" || approved.Transaction.Status != models.TransactionStatusSuccess {
+		t.Errorf("approved %+v", approved)
+	}
+	if entries := h.ledgerEntries(12); len(entries) != 1 || entries[0].Amount != 6000 {
+		t.Errorf("ledger after approval: %+v, want one 6000 credit", entries)
+	}
+}
+
+func TestInternalTokensAreDistinctReviewers(t *testing.T) {
+	h := newReviewHarness(t, nil)
+	key := h.apiKey(12, "live")
+	id := h.held(key, "big-1", dto.TransactionCreateRequest{Amount: 60, Currency: "NGN"})
+	path := fmt.Sprintf("/reviews/%d", id)
+
+	var claimed dto.ReviewResponse
+	if code := h.do(http.MethodPost, path+"/claim", internalToken, nil, &claimed); code != http.StatusOK {
+		t.Fatalf("claim: status %d", code)
+	}
+	if claimed.Reviewer == "" || claimed.Reviewer == internalToken {
+		t.Errorf("internal reviewer recorded as %q", claimed.Reviewer)
+	}
+	if code := h.do(http.MethodPost, path+"/decline", otherInternalToken, nil, nil); code != http.StatusConflict {
+		t.Errorf("decline by another internal service: status %d, want 409", code)
+	}
+	if code := h.do(http.MethodPost, path+"/decline", internalToken, nil, nil); code != http.StatusOK {
+		t.Errorf("decline by the claiming service: status %d", code)
+	}
+}
+
+func TestReviewQueueRedactsPII(t *testing.T) {
+	policy := map[string][]string{
+		"transactions:create": {"merchant-owner"},
+		"api_keys:manage":     {"service"},
+		"reviews:read":        {"support", "service"},
+		"reviews:decide":      {"support", "service"},
+		"pii:read":            {"service"},
+	}
+	raw, err := json.Marshal(policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "policy.json")
+	if err := os.WriteFile(path, raw, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	h := newReviewHarness(t, map[string]string{"RBAC_POLICY_FILE": path})
+	key := h.apiKey(12, "live")
+	const email = "ada@example.com"
+	id := h.held(key, "big-1", dto.TransactionCreateRequest{Amount: 60, Currency: "NGN", CustomerEmail: email, CustomerName: "Ada Lovelace"})
+	support := h.staffToken("alice", "support")
+
+	var queue dto.ReviewListResponse
+	h.do(http.MethodGet, "/reviews", support, nil, &queue)
+	if len(queue.Reviews) != 1 || queue.Reviews[0].Transaction.CustomerEmail == email {
+		t.Errorf("review queue shows %+v to a caller without pii:read", queue.Reviews)
+	}
+	var rv dto.ReviewResponse
+	h.do(http.MethodPost, fmt.Sprintf("/reviews/%d/claim", id), support, nil, &rv)
+	if rv.Transaction.CustomerEmail == email || rv.Transaction.CustomerName == "Ada Lovelace" {
+		t.Errorf("claimed review shows %q, %q to a caller without pii:read", rv.Transaction.CustomerEmail, rv.Transaction.CustomerName)
+	}
+
+	h.do(http.MethodGet, fmt.Sprintf("/reviews/%d", id), internalToken, nil, &rv)
+	if rv.Transaction.CustomerEmail != email {
+		t.Errorf("internal caller sees email %q, want it in full", rv.Transaction.CustomerEmail)
+	}
+}
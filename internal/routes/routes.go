@@ -1,34 +1,159 @@
 package routes
 
 import (
+	"context"
+	"fmt"
+	"log/slog"
+
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/kodra-pay/transaction-service/internal/auth"
+	"github.com/kodra-pay/transaction-service/internal/background"
 	"github.com/kodra-pay/transaction-service/internal/config"
 	"github.com/kodra-pay/transaction-service/internal/handlers"
-	"github.com/kodra-pay/transaction-service/internal/queue"
-	"github.com/kodra-pay/transaction-service/internal/repositories"
+	"github.com/kodra-pay/transaction-service/internal/metrics"
+	"github.com/kodra-pay/transaction-service/internal/middleware"
+	"github.com/kodra-pay/transaction-service/internal/models"
 	"github.com/kodra-pay/transaction-service/internal/services"
 )
 
-func Register(app *fiber.App, serviceName string) {
-	health := handlers.NewHealthHandler(serviceName)
-	health.Register(app)
+// Register wires the service onto app, on top of deps. The returned function
+// waits for background work started by requests and workers, then closes
+// deps; call it after the server has stopped accepting requests.
+func Register(app *fiber.App, cfg config.Config, logger *slog.Logger, deps *Dependencies) (func(context.Context) error, error) {
+	app.Use(middleware.Metrics())
+	app.Use(middleware.Tracing())
+	app.Use(middleware.Logger(logger))
+	app.Get("/metrics", adaptor.HTTPHandler(promhttp.HandlerFor(metrics.Registry, promhttp.HandlerOpts{})))
+
+	tasks := background.NewTasks()
+	policy, err := auth.LoadPolicy(cfg.Auth.PolicyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	limitSvc := services.NewLimitService(deps.Limits, deps.Volumes, cfg.Limits)
+	limitHandler := handlers.NewLimitHandler(limitSvc, policy)
+
+	risk := services.NewRiskEngine(cfg.Risk, deps.Transactions)
+	auditSvc := services.NewAuditService(deps.Audit, logger)
+	svc := services.NewTransactionService(deps.Transactions, deps.Ledger, deps.Settlements, risk, limitSvc, auditSvc, deps.Events, deps.Balances, tasks, logger)
+	handler := handlers.NewTransactionHandler(svc, policy)
+
+	disputeSvc := services.NewDisputeService(deps.Disputes, deps.Transactions, deps.Balances, deps.Settlements, tasks, logger)
+	disputeHandler := handlers.NewDisputeHandler(disputeSvc, policy)
+
+	reviewSvc := services.NewReviewService(deps.Reviews, deps.Transactions, svc, limitSvc, deps.Notifications, tasks, logger)
+	reviewHandler := handlers.NewReviewHandler(reviewSvc, policy)
+
+	adjustmentSvc := services.NewAdjustmentService(deps.Adjustments, deps.Transactions, svc, logger)
+	adjustmentHandler := handlers.NewAdjustmentHandler(adjustmentSvc, policy)
 
-	cfg := config.Load(serviceName, "7004")
+	apiKeySvc := services.NewAPIKeyService(deps.APIKeys, cfg.Auth, tasks, logger)
+	apiKeyHandler := handlers.NewAPIKeyHandler(apiKeySvc, policy)
 
-	repo, err := repositories.NewTransactionRepository(cfg.PostgresDSN)
+	metrics.RegisterBacklog("reviews", func(ctx context.Context) (int64, error) {
+		return deps.Reviews.CountByStatus(ctx, models.ReviewStatusPending, models.ReviewStatusClaimed)
+	})
+	metrics.RegisterBacklog("erasures", func(ctx context.Context) (int64, error) {
+		return deps.Erasures.CountByStatus(ctx, models.ErasureStatusPending, models.ErasureStatusRunning)
+	})
+	metrics.RegisterBacklog("settlement_merchants", deps.Settlements.PendingCount)
+	metrics.RegisterBacklog("notifications", deps.Notifications.Backlog)
+
+	rateLimitSvc := services.NewRateLimitService(deps.RateLimits, cfg.RateLimit)
+
+	privacySvc := services.NewPrivacyService(deps.Erasures, deps.Transactions, cfg.Privacy.ErasureInterval, logger)
+	privacyHandler := handlers.NewPrivacyHandler(privacySvc, policy)
+	tasks.Go(func() { privacySvc.Run(tasks.Context()) })
+
+	jwtVerifier, err := auth.NewJWTVerifier(cfg.Auth.JWT)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 
-	// Initialize settlement event publisher
-	publisher := queue.NewSettlementPublisher()
+	checks := append(append([]handlers.HealthCheck(nil), deps.Checks...),
+		handlers.HealthCheck{Name: "outbox", Check: func(ctx context.Context) error {
+			if cfg.Health.MaxOutboxBacklog <= 0 {
+				return nil
+			}
+			n, err := deps.Notifications.Backlog(ctx)
+			if err != nil {
+				return err
+			}
+			if n > int64(cfg.Health.MaxOutboxBacklog) {
+				return fmt.Errorf("%d notifications waiting, limit is %d", n, cfg.Health.MaxOutboxBacklog)
+			}
+			return nil
+		}},
+	)
+	health := handlers.NewHealthHandler(cfg.ServiceName, cfg.Health.CheckTimeout, checks...)
+	health.Register(app)
 
-	svc := services.NewTransactionService(repo, publisher)
-	handler := handlers.NewTransactionHandler(svc)
+	// Everything registered below requires a merchant API key, a signed JWT or internal credentials.
+	app.Use(middleware.Authenticate(apiKeySvc, jwtVerifier))
+	app.Use(middleware.RateLimit(rateLimitSvc))
+	internalOnly := middleware.RequireInternal()
 
 	app.Get("/transactions", handler.List)
 	app.Post("/transactions", handler.Create)
 	app.Get("/transactions/:reference", handler.Get)
 	app.Post("/transactions/:reference/capture", handler.Capture)
 	app.Post("/transactions/:reference/refund", handler.Refund)
+	app.Post("/transactions/:reference/void", handler.Void)
+	app.Get("/transactions/:reference/history", handler.History)
+	app.Post("/payouts", handler.CreatePayout)
+	app.Post("/transactions/:reference/disputes", internalOnly, disputeHandler.Create)
+
+	app.Get("/disputes", disputeHandler.List)
+	app.Get("/disputes/:id", disputeHandler.Get)
+	app.Patch("/disputes/:id", disputeHandler.Update)
+	app.Post("/disputes/:id/evidence", disputeHandler.AddEvidence)
+
+	app.Get("/api-keys", apiKeyHandler.List)
+	app.Post("/api-keys/rotate", apiKeyHandler.Rotate)
+	app.Delete("/api-keys/:id", apiKeyHandler.Revoke)
+
+	reviews := app.Group("/reviews", internalOnly)
+	reviews.Get("/", reviewHandler.List)
+	reviews.Get("/:id", reviewHandler.Get)
+	reviews.Post("/:id/claim", reviewHandler.Claim)
+	reviews.Post("/:id/approve", reviewHandler.Approve)
+	reviews.Post("/:id/decline", reviewHandler.Decline)
+
+	adjustments := app.Group("/adjustments", internalOnly)
+	adjustments.Get("/", adjustmentHandler.List)
+	adjustments.Post("/", adjustmentHandler.Create)
+	adjustments.Get("/:id", adjustmentHandler.Get)
+	adjustments.Post("/:id/approve", adjustmentHandler.Approve)
+	adjustments.Post("/:id/reject", adjustmentHandler.Reject)
+
+	internal := app.Group("/internal", internalOnly)
+	internal.Post("/transactions/:reference/events", handler.ReportStatus)
+
+	admin := app.Group("/admin", internalOnly)
+	admin.Get("/merchants/:merchant_id/limits", limitHandler.List)
+	admin.Put("/merchants/:merchant_id/limits", limitHandler.Set)
+	admin.Get("/merchants/:merchant_id/api-keys", apiKeyHandler.List)
+	admin.Post("/merchants/:merchant_id/api-keys", apiKeyHandler.Create)
+	admin.Patch("/transactions/:reference", handler.Update)
+	admin.Post("/pii/reencrypt", handler.ReencryptPII)
+	admin.Get("/customers/export", privacyHandler.Export)
+	admin.Post("/erasures", privacyHandler.RequestErasure)
+	admin.Get("/erasures/:id", privacyHandler.GetErasure)
+
+	return func(ctx context.Context) error {
+		err := tasks.Shutdown(ctx)
+		if err != nil {
+			logger.Warn("background tasks still running at shutdown deadline", "error", err)
+		}
+		if deps.Close != nil {
+			if cerr := deps.Close(); cerr != nil {
+				logger.Warn("failed to close connections", "error", cerr)
+			}
+		}
+		return err
+	}, nil
 }
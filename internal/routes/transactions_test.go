@@ -0,0 +1,446 @@
+package routes_test
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/kodra-pay/transaction-service/internal/dto"
+	"github.com/kodra-pay/transaction-service/internal/models"
+)
+
+func TestCreateGetAndList(t *testing.T) {
+	h := newHarness(t)
+	live := h.apiKey(12, "live")
+	test := h.apiKey(12, "test")
+	other := h.apiKey(15, "live")
+
+	first := h.create(live, dto.TransactionCreateRequest{Reference: "ord-1", Amount: 150.25, Currency: "NGN", CustomerEmail: "ada@example.com"})
+	if first.MerchantID != 12 || first.Status != models.TransactionStatusSuccess || !first.Livemode {
+		t.Fatalf("created %+v, want a live success for merchant 12", first)
+	}
+	h.create(live, dto.TransactionCreateRequest{Reference: "ord-2", Amount: 20, Currency: "NGN"})
+	h.create(test, dto.TransactionCreateRequest{Reference: "ord-test", Amount: 5, Currency: "NGN"})
+
+	var got dto.TransactionResponse
+	if code := h.do(http.MethodGet, "/transactions/ord-1", live, nil, &got); code != http.StatusOK {
+		t.Fatalf("get: status %d", code)
+	}
+	if got.ID != first.ID || got.Amount != 150.25 || got.Currency != "NGN" || got.CustomerEmail != "ada@example.com" {
+		t.Errorf("get returned %+v, want %+v", got, first)
+	}
+	if code := h.do(http.MethodGet, "/transactions/missing", live, nil, nil); code != http.StatusNotFound {
+		t.Errorf("get unknown reference: status %d, want 404", code)
+	}
+	if code := h.do(http.MethodGet, "/transactions/ord-1", other, nil, nil); code != http.StatusNotFound {
+		t.Errorf("get another merchant's transaction: status %d, want 404", code)
+	}
+
+	var list dto.TransactionListResponse
+	if code := h.do(http.MethodGet, "/transactions", live, nil, &list); code != http.StatusOK {
+		t.Fatalf("list: status %d", code)
+	}
+	var refs []string
+	for _, tx := range list.Transactions {
+		refs = append(refs, tx.Reference)
+	}
+	if len(refs) != 2 || refs[0] != "ord-2" || refs[1] != "ord-1" {
+		t.Errorf("live key listed %v, want [ord-2 ord-1]", refs)
+	}
+
+	list = dto.TransactionListResponse{}
+	h.do(http.MethodGet, "/transactions", test, nil, &list)
+	if len(list.Transactions) != 1 || list.Transactions[0].Reference != "ord-test" {
+		t.Errorf("test key listed %+v, want only ord-test", list.Transactions)
+	}
+
+	list = dto.TransactionListResponse{}
+	h.do(http.MethodGet, "/transactions?limit=1", live, nil, &list)
+	if len(list.Transactions) != 1 {
+		t.Errorf("limit=1 listed %d transactions", len(list.Transactions))
+	}
+
+	list = dto.TransactionListResponse{}
+	h.do(http.MethodGet, "/transactions", other, nil, &list)
+	if len(list.Transactions) != 0 {
+		t.Errorf("merchant 15 listed %+v, want nothing", list.Transactions)
+	}
+}
+
+func TestListLimit(t *testing.T) {
+	h := newHarness(t)
+	key := h.apiKey(12, "live")
+	for i := 0; i < 101; i++ {
+		h.create(key, dto.TransactionCreateRequest{Reference: fmt.Sprintf("ord-%d", i), Amount: 1, Currency: "NGN"})
+	}
+
+	var list dto.TransactionListResponse
+	if code := h.do(http.MethodGet, "/transactions?limit=1000", key, nil, &list); code != http.StatusOK || len(list.Transactions) != 100 {
+		t.Errorf("limit=1000: status %d with %d transactions, want 100", code, len(list.Transactions))
+	}
+	for _, path := range []string{"/transactions", "/disputes", "/reviews", "/adjustments"} {
+		if code, e := h.fail(http.MethodGet, path+"?limit=-1", internalToken, nil); code != http.StatusBadRequest || len(e.Fields) != 1 || e.Fields[0].Field != "limit" {
+			t.Errorf("%s?limit=-1: got %d %+v, want 400 on limit", path, code, e)
+		}
+	}
+}
+
+func TestCaptureAndRefund(t *testing.T) {
+	h := newHarness(t)
+	key := h.apiKey(12, "live")
+
+	tx := h.create(key, dto.TransactionCreateRequest{Reference: "auth-1", Amount: 250.50, Currency: "NGN", Status: models.TransactionStatusPending})
+	if tx.Status != models.TransactionStatusPending {
+		t.Fatalf("created with status %q, want pending", tx.Status)
+	}
+	if entries := h.ledgerEntries(12); len(entries) != 0 {
+		t.Fatalf("pending transaction reached the ledger: %+v", entries)
+	}
+	if code := h.do(http.MethodPost, "/transactions/auth-1/refund", key, nil, nil); code != http.StatusConflict {
+		t.Errorf("refund before capture: status %d, want 409", code)
+	}
+
+	var captured dto.TransactionResponse
+	if code := h.do(http.MethodPost, "/transactions/auth-1/capture", key, nil, &captured); code != http.StatusOK {
+		t.Fatalf("capture: status %d", code)
+	}
+	if captured.Status != models.TransactionStatusSuccess {
+		t.Fatalf("captured status %q, want success", captured.Status)
+	}
+	if code := h.do(http.MethodPost, "/transactions/auth-1/capture", key, nil, nil); code != http.StatusConflict {
+		t.Errorf("second capture: status %d, want 409", code)
+	}
+
+	entries := h.ledgerEntries(12)
+	if len(entries) != 1 {
+		t.Fatalf("got %d ledger entries after capture, want 1", len(entries))
+	}
+	credit := entries[0]
+	if credit.EntryType != models.LedgerEntryCredit || credit.Amount != 25050 || credit.BalanceAfter != 25050 ||
+		credit.Reference != "auth-1" || credit.TransactionID != tx.ID || credit.Currency != "NGN" {
+		t.Errorf("capture posted %+v, want a 25050 NGN credit for auth-1", credit)
+	}
+	settled := h.waitForSettlements(1)
+	if settled[0].MerchantID != 12 || settled[0].Amount != 25050 || settled[0].TransactionID != tx.ID {
+		t.Errorf("capture published %+v", settled[0])
+	}
+
+	var refunded dto.TransactionResponse
+	if code := h.do(http.MethodPost, "/transactions/auth-1/refund", key, nil, &refunded); code != http.StatusOK {
+		t.Fatalf("refund: status %d", code)
+	}
+	if refunded.Status != models.TransactionStatusRefunded {
+		t.Fatalf("refunded status %q", refunded.Status)
+	}
+	if code := h.do(http.MethodPost, "/transactions/auth-1/refund", key, nil, nil); code != http.StatusConflict {
+		t.Errorf("second refund: status %d, want 409", code)
+	}
+
+	entries = h.ledgerEntries(12)
+	if len(entries) != 2 {
+		t.Fatalf("got %d ledger entries after refund, want 2", len(entries))
+	}
+	if debit := entries[1]; debit.EntryType != models.LedgerEntryDebit || debit.Amount != 25050 || debit.BalanceAfter != 0 {
+		t.Errorf("refund posted %+v, want a 25050 debit back to 0", debit)
+	}
+	settled = h.waitForSettlements(2)
+	if settled[1].Amount != -25050 {
+		t.Errorf("refund published %+v, want -25050", settled[1])
+	}
+	if pending := h.settlements.Pending(12); pending != 0 {
+		t.Errorf("merchant 12 has %d unsettled after a full refund", pending)
+	}
+
+	var timeline dto.TransactionResponse
+	h.do(http.MethodGet, "/transactions/auth-1?include=timeline", key, nil, &timeline)
+	var statuses []string
+	for _, ev := range timeline.Timeline {
+		statuses = append(statuses, ev.ToStatus)
+	}
+	if len(statuses) != 3 || statuses[0] != "pending" || statuses[1] != "success" || statuses[2] != "refunded" {
+		t.Errorf("timeline %v, want [pending success refunded]", statuses)
+	}
+}
+
+func TestVoidReleasesWithoutPosting(t *testing.T) {
+	h := newHarness(t)
+	key := h.apiKey(12, "live")
+
+	h.create(key, dto.TransactionCreateRequest{Reference: "void-1", Amount: 10, Currency: "NGN", Status: models.TransactionStatusAuthorized})
+	var voided dto.TransactionResponse
+	if code := h.do(http.MethodPost, "/transactions/void-1/void", key, nil, &voided); code != http.StatusOK {
+		t.Fatalf("void: status %d", code)
+	}
+	if voided.Status != models.TransactionStatusVoided {
+		t.Errorf("voided status %q", voided.Status)
+	}
+	if code := h.do(http.MethodPost, "/transactions/void-1/capture", key, nil, nil); code != http.StatusConflict {
+		t.Errorf("capture after void: status %d, want 409", code)
+	}
+	if entries := h.ledgerEntries(12); len(entries) != 0 {
+		t.Errorf("void posted %+v", entries)
+	}
+	h.waitForSettlements(0)
+}
+
+func TestTestModeSkipsLedgerAndSettlement(t *testing.T) {
+	h := newHarness(t)
+	key := h.apiKey(12, "test")
+
+	h.create(key, dto.TransactionCreateRequest{Reference: "sandbox-1", Amount: 99.99, Currency: "NGN"})
+	if code := h.do(http.MethodPost, "/transactions/sandbox-1/refund", key, nil, nil); code != http.StatusOK {
+		t.Fatalf("refund: status %d", code)
+	}
+	if entries := h.ledgerEntries(12); len(entries) != 0 {
+		t.Errorf("test mode posted %+v", entries)
+	}
+	h.waitForSettlements(0)
+	if updates := h.balances.Updates(); len(updates) != 0 {
+		t.Errorf("test mode updated balances: %+v", updates)
+	}
+}
+
+func TestKeysOnlyReachTheirOwnMode(t *testing.T) {
+	h := newHarness(t)
+	live := h.apiKey(12, "live")
+	test := h.apiKey(12, "test")
+	h.create(live, dto.TransactionCreateRequest{Reference: "live-1", Amount: 50, Currency: "NGN", Status: models.TransactionStatusAuthorized})
+	h.create(test, dto.TransactionCreateRequest{Reference: "sandbox-1", Amount: 50, Currency: "NGN"})
+
+	for _, req := range []struct{ method, path string }{
+		{http.MethodGet, "/transactions/live-1"},
+		{http.MethodGet, "/transactions/live-1/history"},
+		{http.MethodPost, "/transactions/live-1/capture"},
+		{http.MethodPost, "/transactions/live-1/void"},
+		{http.MethodPost, "/transactions/live-1/refund"},
+	} {
+		if code, e := h.fail(req.method, req.path, test, nil); code != http.StatusNotFound || e.Code != "not_found" {
+			t.Errorf("test key %s %s: got %d %s, want 404 not_found", req.method, req.path, code, e.Code)
+		}
+	}
+	if code, _ := h.fail(http.MethodGet, "/transactions/sandbox-1", live, nil); code != http.StatusNotFound {
+		t.Errorf("live key reading a test transaction: status %d, want 404", code)
+	}
+
+	var tx dto.TransactionResponse
+	h.do(http.MethodGet, "/transactions/live-1", live, nil, &tx)
+	if tx.Status != models.TransactionStatusAuthorized {
+		t.Errorf("live transaction is %q after the test key's attempts, want authorized", tx.Status)
+	}
+	if code := h.do(http.MethodGet, "/transactions/live-1", internalToken, nil, nil); code != http.StatusOK {
+		t.Errorf("internal caller reading a live transaction: status %d", code)
+	}
+}
+
+func TestLedgerBalanceAccumulates(t *testing.T) {
+	h := newHarness(t)
+	key := h.apiKey(12, "live")
+	other := h.apiKey(15, "live")
+
+	h.create(key, dto.TransactionCreateRequest{Reference: "a", Amount: 100, Currency: "NGN"})
+	h.create(other, dto.TransactionCreateRequest{Reference: "b", Amount: 7, Currency: "NGN"})
+	h.create(key, dto.TransactionCreateRequest{Reference: "c", Amount: 0.5, Currency: "NGN"})
+
+	entries := h.ledgerEntries(12)
+	if len(entries) != 2 || entries[0].BalanceAfter != 10000 || entries[1].BalanceAfter != 10050 {
+		t.Errorf("merchant 12 ledger %+v, want balances 10000 then 10050", entries)
+	}
+	if entries := h.ledgerEntries(15); len(entries) != 1 || entries[0].BalanceAfter != 700 {
+		t.Errorf("merchant 15 ledger %+v, want one entry with balance 700", entries)
+	}
+	h.waitForSettlements(3)
+	if got := h.settlements.Pending(12); got != 10050 {
+		t.Errorf("merchant 12 unsettled %d, want 10050", got)
+	}
+}
+
+func TestMoneyRounding(t *testing.T) {
+	h := newHarness(t)
+	key := h.apiKey(12, "live")
+
+	cases := []struct {
+		reference string
+		amount    float64
+		minor     int64
+		echoed    float64
+	}{
+		{"round-cents", 150.25, 15025, 150.25},
+		{"one-kobo", 0.01, 1, 0.01},
+		{"float-noise", 0.1 + 0.2, 30, 0.3},
+		{"large", 987654.32, 98765432, 987654.32},
+	}
+	for i, tc := range cases {
+		tx := h.create(key, dto.TransactionCreateRequest{Reference: tc.reference, Amount: tc.amount, Currency: "NGN"})
+		if tx.Amount != tc.echoed {
+			t.Errorf("%s: amount %v echoed as %v, want %v", tc.reference, tc.amount, tx.Amount, tc.echoed)
+		}
+		entries := h.ledgerEntries(12)
+		if len(entries) != i+1 {
+			t.Fatalf("%s: got %d ledger entries, want %d", tc.reference, len(entries), i+1)
+		}
+		if got := entries[i].Amount; got != tc.minor {
+			t.Errorf("%s: amount %v posted as %d minor units, want %d", tc.reference, tc.amount, got, tc.minor)
+		}
+	}
+}
+
+func TestValidationErrors(t *testing.T) {
+	h := newHarness(t)
+	key := h.apiKey(12, "live")
+	h.create(key, dto.TransactionCreateRequest{Reference: "dup", Amount: 1, Currency: "NGN"})
+
+	cases := []struct {
+		name       string
+		credential string
+		body       interface{}
+		want       int
+		code       string
+	}{
+		{"malformed json", key, `{"amount": `, http.StatusBadRequest, "invalid_request"},
+		{"wrong type", key, `{"amount": "ten"}`, http.StatusBadRequest, "invalid_request"},
+		{"missing amount", key, dto.TransactionCreateRequest{Reference: "no-amount", Currency: "NGN"}, http.StatusBadRequest, "validation_failed"},
+		{"negative amount", key, dto.TransactionCreateRequest{Reference: "negative", Amount: -5, Currency: "NGN"}, http.StatusBadRequest, "validation_failed"},
+		{"other merchant", key, dto.TransactionCreateRequest{Reference: "spoof", MerchantID: 15, Amount: 5, Currency: "NGN"}, http.StatusForbidden, "forbidden"},
+		{"internal without merchant", internalToken, dto.TransactionCreateRequest{Reference: "orphan", Amount: 5, Currency: "NGN"}, http.StatusBadRequest, "validation_failed"},
+		{"no credentials", "", dto.TransactionCreateRequest{Reference: "anon", Amount: 5, Currency: "NGN"}, http.StatusUnauthorized, "unauthenticated"},
+		{"bad credentials", "kp_live_nope", dto.TransactionCreateRequest{Reference: "forged", Amount: 5, Currency: "NGN"}, http.StatusUnauthorized, "unauthenticated"},
+		{"over single limit", key, dto.TransactionCreateRequest{Reference: "huge", Amount: 2000000, Currency: "NGN"}, http.StatusUnprocessableEntity, "limit_exceeded"},
+		{"duplicate reference", key, dto.TransactionCreateRequest{Reference: "dup", Amount: 1, Currency: "NGN"}, http.StatusConflict, "duplicate_reference"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			code, e := h.fail(http.MethodPost, "/transactions", tc.credential, tc.body)
+			if code != tc.want || e.Code != tc.code {
+				t.Errorf("got %d %s, want %d %s: %+v", code, e.Code, tc.want, tc.code, e)
+			}
+			if e.Message == "" || e.RequestID == "" {
+				t.Errorf("error envelope without message or request ID: %+v", e)
+			}
+		})
+	}
+
+	code, e := h.fail(http.MethodPost, "/transactions", key, dto.TransactionCreateRequest{Reference: "fields", Amount: -1, Currency: "NGN"})
+	if code != http.StatusBadRequest || len(e.Fields) != 1 || e.Fields[0].Field != "amount" {
+		t.Errorf("negative amount: got %d %+v, want a field error on amount", code, e)
+	}
+	if code, e := h.fail(http.MethodPost, "/transactions/missing/capture", key, nil); code != http.StatusNotFound || e.Code != "not_found" {
+		t.Errorf("capture unknown reference: got %d %s, want 404 not_found", code, e.Code)
+	}
+	if code, e := h.fail(http.MethodGet, "/no-such-route", key, nil); code != http.StatusNotFound || e.Code != "not_found" {
+		t.Errorf("unknown route: got %d %s, want 404 not_found", code, e.Code)
+	}
+	if entries := h.ledgerEntries(12); len(entries) != 1 {
+		t.Errorf("rejected requests posted to the ledger: %+v", entries)
+	}
+}
+
+func TestCreateFieldValidation(t *testing.T) {
+	h := newHarness(t)
+	key := h.apiKey(12, "live")
+
+	cases := []struct {
+		name   string
+		change func(*dto.TransactionCreateRequest)
+		field  string
+	}{
+		{"bad email", func(r *dto.TransactionCreateRequest) { r.CustomerEmail = "not-an-email" }, "customer_email"},
+		{"lower-case currency", func(r *dto.TransactionCreateRequest) { r.Currency = "ngn" }, "currency"},
+		{"missing currency", func(r *dto.TransactionCreateRequest) { r.Currency = "" }, "currency"},
+		{"unknown payment method", func(r *dto.TransactionCreateRequest) { r.PaymentMethod = "cheque" }, "payment_method"},
+		{"long description", func(r *dto.TransactionCreateRequest) { r.Description = strings.Repeat("x", 501) }, "description"},
+		{"negative customer", func(r *dto.TransactionCreateRequest) { r.CustomerID = -3 }, "customer_id"},
+		{"payout status", func(r *dto.TransactionCreateRequest) { r.Status = "payout" }, "status"},
+		{"refunded status", func(r *dto.TransactionCreateRequest) { r.Status = models.TransactionStatusRefunded }, "status"},
+		{"bad card bin", func(r *dto.TransactionCreateRequest) { r.CardBIN = "41x111" }, "card_bin"},
+		{"long reference", func(r *dto.TransactionCreateRequest) { r.Reference = strings.Repeat("r", 101) }, "reference"},
+	}
+	for i, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := dto.TransactionCreateRequest{Reference: fmt.Sprintf("v-%d", i), Amount: 10, Currency: "NGN"}
+			tc.change(&req)
+			code, e := h.fail(http.MethodPost, "/transactions", key, req)
+			if code != http.StatusBadRequest || e.Code != "validation_failed" || len(e.Fields) != 1 || e.Fields[0].Field != tc.field {
+				t.Errorf("got %d %+v, want a single %s field error", code, e, tc.field)
+			}
+		})
+	}
+
+	// The reference is optional.
+	if code := h.do(http.MethodPost, "/transactions", key, dto.TransactionCreateRequest{Amount: 10, Currency: "NGN"}, nil); code != http.StatusCreated {
+		t.Errorf("create without a reference: status %d, want 201", code)
+	}
+
+	// Every invalid field is reported at once.
+	_, e := h.fail(http.MethodPost, "/transactions", key, dto.TransactionCreateRequest{Reference: "many", Amount: -1, Currency: "naira", CustomerEmail: "@"})
+	if len(e.Fields) != 3 {
+		t.Errorf("got fields %+v, want amount, currency and customer_email", e.Fields)
+	}
+	if entries := h.ledgerEntries(12); len(entries) != 1 {
+		t.Errorf("invalid requests posted to the ledger: %+v", entries)
+	}
+}
+
+func TestCreateWithoutReference(t *testing.T) {
+	h := newHarness(t)
+	key := h.apiKey(12, "live")
+
+	first := h.create(key, dto.TransactionCreateRequest{Amount: 10, Currency: "NGN"})
+	second := h.create(key, dto.TransactionCreateRequest{Amount: 20, Currency: "NGN"})
+	if first.Reference == "" || second.Reference == "" || first.Reference == second.Reference {
+		t.Fatalf("got references %q and %q, want two distinct generated ones", first.Reference, second.Reference)
+	}
+	for _, want := range []dto.TransactionResponse{first, second} {
+		var got dto.TransactionResponse
+		if code := h.do(http.MethodGet, "/transactions/"+want.Reference, key, nil, &got); code != http.StatusOK || got.ID != want.ID {
+			t.Errorf("get %s: status %d, transaction %d, want 200 and %d", want.Reference, code, got.ID, want.ID)
+		}
+	}
+}
+
+func TestAmountBounds(t *testing.T) {
+	h := newHarness(t)
+	key := h.apiKey(12, "live")
+	h.create(key, dto.TransactionCreateRequest{Reference: "sale-1", Amount: 100, Currency: "NGN"})
+
+	requests := []struct {
+		name       string
+		path       string
+		credential string
+		body       func(amount float64) interface{}
+	}{
+		{"transaction", "/transactions", key, func(a float64) interface{} {
+			return dto.TransactionCreateRequest{Reference: "amt", Amount: a, Currency: "NGN"}
+		}},
+		{"payout", "/payouts", key, func(a float64) interface{} {
+			return dto.PayoutCreateRequest{Reference: "amt", Amount: a, Currency: "NGN"}
+		}},
+		{"adjustment", "/adjustments", internalToken, func(a float64) interface{} {
+			return dto.AdjustmentCreateRequest{Reference: "amt", MerchantID: 12, Direction: "credit", Amount: a, Currency: "NGN", Reason: "Goodwill credit"}
+		}},
+		{"dispute", "/transactions/sale-1/disputes", internalToken, func(a float64) interface{} {
+			return dto.DisputeCreateRequest{Amount: a, ReasonCode: "fraud"}
+		}},
+	}
+	amounts := []struct {
+		name   string
+		amount float64
+	}{
+		{"below one kobo", 0.001},
+		{"beyond int64 in kobo", 1e17},
+		{"three decimal places", 1.005},
+	}
+	for _, req := range requests {
+		for _, amt := range amounts {
+			t.Run(req.name+"/"+amt.name, func(t *testing.T) {
+				code, e := h.fail(http.MethodPost, req.path, req.credential, req.body(amt.amount))
+				if code != http.StatusBadRequest || e.Code != "validation_failed" || len(e.Fields) != 1 || e.Fields[0].Field != "amount" {
+					t.Errorf("amount %v: got %d %+v, want a single amount field error", amt.amount, code, e)
+				}
+			})
+		}
+	}
+	if entries := h.ledgerEntries(12); len(entries) != 1 {
+		t.Errorf("rejected amounts posted to the ledger: %+v", entries)
+	}
+}
@@ -0,0 +1,223 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"math"
+
+	"github.com/kodra-pay/transaction-service/internal/apperr"
+	"github.com/kodra-pay/transaction-service/internal/auth"
+	"github.com/kodra-pay/transaction-service/internal/dto"
+	"github.com/kodra-pay/transaction-service/internal/models"
+)
+
+var (
+	ErrAdjustmentNotFound     = apperr.New(apperr.CodeNotFound, "adjustment not found")
+	ErrAdjustmentNotPending   = apperr.New(apperr.CodeInvalidStateTransition, "adjustment is not pending")
+	ErrAdjustmentSelfDecision = apperr.Forbidden("an adjustment must be decided by an operator other than the one who proposed it")
+	ErrOperatorRequired       = apperr.Forbidden("adjustments require credentials that identify the operator")
+)
+
+// AdjustmentService handles manual credits and debits of merchant balances
+// under maker-checker control: one operator proposes an adjustment and it
+// only reaches the ledger and settlement once a different operator approves
+// it. Operators are identified by their credentials' subject, never by the
+// request body.
+type AdjustmentService struct {
+	repo   AdjustmentStore
+	txRepo TransactionStore
+	txSvc  *TransactionService
+	log    *slog.Logger
+}
+
+func NewAdjustmentService(repo AdjustmentStore, txRepo TransactionStore, txSvc *TransactionService, logger *slog.Logger) *AdjustmentService {
+	return &AdjustmentService{repo: repo, txRepo: txRepo, txSvc: txSvc, log: logger}
+}
+
+// Propose records an adjustment awaiting approval. Its transaction is created
+// pending, so the proposal shows in the merchant's history, but nothing is
+// posted yet.
+func (s *AdjustmentService) Propose(ctx context.Context, req dto.AdjustmentCreateRequest) (dto.AdjustmentResponse, error) {
+	operator, err := operatorFrom(ctx)
+	if err != nil {
+		return dto.AdjustmentResponse{}, err
+	}
+	tx := &models.Transaction{
+		Reference:    req.Reference,
+		MerchantID:   req.MerchantID,
+		Type:         models.TransactionTypeAdjustment,
+		Amount:       int64(math.Round(req.Amount * 100)),
+		Currency:     req.Currency,
+		Status:       models.TransactionStatusPending,
+		Description:  req.Reason,
+		RiskDecision: models.RiskDecisionAllow,
+		Livemode:     auth.FromContext(ctx).Livemode(),
+	}
+	adj := &models.Adjustment{
+		MerchantID: tx.MerchantID,
+		Direction:  req.Direction,
+		Amount:     tx.Amount,
+		Currency:   tx.Currency,
+		Reason:     req.Reason,
+		Status:     models.AdjustmentStatusPending,
+		ProposedBy: operator,
+	}
+	ev := newTransactionEvent(models.EventSourceAPI, "", map[string]interface{}{
+		"direction":   adj.Direction,
+		"proposed_by": operator,
+	})
+	if err := s.txRepo.CreateAdjustment(ctx, tx, ev, adj); err != nil {
+		return dto.AdjustmentResponse{}, err
+	}
+	s.txSvc.audit.Record(ctx, models.AuditActionAdjustmentPropose, nil, tx)
+	return toAdjustmentResponse(adj, tx), nil
+}
+
+func (s *AdjustmentService) List(ctx context.Context, merchantID int, status string, limit int) (dto.AdjustmentListResponse, error) {
+	list, err := s.repo.List(ctx, merchantID, status, limit)
+	if err != nil {
+		return dto.AdjustmentListResponse{}, err
+	}
+	res := dto.AdjustmentListResponse{Adjustments: []dto.AdjustmentResponse{}}
+	for _, adj := range list {
+		tx, err := s.txRepo.GetByID(ctx, adj.TransactionID)
+		if err != nil {
+			return dto.AdjustmentListResponse{}, err
+		}
+		res.Adjustments = append(res.Adjustments, toAdjustmentResponse(adj, tx))
+	}
+	res.Total = len(res.Adjustments)
+	return res, nil
+}
+
+func (s *AdjustmentService) Get(ctx context.Context, id int) (dto.AdjustmentResponse, error) {
+	adj, tx, err := s.load(ctx, id)
+	if err != nil {
+		return dto.AdjustmentResponse{}, err
+	}
+	return toAdjustmentResponse(adj, tx), nil
+}
+
+// Approve posts the adjustment: its transaction succeeds, the ledger entry is
+// written with it, and the movement goes to the merchant balance and the
+// settlement queue. Debits are posted even if they take the balance below
+// zero, since correcting an over-credit is what they are for.
+func (s *AdjustmentService) Approve(ctx context.Context, id int, req dto.AdjustmentDecisionRequest) (dto.AdjustmentResponse, error) {
+	adj, tx, err := s.decide(ctx, id, req, models.AuditActionAdjustmentApprove, models.AdjustmentStatusApproved, models.TransactionStatusSuccess)
+	if err != nil {
+		return dto.AdjustmentResponse{}, err
+	}
+	if tx.Livemode {
+		amount := adj.Amount
+		if adj.Direction == models.LedgerEntryDebit {
+			amount = -amount
+		}
+		s.txSvc.applyBalanceChange(ctx, tx, amount)
+	}
+	return toAdjustmentResponse(adj, tx), nil
+}
+
+// Reject closes the adjustment without posting anything; its transaction fails.
+func (s *AdjustmentService) Reject(ctx context.Context, id int, req dto.AdjustmentDecisionRequest) (dto.AdjustmentResponse, error) {
+	adj, tx, err := s.decide(ctx, id, req, models.AuditActionAdjustmentReject, models.AdjustmentStatusRejected, models.TransactionStatusFailed)
+	if err != nil {
+		return dto.AdjustmentResponse{}, err
+	}
+	return toAdjustmentResponse(adj, tx), nil
+}
+
+func (s *AdjustmentService) decide(ctx context.Context, id int, req dto.AdjustmentDecisionRequest, action, adjStatus, txStatus string) (*models.Adjustment, *models.Transaction, error) {
+	operator, err := operatorFrom(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	adj, tx, err := s.load(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if adj.Status != models.AdjustmentStatusPending {
+		return nil, nil, ErrAdjustmentNotPending
+	}
+	if adj.ProposedBy == operator {
+		return nil, nil, ErrAdjustmentSelfDecision
+	}
+
+	before := *tx
+	adj.Status = adjStatus
+	adj.DecidedBy = operator
+	adj.DecisionNotes = req.Notes
+	tx.Status = txStatus
+	ev := newTransactionEvent(models.EventSourceAPI, before.Status, map[string]interface{}{
+		"adjustment_id": adj.ID,
+		"proposed_by":   adj.ProposedBy,
+		"decided_by":    operator,
+	})
+	// Test-mode adjustments never touch the ledger.
+	var entry *models.LedgerEntry
+	if adjStatus == models.AdjustmentStatusApproved && tx.Livemode {
+		entry = &models.LedgerEntry{
+			MerchantID:    tx.MerchantID,
+			TransactionID: tx.ID,
+			EntryType:     adj.Direction,
+			Amount:        adj.Amount,
+			Currency:      adj.Currency,
+			Description:   "Manual adjustment: " + adj.Reason,
+			Reference:     tx.Reference,
+		}
+	}
+	ok, err := s.repo.Decide(ctx, adj, tx, ev, entry)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !ok {
+		return nil, nil, ErrAdjustmentNotPending
+	}
+	s.txSvc.audit.Record(ctx, action, &before, tx)
+	return adj, tx, nil
+}
+
+func (s *AdjustmentService) load(ctx context.Context, id int) (*models.Adjustment, *models.Transaction, error) {
+	adj, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if adj == nil {
+		return nil, nil, ErrAdjustmentNotFound
+	}
+	tx, err := s.txRepo.GetByID(ctx, adj.TransactionID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if tx == nil {
+		return nil, nil, ErrTransactionNotFound
+	}
+	return adj, tx, nil
+}
+
+// operatorFrom returns who is acting on an adjustment: the subject of the
+// caller's credentials.
+func operatorFrom(ctx context.Context) (string, error) {
+	p := auth.FromContext(ctx)
+	if p == nil || p.Subject == "" {
+		return "", ErrOperatorRequired
+	}
+	return p.Subject, nil
+}
+
+func toAdjustmentResponse(adj *models.Adjustment, tx *models.Transaction) dto.AdjustmentResponse {
+	resp := dto.AdjustmentResponse{
+		ID:            adj.ID,
+		Direction:     adj.Direction,
+		Reason:        adj.Reason,
+		Status:        adj.Status,
+		ProposedBy:    adj.ProposedBy,
+		DecidedBy:     adj.DecidedBy,
+		DecisionNotes: adj.DecisionNotes,
+		DecidedAt:     adj.DecidedAt,
+		CreatedAt:     adj.CreatedAt,
+	}
+	if tx != nil {
+		resp.Transaction = toTransactionResponse(tx)
+	}
+	return resp
+}
@@ -0,0 +1,187 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/kodra-pay/transaction-service/internal/apperr"
+	"github.com/kodra-pay/transaction-service/internal/auth"
+	"github.com/kodra-pay/transaction-service/internal/background"
+	"github.com/kodra-pay/transaction-service/internal/config"
+	"github.com/kodra-pay/transaction-service/internal/dto"
+	"github.com/kodra-pay/transaction-service/internal/models"
+)
+
+// apiKeyPrefix starts every merchant key, followed by the mode: kp_live_..., kp_test_...
+const apiKeyPrefix = "kp_"
+
+var (
+	ErrInvalidCredentials = apperr.New(apperr.CodeUnauthenticated, "invalid or expired credentials")
+	ErrInvalidAPIKeyMode  = apperr.Invalid("mode", "must be test or live")
+	ErrAPIKeyNotFound     = apperr.New(apperr.CodeNotFound, "api key not found")
+)
+
+type APIKeyService struct {
+	repo           APIKeyStore
+	internalHashes [][]byte
+	rotationGrace  time.Duration
+	tasks          *background.Tasks
+	log            *slog.Logger
+}
+
+func NewAPIKeyService(repo APIKeyStore, cfg config.AuthConfig, tasks *background.Tasks, logger *slog.Logger) *APIKeyService {
+	s := &APIKeyService{repo: repo, rotationGrace: cfg.KeyRotationGrace, tasks: tasks, log: logger}
+	for _, token := range cfg.InternalTokens {
+		sum := sha256.Sum256([]byte(token))
+		s.internalHashes = append(s.internalHashes, sum[:])
+	}
+	return s
+}
+
+// internalSubject names the caller holding an internal token by a short hash
+// of the token, so each service is its own actor in audit entries, reviews
+// and maker-checker decisions without the token itself being logged.
+func internalSubject(hash []byte) string {
+	return "internal:" + hex.EncodeToString(hash[:6])
+}
+
+// Authenticate resolves a bearer credential to the calling principal. Merchant
+// keys are looked up by hash; anything else is checked against the internal tokens.
+func (s *APIKeyService) Authenticate(ctx context.Context, credential string) (*auth.Principal, error) {
+	sum := sha256.Sum256([]byte(credential))
+
+	if !strings.HasPrefix(credential, apiKeyPrefix) {
+		for _, h := range s.internalHashes {
+			if subtle.ConstantTimeCompare(sum[:], h) == 1 {
+				return &auth.Principal{Kind: auth.KindInternal, Subject: internalSubject(h), Mode: auth.ModeLive, Roles: []string{auth.RoleService}}, nil
+			}
+		}
+		return nil, ErrInvalidCredentials
+	}
+
+	key, err := s.repo.GetByHash(ctx, hex.EncodeToString(sum[:]))
+	if err != nil {
+		return nil, err
+	}
+	if key == nil || !key.Usable(time.Now()) {
+		return nil, ErrInvalidCredentials
+	}
+
+	id := key.ID
+	s.tasks.Go(func() {
+		if err := s.repo.TouchLastUsed(context.Background(), id); err != nil {
+			s.log.WarnContext(ctx, "failed to record api key usage", "api_key_id", id, "error", err)
+		}
+	})
+
+	return &auth.Principal{
+		Kind:       auth.KindAPIKey,
+		Subject:    key.Prefix,
+		MerchantID: key.MerchantID,
+		KeyID:      key.ID,
+		Mode:       key.Mode,
+		Roles:      []string{auth.RoleMerchantOwner},
+	}, nil
+}
+
+// Create issues a new key for the merchant. The plaintext key is only ever
+// returned here.
+func (s *APIKeyService) Create(ctx context.Context, merchantID int, mode string) (dto.APIKeyCreatedResponse, error) {
+	if mode != auth.ModeTest && mode != auth.ModeLive {
+		return dto.APIKeyCreatedResponse{}, ErrInvalidAPIKeyMode
+	}
+	raw, key, err := newAPIKey(merchantID, mode)
+	if err != nil {
+		return dto.APIKeyCreatedResponse{}, err
+	}
+	if err := s.repo.Create(ctx, key); err != nil {
+		return dto.APIKeyCreatedResponse{}, err
+	}
+	return dto.APIKeyCreatedResponse{APIKeyResponse: toAPIKeyResponse(key), Key: raw}, nil
+}
+
+func (s *APIKeyService) List(ctx context.Context, merchantID int) (dto.APIKeyListResponse, error) {
+	keys, err := s.repo.ListByMerchant(ctx, merchantID)
+	if err != nil {
+		return dto.APIKeyListResponse{}, err
+	}
+	res := dto.APIKeyListResponse{Keys: []dto.APIKeyResponse{}}
+	for _, k := range keys {
+		res.Keys = append(res.Keys, toAPIKeyResponse(k))
+	}
+	return res, nil
+}
+
+func (s *APIKeyService) Get(ctx context.Context, id int) (dto.APIKeyResponse, error) {
+	key, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return dto.APIKeyResponse{}, err
+	}
+	if key == nil {
+		return dto.APIKeyResponse{}, ErrAPIKeyNotFound
+	}
+	return toAPIKeyResponse(key), nil
+}
+
+// Rotate replaces the key the caller authenticated with. The old key keeps
+// working for the configured grace period so deployments can switch over.
+func (s *APIKeyService) Rotate(ctx context.Context, p *auth.Principal) (dto.APIKeyCreatedResponse, error) {
+	if p == nil || p.Kind != auth.KindAPIKey {
+		return dto.APIKeyCreatedResponse{}, ErrAPIKeyNotFound
+	}
+	raw, key, err := newAPIKey(p.MerchantID, p.Mode)
+	if err != nil {
+		return dto.APIKeyCreatedResponse{}, err
+	}
+	if err := s.repo.Rotate(ctx, p.KeyID, time.Now().Add(s.rotationGrace), key); err != nil {
+		return dto.APIKeyCreatedResponse{}, err
+	}
+	return dto.APIKeyCreatedResponse{APIKeyResponse: toAPIKeyResponse(key), Key: raw}, nil
+}
+
+// Revoke disables a key immediately. Merchants may only revoke their own keys.
+func (s *APIKeyService) Revoke(ctx context.Context, p *auth.Principal, id int) error {
+	key, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if key == nil || !p.CanAccessMerchant(key.MerchantID) {
+		return ErrAPIKeyNotFound
+	}
+	return s.repo.Revoke(ctx, id)
+}
+
+func newAPIKey(merchantID int, mode string) (string, *models.APIKey, error) {
+	secret := make([]byte, 24)
+	if _, err := rand.Read(secret); err != nil {
+		return "", nil, err
+	}
+	raw := apiKeyPrefix + mode + "_" + hex.EncodeToString(secret)
+	sum := sha256.Sum256([]byte(raw))
+	return raw, &models.APIKey{
+		MerchantID: merchantID,
+		Prefix:     raw[:len(apiKeyPrefix)+len(mode)+9],
+		KeyHash:    hex.EncodeToString(sum[:]),
+		Mode:       mode,
+		Status:     models.APIKeyStatusActive,
+	}, nil
+}
+
+func toAPIKeyResponse(k *models.APIKey) dto.APIKeyResponse {
+	return dto.APIKeyResponse{
+		ID:         k.ID,
+		MerchantID: k.MerchantID,
+		Prefix:     k.Prefix,
+		Mode:       k.Mode,
+		Status:     k.Status,
+		ExpiresAt:  k.ExpiresAt,
+		LastUsedAt: k.LastUsedAt,
+		CreatedAt:  k.CreatedAt,
+	}
+}
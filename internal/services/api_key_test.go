@@ -0,0 +1,45 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/kodra-pay/transaction-service/internal/auth"
+	"github.com/kodra-pay/transaction-service/internal/config"
+)
+
+func TestInternalTokensAreDistinctSubjects(t *testing.T) {
+	svc := NewAPIKeyService(nil, config.AuthConfig{InternalTokens: []string{"settlement-token", "ops-token"}}, nil, slog.Default())
+	ctx := context.Background()
+
+	settlement, err := svc.Authenticate(ctx, "settlement-token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ops, err := svc.Authenticate(ctx, "ops-token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []*auth.Principal{settlement, ops} {
+		if !p.IsInternal() || !strings.HasPrefix(p.Subject, "internal:") {
+			t.Errorf("internal token authenticated as %+v", p)
+		}
+		if strings.Contains(p.Subject, "token") {
+			t.Errorf("subject %q reveals the token", p.Subject)
+		}
+	}
+	if settlement.Subject == ops.Subject {
+		t.Errorf("two internal tokens share the subject %q", ops.Subject)
+	}
+	again, _ := svc.Authenticate(ctx, "settlement-token")
+	if again.Subject != settlement.Subject {
+		t.Errorf("subject changed between requests: %q, %q", settlement.Subject, again.Subject)
+	}
+
+	if _, err := svc.Authenticate(ctx, "unknown-token"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("unknown token: %v, want ErrInvalidCredentials", err)
+	}
+}
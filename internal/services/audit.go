@@ -0,0 +1,130 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+
+	"github.com/kodra-pay/transaction-service/internal/auth"
+	"github.com/kodra-pay/transaction-service/internal/dto"
+	"github.com/kodra-pay/transaction-service/internal/models"
+	"github.com/kodra-pay/transaction-service/internal/requestctx"
+)
+
+type AuditService struct {
+	repo AuditStore
+	log  *slog.Logger
+}
+
+func NewAuditService(repo AuditStore, logger *slog.Logger) *AuditService {
+	return &AuditService{repo: repo, log: logger}
+}
+
+// Record appends a change to the audit log, attributing it to the principal
+// and request ID carried by ctx. before is nil for creations. Failures are
+// logged rather than returned: the change itself has already been made.
+func (s *AuditService) Record(ctx context.Context, action string, before, after *models.Transaction) {
+	if s == nil || after == nil {
+		return
+	}
+
+	e := &models.AuditEntry{
+		TransactionID: after.ID,
+		Reference:     after.Reference,
+		Action:        action,
+		ActorKind:     "system",
+		Actor:         "system",
+		RequestID:     requestctx.RequestID(ctx),
+	}
+	if p := auth.FromContext(ctx); p != nil {
+		e.ActorKind = p.Kind
+		e.Actor = p.Subject
+		e.ActorMerchant = p.MerchantID
+	}
+
+	var err error
+	if before != nil {
+		if e.Before, err = s.snapshot(before); err != nil {
+			s.log.ErrorContext(ctx, "failed to encode audit snapshot", "reference", after.Reference, "action", action, "error", err)
+			return
+		}
+	}
+	if e.After, err = s.snapshot(after); err != nil {
+		s.log.ErrorContext(ctx, "failed to encode audit snapshot", "reference", after.Reference, "action", action, "error", err)
+		return
+	}
+
+	if err := s.repo.Append(context.WithoutCancel(ctx), e); err != nil {
+		s.log.ErrorContext(ctx, "failed to record audit entry", "reference", after.Reference, "action", action, "error", err)
+	}
+}
+
+// piiFields are the snapshot fields that identify a customer.
+var piiFields = []string{"customer_email", "customer_name"}
+
+// snapshot encodes tx for the audit log without customer PII. Entries are
+// append-only and hash-chained, so nothing written to them could be erased
+// later; the customer ID still shows whom a change concerned.
+func (s *AuditService) snapshot(tx *models.Transaction) ([]byte, error) {
+	sealed := *tx
+	sealed.CustomerEmail = ""
+	sealed.CustomerName = ""
+	return json.Marshal(&sealed)
+}
+
+// withoutPII drops customer PII from a snapshot written before snapshots
+// left it out. Hashes are checked against the stored snapshot, so this only
+// changes what is shown.
+func withoutPII(snapshot json.RawMessage) json.RawMessage {
+	if len(snapshot) == 0 {
+		return snapshot
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(snapshot, &fields); err != nil {
+		return nil
+	}
+	found := false
+	for _, name := range piiFields {
+		if _, ok := fields[name]; ok {
+			delete(fields, name)
+			found = true
+		}
+	}
+	if !found {
+		return snapshot
+	}
+	redacted, err := json.Marshal(fields)
+	if err != nil {
+		return nil
+	}
+	return redacted
+}
+
+// History returns a transaction's audit entries, oldest first, with each
+// entry's hash re-computed so tampering is visible. Customer PII that older
+// entries still hold is not shown.
+func (s *AuditService) History(ctx context.Context, reference string) (dto.TransactionHistoryResponse, error) {
+	entries, err := s.repo.ListByReference(ctx, reference)
+	if err != nil {
+		return dto.TransactionHistoryResponse{}, err
+	}
+	res := dto.TransactionHistoryResponse{Reference: reference, Entries: []dto.AuditEntryResponse{}, Verified: true}
+	for _, e := range entries {
+		verified := e.ComputeHash() == e.Hash
+		res.Verified = res.Verified && verified
+		res.Entries = append(res.Entries, dto.AuditEntryResponse{
+			ID:        e.ID,
+			Action:    e.Action,
+			ActorKind: e.ActorKind,
+			Actor:     e.Actor,
+			RequestID: e.RequestID,
+			Before:    withoutPII(e.Before),
+			After:     withoutPII(e.After),
+			PrevHash:  e.PrevHash,
+			Hash:      e.Hash,
+			Verified:  verified,
+			CreatedAt: e.CreatedAt,
+		})
+	}
+	return res, nil
+}
@@ -0,0 +1,53 @@
+package services
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/kodra-pay/transaction-service/internal/models"
+)
+
+func TestAuditSnapshotsHoldNoPII(t *testing.T) {
+	s := &AuditService{}
+	tx := &models.Transaction{Reference: "ref-1", CustomerID: 7, CustomerEmail: "ada@example.com", CustomerName: "Ada Lovelace", Amount: 100}
+
+	raw, err := s.snapshot(tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(raw), "ada@example.com") || strings.Contains(string(raw), "Ada Lovelace") {
+		t.Errorf("snapshot holds PII: %s", raw)
+	}
+	if !strings.Contains(string(raw), `"customer_id":7`) {
+		t.Errorf("snapshot lost the customer ID: %s", raw)
+	}
+	if tx.CustomerEmail == "" {
+		t.Error("snapshot cleared the transaction's own email")
+	}
+}
+
+func TestWithoutPII(t *testing.T) {
+	legacy := json.RawMessage(`{"reference":"ref-1","customer_email":"ada@example.com","customer_name":"Ada","amount":100}`)
+	var fields map[string]interface{}
+	if err := json.Unmarshal(withoutPII(legacy), &fields); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := fields["customer_email"]; ok {
+		t.Errorf("customer_email still shown: %v", fields)
+	}
+	if _, ok := fields["customer_name"]; ok {
+		t.Errorf("customer_name still shown: %v", fields)
+	}
+	if fields["reference"] != "ref-1" || fields["amount"] != float64(100) {
+		t.Errorf("other fields changed: %v", fields)
+	}
+
+	clean := json.RawMessage(`{"reference":"ref-1","amount":100}`)
+	if got := withoutPII(clean); string(got) != string(clean) {
+		t.Errorf("snapshot without PII rewritten to %s", got)
+	}
+	if got := withoutPII(nil); got != nil {
+		t.Errorf("empty snapshot became %s", got)
+	}
+}
@@ -0,0 +1,276 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"time"
+
+	"github.com/kodra-pay/transaction-service/internal/apperr"
+	"github.com/kodra-pay/transaction-service/internal/background"
+	"github.com/kodra-pay/transaction-service/internal/dto"
+	"github.com/kodra-pay/transaction-service/internal/models"
+	"github.com/kodra-pay/transaction-service/internal/repositories"
+)
+
+var (
+	ErrTransactionNotCaptured  = apperr.New(apperr.CodeUnprocessable, "only captured transactions can be disputed")
+	ErrDisputeNotFound         = apperr.New(apperr.CodeNotFound, "dispute not found")
+	ErrDisputeAlreadyOpen      = apperr.New(apperr.CodeConflict, "transaction already has an open dispute")
+	ErrDisputeAlreadyLost      = apperr.New(apperr.CodeConflict, "transaction was already charged back by a lost dispute")
+	ErrInvalidDisputeAmount    = apperr.Invalid("amount", "must be positive and not exceed the transaction amount")
+	ErrInvalidDisputeStatus    = apperr.New(apperr.CodeInvalidStateTransition, "invalid dispute status transition")
+	ErrDisputeResolved         = apperr.New(apperr.CodeInvalidStateTransition, "dispute is already resolved")
+	ErrDisputeEvidenceRequired = apperr.Invalid("type", "is required")
+)
+
+// defaultEvidenceWindow is used when the card network does not supply a due date.
+const defaultEvidenceWindow = 7 * 24 * time.Hour
+
+type DisputeService struct {
+	repo        DisputeStore
+	txRepo      TransactionStore
+	balances    BalanceUpdater
+	settlements SettlementPublisher
+	tasks       *background.Tasks
+	log         *slog.Logger
+}
+
+func NewDisputeService(repo DisputeStore, txRepo TransactionStore, balances BalanceUpdater, settlements SettlementPublisher, tasks *background.Tasks, logger *slog.Logger) *DisputeService {
+	return &DisputeService{repo: repo, txRepo: txRepo, balances: balances, settlements: settlements, tasks: tasks, log: logger}
+}
+
+// Open records a chargeback against a captured transaction and debits the
+// disputed amount from the merchant's ledger. A transaction is charged back at
+// most once: not while another dispute is open, and never after one was lost.
+func (s *DisputeService) Open(ctx context.Context, reference string, req dto.DisputeCreateRequest) (dto.DisputeResponse, error) {
+	tx, err := s.txRepo.GetByReference(ctx, reference)
+	if err != nil {
+		return dto.DisputeResponse{}, err
+	}
+	if tx == nil {
+		return dto.DisputeResponse{}, ErrTransactionNotFound
+	}
+	if tx.Status != models.TransactionStatusSuccess || !tx.IsPayment() {
+		return dto.DisputeResponse{}, ErrTransactionNotCaptured
+	}
+
+	amount := tx.Amount
+	if req.Amount > 0 {
+		amount = int64(math.Round(req.Amount * 100))
+	}
+	if amount <= 0 || amount > tx.Amount {
+		return dto.DisputeResponse{}, ErrInvalidDisputeAmount
+	}
+
+	dueBy := req.EvidenceDueBy
+	if dueBy.IsZero() {
+		dueBy = time.Now().Add(defaultEvidenceWindow)
+	}
+
+	d := &models.Dispute{
+		TransactionID: tx.ID,
+		Reference:     tx.Reference,
+		MerchantID:    tx.MerchantID,
+		Amount:        amount,
+		Currency:      tx.Currency,
+		ReasonCode:    req.ReasonCode,
+		Status:        models.DisputeStatusNeedsResponse,
+		EvidenceDueBy: dueBy,
+		Livemode:      tx.Livemode,
+	}
+	// Test-mode transactions never reached the ledger, so there is nothing to debit.
+	var debit *models.LedgerEntry
+	if tx.Livemode {
+		debit = &models.LedgerEntry{
+			MerchantID:    tx.MerchantID,
+			TransactionID: tx.ID,
+			EntryType:     models.LedgerEntryDebit,
+			Amount:        amount,
+			Currency:      tx.Currency,
+			Description:   "Chargeback debit",
+			Reference:     tx.Reference,
+		}
+	}
+	code, err := s.repo.Create(ctx, d, debit)
+	if err != nil {
+		return dto.DisputeResponse{}, err
+	}
+	switch code {
+	case repositories.DisputeNotCaptured:
+		// Refunded between our read and the dispute's insert.
+		return dto.DisputeResponse{}, ErrTransactionNotCaptured
+	case repositories.DisputeAlreadyOpen:
+		return dto.DisputeResponse{}, ErrDisputeAlreadyOpen
+	case repositories.DisputeAlreadyLost:
+		return dto.DisputeResponse{}, ErrDisputeAlreadyLost
+	}
+
+	if debit != nil {
+		s.applyBalanceChange(ctx, d, -d.Amount)
+	}
+
+	return toDisputeResponse(d, nil), nil
+}
+
+func (s *DisputeService) Get(ctx context.Context, id int) (dto.DisputeResponse, error) {
+	d, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return dto.DisputeResponse{}, err
+	}
+	if d == nil {
+		return dto.DisputeResponse{}, ErrDisputeNotFound
+	}
+	evidence, err := s.repo.ListEvidence(ctx, id)
+	if err != nil {
+		return dto.DisputeResponse{}, err
+	}
+	return toDisputeResponse(d, evidence), nil
+}
+
+func (s *DisputeService) List(ctx context.Context, merchantID int, status string, livemode *bool, limit int) (dto.DisputeListResponse, error) {
+	list, err := s.repo.List(ctx, merchantID, status, livemode, limit)
+	if err != nil {
+		return dto.DisputeListResponse{}, err
+	}
+	res := dto.DisputeListResponse{Disputes: []dto.DisputeResponse{}}
+	for _, d := range list {
+		res.Disputes = append(res.Disputes, toDisputeResponse(d, nil))
+	}
+	res.Total = len(res.Disputes)
+	return res, nil
+}
+
+// UpdateStatus moves a dispute through its lifecycle. Winning a dispute
+// re-credits the amount that was debited when it was opened.
+func (s *DisputeService) UpdateStatus(ctx context.Context, id int, status string) (dto.DisputeResponse, error) {
+	d, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return dto.DisputeResponse{}, err
+	}
+	if d == nil {
+		return dto.DisputeResponse{}, ErrDisputeNotFound
+	}
+	if !models.CanTransitionDispute(d.Status, status) {
+		return dto.DisputeResponse{}, ErrInvalidDisputeStatus
+	}
+
+	var credit *models.LedgerEntry
+	if status == models.DisputeStatusWon {
+		tx, err := s.txRepo.GetByID(ctx, d.TransactionID)
+		if err != nil {
+			return dto.DisputeResponse{}, err
+		}
+		if tx == nil {
+			return dto.DisputeResponse{}, ErrTransactionNotFound
+		}
+		if tx.Livemode {
+			credit = &models.LedgerEntry{
+				MerchantID:    d.MerchantID,
+				TransactionID: d.TransactionID,
+				EntryType:     models.LedgerEntryCredit,
+				Amount:        d.Amount,
+				Currency:      d.Currency,
+				Description:   "Chargeback reversal",
+				Reference:     d.Reference,
+			}
+		}
+	}
+
+	from := d.Status
+	d.Status = status
+	ok, err := s.repo.UpdateStatus(ctx, d, from, credit)
+	if err != nil {
+		return dto.DisputeResponse{}, err
+	}
+	if !ok {
+		// Someone else moved the dispute between our read and write.
+		return dto.DisputeResponse{}, ErrInvalidDisputeStatus
+	}
+
+	if credit != nil {
+		s.applyBalanceChange(ctx, d, d.Amount)
+	}
+
+	return toDisputeResponse(d, nil), nil
+}
+
+// applyBalanceChange reports a chargeback or its reversal, amount minor units
+// (negative for money taken back), to the merchant service and the settlement
+// queue in the background, so disputed funds are not paid out.
+func (s *DisputeService) applyBalanceChange(ctx context.Context, d *models.Dispute, amount int64) {
+	merchantID, currency, txID, reference := d.MerchantID, d.Currency, d.TransactionID, d.Reference
+	s.tasks.Go(func() {
+		// Detached from the request but kept in its trace.
+		ctx := context.WithoutCancel(ctx)
+		s.balances.Record(ctx, merchantID, currency, float64(amount)/100)
+		if s.settlements == nil {
+			return
+		}
+		if err := s.settlements.PublishTransaction(ctx, merchantID, amount, currency, txID); err != nil {
+			s.log.ErrorContext(ctx, "failed to publish chargeback settlement event", "reference", reference,
+				"merchant_id", merchantID, "amount", amount, "currency", currency, "error", err)
+		}
+	})
+}
+
+func (s *DisputeService) AddEvidence(ctx context.Context, id int, req dto.DisputeEvidenceRequest) (dto.DisputeEvidenceResponse, error) {
+	if req.Type == "" {
+		return dto.DisputeEvidenceResponse{}, ErrDisputeEvidenceRequired
+	}
+	d, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return dto.DisputeEvidenceResponse{}, err
+	}
+	if d == nil {
+		return dto.DisputeEvidenceResponse{}, ErrDisputeNotFound
+	}
+	if d.Status == models.DisputeStatusWon || d.Status == models.DisputeStatusLost {
+		return dto.DisputeEvidenceResponse{}, ErrDisputeResolved
+	}
+
+	e := &models.DisputeEvidence{
+		DisputeID:   d.ID,
+		Type:        req.Type,
+		Description: req.Description,
+		FileURL:     req.FileURL,
+		SubmittedBy: req.SubmittedBy,
+	}
+	if err := s.repo.AddEvidence(ctx, e); err != nil {
+		return dto.DisputeEvidenceResponse{}, err
+	}
+	return toDisputeEvidenceResponse(e), nil
+}
+
+func toDisputeResponse(d *models.Dispute, evidence []*models.DisputeEvidence) dto.DisputeResponse {
+	resp := dto.DisputeResponse{
+		ID:            d.ID,
+		TransactionID: d.TransactionID,
+		Reference:     d.Reference,
+		MerchantID:    d.MerchantID,
+		Amount:        float64(d.Amount) / 100,
+		Currency:      d.Currency,
+		ReasonCode:    d.ReasonCode,
+		Status:        d.Status,
+		EvidenceDueBy: d.EvidenceDueBy,
+		Livemode:      d.Livemode,
+		ResolvedAt:    d.ResolvedAt,
+		CreatedAt:     d.CreatedAt,
+		UpdatedAt:     d.UpdatedAt,
+	}
+	for _, e := range evidence {
+		resp.Evidence = append(resp.Evidence, toDisputeEvidenceResponse(e))
+	}
+	return resp
+}
+
+func toDisputeEvidenceResponse(e *models.DisputeEvidence) dto.DisputeEvidenceResponse {
+	return dto.DisputeEvidenceResponse{
+		ID:          e.ID,
+		Type:        e.Type,
+		Description: e.Description,
+		FileURL:     e.FileURL,
+		SubmittedBy: e.SubmittedBy,
+		CreatedAt:   e.CreatedAt,
+	}
+}
@@ -0,0 +1,183 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/kodra-pay/transaction-service/internal/config"
+	"github.com/kodra-pay/transaction-service/internal/dto"
+	"github.com/kodra-pay/transaction-service/internal/models"
+	"github.com/kodra-pay/transaction-service/internal/repositories"
+	"github.com/kodra-pay/transaction-service/internal/validate"
+)
+
+// Names of the limits reported in LimitExceededError.
+const (
+	LimitMaxSingleAmount = "max_single_amount"
+	LimitDailyVolume     = "daily_volume"
+	LimitMonthlyVolume   = "monthly_volume"
+)
+
+// LimitExceededError explains which merchant limit a transaction would breach.
+// Amounts are in minor units.
+type LimitExceededError struct {
+	Limit     string
+	Currency  string
+	Max       int64
+	Current   int64
+	Attempted int64
+}
+
+func (e *LimitExceededError) Error() string {
+	return fmt.Sprintf("%s limit of %.2f %s exceeded", e.Limit, float64(e.Max)/100, e.Currency)
+}
+
+type LimitService struct {
+	repo     LimitStore
+	counter  VolumeCounter
+	defaults config.LimitsConfig
+}
+
+func NewLimitService(repo LimitStore, counter VolumeCounter, defaults config.LimitsConfig) *LimitService {
+	return &LimitService{repo: repo, counter: counter, defaults: defaults}
+}
+
+// Reserve checks the transaction against the merchant's limits and, if it
+// fits, adds it to the daily and monthly counters. The reservation is recorded
+// on tx, so ReleaseTransaction gives back exactly what was counted even if the
+// limits change in between.
+func (s *LimitService) Reserve(ctx context.Context, tx *models.Transaction) error {
+	limit, err := s.effectiveLimit(ctx, tx.MerchantID, tx.Currency)
+	if err != nil {
+		return err
+	}
+
+	if limit.MaxSingleAmount > 0 && tx.Amount > limit.MaxSingleAmount {
+		return &LimitExceededError{
+			Limit:     LimitMaxSingleAmount,
+			Currency:  limit.Currency,
+			Max:       limit.MaxSingleAmount,
+			Attempted: tx.Amount,
+		}
+	}
+	if limit.DailyVolume <= 0 && limit.MonthlyVolume <= 0 {
+		return nil
+	}
+
+	// Postgres keeps microseconds; truncating here keeps the stored time in
+	// the day and month it was counted in.
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	code, daily, monthly, err := s.counter.Reserve(ctx, tx.MerchantID, limit.Currency, tx.Amount, limit.DailyVolume, limit.MonthlyVolume, now)
+	if err != nil {
+		return err
+	}
+	switch code {
+	case repositories.VolumeDailyExceeded:
+		return &LimitExceededError{Limit: LimitDailyVolume, Currency: limit.Currency, Max: limit.DailyVolume, Current: daily, Attempted: tx.Amount}
+	case repositories.VolumeMonthlyExceeded:
+		return &LimitExceededError{Limit: LimitMonthlyVolume, Currency: limit.Currency, Max: limit.MonthlyVolume, Current: monthly, Attempted: tx.Amount}
+	}
+	tx.LimitReservedAt = &now
+	tx.LimitCurrency = limit.Currency
+	return nil
+}
+
+// ReleaseTransaction gives back the volume Reserve counted for a transaction
+// that will never be collected, such as one that failed to save or was
+// declined in manual review. It is a no-op for a transaction that was not
+// counted.
+func (s *LimitService) ReleaseTransaction(ctx context.Context, tx *models.Transaction) error {
+	if tx.LimitReservedAt == nil {
+		return nil
+	}
+	return s.counter.Release(ctx, tx.MerchantID, tx.LimitCurrency, tx.Amount, *tx.LimitReservedAt)
+}
+
+// List returns the merchant's configured limits with today's and this month's usage.
+func (s *LimitService) List(ctx context.Context, merchantID int) (dto.MerchantLimitListResponse, error) {
+	limits, err := s.repo.ListByMerchant(ctx, merchantID)
+	if err != nil {
+		return dto.MerchantLimitListResponse{}, err
+	}
+	res := dto.MerchantLimitListResponse{
+		MerchantID: merchantID,
+		Defaults: dto.MerchantLimitResponse{
+			Tier:            s.defaults.DefaultTier,
+			MaxSingleAmount: float64(s.defaults.DefaultMaxSingleAmount) / 100,
+			DailyVolume:     float64(s.defaults.DefaultDailyVolume) / 100,
+			MonthlyVolume:   float64(s.defaults.DefaultMonthlyVolume) / 100,
+		},
+		Limits: []dto.MerchantLimitResponse{},
+	}
+	now := time.Now()
+	for _, l := range limits {
+		resp := toMerchantLimitResponse(l)
+		daily, monthly, err := s.counter.Usage(ctx, merchantID, l.Currency, now)
+		if err != nil {
+			return dto.MerchantLimitListResponse{}, err
+		}
+		resp.DailyUsed = float64(daily) / 100
+		resp.MonthlyUsed = float64(monthly) / 100
+		res.Limits = append(res.Limits, resp)
+	}
+	return res, nil
+}
+
+// Set replaces the merchant's limits in one currency. An invalid request is
+// reported field by field, as a validation_failed error.
+func (s *LimitService) Set(ctx context.Context, merchantID int, req dto.MerchantLimitRequest) (dto.MerchantLimitResponse, error) {
+	if err := validate.Struct(&req); err != nil {
+		return dto.MerchantLimitResponse{}, err
+	}
+	tier := req.Tier
+	if tier == "" {
+		tier = s.defaults.DefaultTier
+	}
+	l := &models.MerchantLimit{
+		MerchantID:      merchantID,
+		Currency:        strings.ToUpper(req.Currency),
+		Tier:            tier,
+		MaxSingleAmount: int64(math.Round(req.MaxSingleAmount * 100)),
+		DailyVolume:     int64(math.Round(req.DailyVolume * 100)),
+		MonthlyVolume:   int64(math.Round(req.MonthlyVolume * 100)),
+	}
+	if err := s.repo.Upsert(ctx, l); err != nil {
+		return dto.MerchantLimitResponse{}, err
+	}
+	return toMerchantLimitResponse(l), nil
+}
+
+// effectiveLimit returns the merchant's own limit for the currency, falling
+// back to the configured starter-tier defaults.
+func (s *LimitService) effectiveLimit(ctx context.Context, merchantID int, currency string) (*models.MerchantLimit, error) {
+	currency = strings.ToUpper(currency)
+	l, err := s.repo.Get(ctx, merchantID, currency)
+	if err != nil {
+		return nil, err
+	}
+	if l != nil {
+		return l, nil
+	}
+	return &models.MerchantLimit{
+		MerchantID:      merchantID,
+		Currency:        currency,
+		Tier:            s.defaults.DefaultTier,
+		MaxSingleAmount: s.defaults.DefaultMaxSingleAmount,
+		DailyVolume:     s.defaults.DefaultDailyVolume,
+		MonthlyVolume:   s.defaults.DefaultMonthlyVolume,
+	}, nil
+}
+
+func toMerchantLimitResponse(l *models.MerchantLimit) dto.MerchantLimitResponse {
+	return dto.MerchantLimitResponse{
+		Currency:        l.Currency,
+		Tier:            l.Tier,
+		MaxSingleAmount: float64(l.MaxSingleAmount) / 100,
+		DailyVolume:     float64(l.DailyVolume) / 100,
+		MonthlyVolume:   float64(l.MonthlyVolume) / 100,
+		UpdatedAt:       l.UpdatedAt,
+	}
+}
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kodra-pay/transaction-service/internal/apperr"
+	"github.com/kodra-pay/transaction-service/internal/config"
+	"github.com/kodra-pay/transaction-service/internal/dto"
+	"github.com/kodra-pay/transaction-service/internal/memory"
+	"github.com/kodra-pay/transaction-service/internal/models"
+)
+
+func newTestLimitService() (*LimitService, *memory.VolumeCounter) {
+	counter := memory.NewVolumeCounter()
+	svc := NewLimitService(memory.NewLimitRepository(memory.NewStore(nil)), counter, config.LimitsConfig{
+		DefaultTier:            "starter",
+		DefaultMaxSingleAmount: 10000,
+		DefaultDailyVolume:     20000,
+		DefaultMonthlyVolume:   100000,
+	})
+	return svc, counter
+}
+
+func TestLimitReserveEnforcesDefaults(t *testing.T) {
+	ctx := context.Background()
+	svc, _ := newTestLimitService()
+
+	var exceeded *LimitExceededError
+	err := svc.Reserve(ctx, &models.Transaction{MerchantID: 1, Amount: 10001, Currency: "ngn"})
+	if !errors.As(err, &exceeded) || exceeded.Limit != LimitMaxSingleAmount {
+		t.Fatalf("over the single amount limit: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := svc.Reserve(ctx, &models.Transaction{MerchantID: 1, Amount: 10000, Currency: "NGN"}); err != nil {
+			t.Fatalf("reservation %d: %v", i, err)
+		}
+	}
+	err = svc.Reserve(ctx, &models.Transaction{MerchantID: 1, Amount: 1, Currency: "NGN"})
+	if !errors.As(err, &exceeded) || exceeded.Limit != LimitDailyVolume || exceeded.Current != 20000 {
+		t.Errorf("over the daily volume: %v", err)
+	}
+}
+
+// TestReleaseGivesBackTheReservation changes the merchant's limits between
+// reserving and releasing, and checks the release still gives back exactly
+// what was counted, in the currency it was counted in.
+func TestReleaseGivesBackTheReservation(t *testing.T) {
+	ctx := context.Background()
+	svc, counter := newTestLimitService()
+	tx := &models.Transaction{MerchantID: 1, Amount: 5000, Currency: "ngn"}
+	if err := svc.Reserve(ctx, tx); err != nil {
+		t.Fatal(err)
+	}
+	if tx.LimitReservedAt == nil || tx.LimitCurrency != "NGN" {
+		t.Fatalf("reservation not recorded: %v %q", tx.LimitReservedAt, tx.LimitCurrency)
+	}
+
+	if _, err := svc.Set(ctx, 1, dto.MerchantLimitRequest{Currency: "NGN"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.ReleaseTransaction(ctx, tx); err != nil {
+		t.Fatal(err)
+	}
+	if daily, monthly, _ := counter.Usage(ctx, 1, "NGN", *tx.LimitReservedAt); daily != 0 || monthly != 0 {
+		t.Errorf("usage after release: %d, %d", daily, monthly)
+	}
+}
+
+// TestReleaseUsesTheReservationDay releases a transaction reserved just before
+// midnight, which must come off that day's counter, not today's.
+func TestReleaseUsesTheReservationDay(t *testing.T) {
+	ctx := context.Background()
+	svc, counter := newTestLimitService()
+	yesterday := time.Now().UTC().Truncate(24 * time.Hour).Add(-time.Minute)
+	now := time.Now()
+	counter.Reserve(ctx, 1, "NGN", 3000, 0, 0, yesterday)
+	counter.Reserve(ctx, 1, "NGN", 2000, 0, 0, now)
+
+	tx := &models.Transaction{MerchantID: 1, Amount: 3000, Currency: "NGN", LimitCurrency: "NGN", LimitReservedAt: &yesterday}
+	if err := svc.ReleaseTransaction(ctx, tx); err != nil {
+		t.Fatal(err)
+	}
+	if daily, _, _ := counter.Usage(ctx, 1, "NGN", yesterday); daily != 0 {
+		t.Errorf("yesterday's volume %d after release, want 0", daily)
+	}
+	if daily, _, _ := counter.Usage(ctx, 1, "NGN", now); daily != 2000 {
+		t.Errorf("today's volume %d after release, want 2000", daily)
+	}
+
+	if err := svc.ReleaseTransaction(ctx, &models.Transaction{MerchantID: 1, Amount: 2000, Currency: "NGN"}); err != nil {
+		t.Fatal(err)
+	}
+	if daily, _, _ := counter.Usage(ctx, 1, "NGN", now); daily != 2000 {
+		t.Errorf("releasing an uncounted transaction changed today's volume to %d", daily)
+	}
+}
+
+func TestSetReportsInvalidFields(t *testing.T) {
+	svc, _ := newTestLimitService()
+
+	_, err := svc.Set(context.Background(), 1, dto.MerchantLimitRequest{Currency: "naira", DailyVolume: -1, MonthlyVolume: 10.005})
+	var e *apperr.Error
+	if !errors.As(err, &e) || e.Code != apperr.CodeValidationFailed {
+		t.Fatalf("got %v, want validation_failed", err)
+	}
+	fields := map[string]bool{}
+	for _, f := range e.Fields {
+		fields[f.Field] = true
+	}
+	if len(fields) != 3 || !fields["currency"] || !fields["daily_volume"] || !fields["monthly_volume"] {
+		t.Errorf("invalid fields %+v, want currency, daily_volume and monthly_volume", e.Fields)
+	}
+
+	resp, err := svc.Set(context.Background(), 1, dto.MerchantLimitRequest{Currency: "NGN", DailyVolume: 500})
+	if err != nil || resp.DailyVolume != 500 || resp.Tier != "starter" {
+		t.Errorf("valid limits: %+v, %v", resp, err)
+	}
+}
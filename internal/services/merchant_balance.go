@@ -0,0 +1,77 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/kodra-pay/transaction-service/internal/config"
+	"github.com/kodra-pay/transaction-service/internal/tracing"
+)
+
+// MerchantBalanceClient reports wallet balance changes to the merchant service.
+type MerchantBalanceClient struct {
+	url    string
+	client *http.Client
+	log    *slog.Logger
+}
+
+func NewMerchantBalanceClient(cfg config.MerchantServiceConfig, logger *slog.Logger) *MerchantBalanceClient {
+	return &MerchantBalanceClient{
+		url:    cfg.URL + "/internal/balance/record",
+		client: &http.Client{Timeout: cfg.Timeout},
+		log:    logger,
+	}
+}
+
+// Record calls the merchant service to update the balance. Failures are
+// logged, not returned: the balance update is not critical for transaction
+// success.
+func (c *MerchantBalanceClient) Record(ctx context.Context, merchantID int, currency string, amount float64) {
+	ctx, span := tracing.Start(ctx, "merchant-service.record_balance", trace.WithSpanKind(trace.SpanKindClient))
+	var err error
+	defer func() { tracing.End(span, err) }()
+
+	payload := map[string]interface{}{
+		"merchant_id": merchantID,
+		"currency":    currency,
+		"amount":      amount,
+	}
+
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+	span.SetAttributes(semconv.HTTPRequestMethodKey.String(req.Method), semconv.URLFull(c.url))
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		c.log.WarnContext(ctx, "failed to call merchant service to update balance", "merchant_id", merchantID, "error", err)
+		return
+	}
+	defer resp.Body.Close()
+	span.SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode))
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		c.log.WarnContext(ctx, "merchant service rejected balance update", "merchant_id", merchantID,
+			"status", resp.StatusCode, "body", string(respBody))
+		err = fmt.Errorf("merchant service returned %d", resp.StatusCode)
+	}
+}
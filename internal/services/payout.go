@@ -0,0 +1,78 @@
+package services
+
+import (
+	"context"
+	"math"
+
+	"github.com/kodra-pay/transaction-service/internal/auth"
+	"github.com/kodra-pay/transaction-service/internal/dto"
+	"github.com/kodra-pay/transaction-service/internal/metrics"
+	"github.com/kodra-pay/transaction-service/internal/models"
+)
+
+// CreatePayout records a payout of the merchant's balance. In live mode the
+// ledger is debited when the payout is created, so the funds cannot be paid
+// out twice, and the payout is refused if the balance in its currency does
+// not cover it. The payout stays pending until the processor reports it paid
+// or failed; a failed payout is credited back. Like the baseline payouts,
+// it is kept out of the merchant-service balance and settlement netting,
+// which would otherwise take the money off the merchant a second time.
+func (s *TransactionService) CreatePayout(ctx context.Context, req dto.PayoutCreateRequest) (dto.TransactionResponse, error) {
+	tx := &models.Transaction{
+		Reference:     req.Reference,
+		MerchantID:    req.MerchantID,
+		Type:          models.TransactionTypePayout,
+		Amount:        int64(math.Round(req.Amount * 100)),
+		Currency:      req.Currency,
+		Status:        models.TransactionStatusPending,
+		PaymentMethod: "bank_transfer",
+		Description:   req.Description,
+		RiskDecision:  models.RiskDecisionAllow,
+		Livemode:      auth.FromContext(ctx).Livemode(),
+	}
+
+	// Test-mode payouts never touch the ledger.
+	var debit *models.LedgerEntry
+	if tx.Livemode {
+		debit = &models.LedgerEntry{
+			MerchantID:  tx.MerchantID,
+			EntryType:   models.LedgerEntryDebit,
+			Amount:      tx.Amount,
+			Currency:    tx.Currency,
+			Description: "Payout debit",
+			Reference:   tx.Reference,
+		}
+	}
+	ok, err := s.repo.CreatePayout(ctx, tx, newTransactionEvent(models.EventSourceAPI, "", nil), debit)
+	if err != nil {
+		return dto.TransactionResponse{}, err
+	}
+	if !ok {
+		return dto.TransactionResponse{}, ErrInsufficientBalance
+	}
+
+	s.audit.Record(ctx, models.AuditActionCreate, nil, tx)
+	metrics.TransactionsCreated.WithLabelValues(tx.Currency, tx.Status).Inc()
+	metrics.TransactionAmount.WithLabelValues(tx.Currency, tx.Status).Add(float64(tx.Amount))
+	return toTransactionResponse(tx), nil
+}
+
+// reversePayout credits back the ledger debit of a live payout that failed.
+func (s *TransactionService) reversePayout(ctx context.Context, tx *models.Transaction) {
+	if !tx.Livemode || s.ledger == nil {
+		return
+	}
+	err := s.ledger.Record(ctx, &models.LedgerEntry{
+		MerchantID:    tx.MerchantID,
+		TransactionID: tx.ID,
+		EntryType:     models.LedgerEntryCredit,
+		Amount:        tx.Amount,
+		Currency:      tx.Currency,
+		Description:   "Payout reversal",
+		Reference:     tx.Reference,
+	})
+	if err != nil {
+		s.log.ErrorContext(ctx, "failed to record payout reversal", "reference", tx.Reference,
+			"merchant_id", tx.MerchantID, "entry_type", models.LedgerEntryCredit, "error", err)
+	}
+}
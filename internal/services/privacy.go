@@ -0,0 +1,159 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/kodra-pay/transaction-service/internal/apperr"
+	"github.com/kodra-pay/transaction-service/internal/auth"
+	"github.com/kodra-pay/transaction-service/internal/dto"
+	"github.com/kodra-pay/transaction-service/internal/models"
+)
+
+var (
+	ErrCustomerRequired = apperr.New(apperr.CodeValidationFailed, "customer_id or email is required")
+	ErrErasureNotFound  = apperr.New(apperr.CodeNotFound, "erasure request not found")
+)
+
+// PrivacyService answers data subject requests: exports of a customer's
+// transactions and erasure of their PII.
+type PrivacyService struct {
+	repo     ErasureStore
+	txRepo   TransactionStore
+	interval time.Duration
+	log      *slog.Logger
+}
+
+func NewPrivacyService(repo ErasureStore, txRepo TransactionStore, interval time.Duration, logger *slog.Logger) *PrivacyService {
+	return &PrivacyService{repo: repo, txRepo: txRepo, interval: interval, log: logger}
+}
+
+// Export returns every transaction of the customer with PII in full.
+func (s *PrivacyService) Export(ctx context.Context, customerID int, email string) (dto.CustomerExportResponse, error) {
+	email = strings.TrimSpace(email)
+	if customerID == 0 && email == "" {
+		return dto.CustomerExportResponse{}, ErrCustomerRequired
+	}
+	txs, err := s.txRepo.ListByCustomer(ctx, customerID, email)
+	if err != nil {
+		return dto.CustomerExportResponse{}, err
+	}
+	res := dto.CustomerExportResponse{
+		CustomerID:   customerID,
+		Email:        email,
+		GeneratedAt:  time.Now().UTC(),
+		Transactions: []dto.TransactionResponse{},
+	}
+	for _, tx := range txs {
+		res.Transactions = append(res.Transactions, toTransactionResponse(tx))
+	}
+	res.Total = len(res.Transactions)
+	return res, nil
+}
+
+// RequestErasure records an erasure request for the background job.
+func (s *PrivacyService) RequestErasure(ctx context.Context, req dto.ErasureCreateRequest) (dto.ErasureResponse, error) {
+	req.Email = strings.TrimSpace(req.Email)
+	if req.CustomerID == 0 && req.Email == "" {
+		return dto.ErasureResponse{}, ErrCustomerRequired
+	}
+	e := &models.ErasureRequest{
+		CustomerID:  req.CustomerID,
+		Email:       req.Email,
+		Reason:      req.Reason,
+		RequestedBy: "system",
+	}
+	if p := auth.FromContext(ctx); p != nil && p.Subject != "" {
+		e.RequestedBy = p.Subject
+	}
+	if err := s.repo.Create(ctx, e); err != nil {
+		return dto.ErasureResponse{}, err
+	}
+	return toErasureResponse(e), nil
+}
+
+// GetErasure returns a request. Completed requests are re-verified on every
+// read by counting transactions that still link to the customer.
+func (s *PrivacyService) GetErasure(ctx context.Context, id int) (dto.ErasureResponse, error) {
+	e, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return dto.ErasureResponse{}, err
+	}
+	if e == nil {
+		return dto.ErasureResponse{}, ErrErasureNotFound
+	}
+	res := toErasureResponse(e)
+	if e.Status == models.ErasureStatusCompleted {
+		remaining, err := s.txRepo.CountByCustomer(ctx, e.CustomerID, e.EmailIndex)
+		if err != nil {
+			return dto.ErasureResponse{}, err
+		}
+		verified := remaining == 0
+		res.Verified = &verified
+		res.RemainingTransactions = &remaining
+	}
+	return res, nil
+}
+
+// Run executes pending erasure requests until ctx is cancelled.
+func (s *PrivacyService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		for s.processNext(ctx) {
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// processNext executes one pending request and reports whether there was one.
+func (s *PrivacyService) processNext(ctx context.Context) bool {
+	e, err := s.repo.ClaimNext(ctx)
+	if err != nil {
+		s.log.ErrorContext(ctx, "failed to claim erasure request", "error", err)
+		return false
+	}
+	if e == nil {
+		return false
+	}
+
+	txCount, ledgerCount, err := s.txRepo.Pseudonymise(ctx, e.CustomerID, e.Email, models.ErasedEmail(e.ID))
+	if err != nil {
+		s.log.ErrorContext(ctx, "erasure request failed", "erasure_id", e.ID, "error", err)
+		if err := s.repo.Fail(ctx, e.ID, err.Error()); err != nil {
+			s.log.ErrorContext(ctx, "failed to record erasure request failure", "erasure_id", e.ID, "error", err)
+		}
+		return true
+	}
+	e.TransactionsAffected = txCount
+	e.LedgerEntriesAffected = ledgerCount
+	if err := s.repo.Complete(ctx, e); err != nil {
+		s.log.ErrorContext(ctx, "failed to complete erasure request", "erasure_id", e.ID, "error", err)
+		return true
+	}
+	s.log.InfoContext(ctx, "erasure request completed", "erasure_id", e.ID,
+		"transactions_affected", txCount, "ledger_entries_affected", ledgerCount)
+	return true
+}
+
+func toErasureResponse(e *models.ErasureRequest) dto.ErasureResponse {
+	return dto.ErasureResponse{
+		ID:                    e.ID,
+		CustomerID:            e.CustomerID,
+		Status:                e.Status,
+		RequestedBy:           e.RequestedBy,
+		Reason:                e.Reason,
+		TransactionsAffected:  e.TransactionsAffected,
+		LedgerEntriesAffected: e.LedgerEntriesAffected,
+		Error:                 e.Error,
+		StartedAt:             e.StartedAt,
+		CompletedAt:           e.CompletedAt,
+		CreatedAt:             e.CreatedAt,
+	}
+}
@@ -0,0 +1,52 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kodra-pay/transaction-service/internal/auth"
+	"github.com/kodra-pay/transaction-service/internal/config"
+	"github.com/kodra-pay/transaction-service/internal/models"
+)
+
+func TestRateLimitServiceLimit(t *testing.T) {
+	s := NewRateLimitService(nil, config.RateLimitConfig{
+		Enabled: true, Reads: 600, Writes: 120, Exports: 10,
+		MerchantOverrides: map[int]map[string]int{7: {models.RateLimitWrites: 1000}},
+	})
+	tests := []struct {
+		merchantID int
+		class      string
+		want       int
+	}{
+		{1, models.RateLimitReads, 600},
+		{1, models.RateLimitWrites, 120},
+		{1, models.RateLimitExports, 10},
+		{7, models.RateLimitWrites, 1000},
+		{7, models.RateLimitReads, 600},
+	}
+	for _, tt := range tests {
+		if got := s.Limit(tt.merchantID, tt.class); got != tt.want {
+			t.Errorf("Limit(%d, %s) = %d, want %d", tt.merchantID, tt.class, got, tt.want)
+		}
+	}
+}
+
+func TestRateLimitServiceExemptions(t *testing.T) {
+	ctx := context.Background()
+	merchant := &auth.Principal{Kind: auth.KindAPIKey, MerchantID: 1, KeyID: 3}
+
+	// A nil bucket would panic if any of these reached Redis.
+	disabled := NewRateLimitService(nil, config.RateLimitConfig{Reads: 1})
+	if d, err := disabled.Allow(ctx, merchant, models.RateLimitReads); d != nil || err != nil {
+		t.Errorf("disabled limiting: %+v, %v", d, err)
+	}
+	s := NewRateLimitService(nil, config.RateLimitConfig{Enabled: true, Reads: 1})
+	internal := &auth.Principal{Kind: auth.KindInternal, Subject: "internal:test"}
+	if d, err := s.Allow(ctx, internal, models.RateLimitReads); d != nil || err != nil {
+		t.Errorf("internal caller: %+v, %v", d, err)
+	}
+	if d, err := s.Allow(ctx, merchant, models.RateLimitWrites); d != nil || err != nil {
+		t.Errorf("unlimited class: %+v, %v", d, err)
+	}
+}
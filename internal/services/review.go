@@ -0,0 +1,202 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/kodra-pay/transaction-service/internal/apperr"
+	"github.com/kodra-pay/transaction-service/internal/auth"
+	"github.com/kodra-pay/transaction-service/internal/background"
+	"github.com/kodra-pay/transaction-service/internal/dto"
+	"github.com/kodra-pay/transaction-service/internal/models"
+	"github.com/kodra-pay/transaction-service/internal/queue"
+)
+
+var (
+	ErrReviewNotFound     = apperr.New(apperr.CodeNotFound, "review not found")
+	ErrReviewerRequired   = apperr.Forbidden("reviews require credentials that identify the reviewer")
+	ErrReviewNotPending   = apperr.New(apperr.CodeInvalidStateTransition, "review is not pending")
+	ErrReviewNotClaimedBy = apperr.New(apperr.CodeConflict, "review must be claimed by the reviewer before it can be decided")
+)
+
+type ReviewService struct {
+	repo     ReviewStore
+	txRepo   TransactionStore
+	txSvc    *TransactionService
+	limits   *LimitService
+	notifier Notifier
+	tasks    *background.Tasks
+	log      *slog.Logger
+}
+
+func NewReviewService(repo ReviewStore, txRepo TransactionStore, txSvc *TransactionService, limits *LimitService, notifier Notifier, tasks *background.Tasks, logger *slog.Logger) *ReviewService {
+	return &ReviewService{repo: repo, txRepo: txRepo, txSvc: txSvc, limits: limits, notifier: notifier, tasks: tasks, log: logger}
+}
+
+func (s *ReviewService) List(ctx context.Context, status string, limit int) (dto.ReviewListResponse, error) {
+	if status == "" {
+		status = models.ReviewStatusPending
+	}
+	list, err := s.repo.ListByStatus(ctx, status, limit)
+	if err != nil {
+		return dto.ReviewListResponse{}, err
+	}
+	res := dto.ReviewListResponse{Reviews: []dto.ReviewResponse{}}
+	for _, rv := range list {
+		tx, err := s.txRepo.GetByID(ctx, rv.TransactionID)
+		if err != nil {
+			return dto.ReviewListResponse{}, err
+		}
+		res.Reviews = append(res.Reviews, toReviewResponse(rv, tx))
+	}
+	res.Total = len(res.Reviews)
+	return res, nil
+}
+
+func (s *ReviewService) Get(ctx context.Context, id int) (dto.ReviewResponse, error) {
+	rv, tx, err := s.load(ctx, id)
+	if err != nil {
+		return dto.ReviewResponse{}, err
+	}
+	return toReviewResponse(rv, tx), nil
+}
+
+// Claim assigns a pending review to the caller so two people do not work the same case.
+func (s *ReviewService) Claim(ctx context.Context, id int) (dto.ReviewResponse, error) {
+	reviewer, err := reviewerFrom(ctx)
+	if err != nil {
+		return dto.ReviewResponse{}, err
+	}
+	rv, tx, err := s.load(ctx, id)
+	if err != nil {
+		return dto.ReviewResponse{}, err
+	}
+	rv.Reviewer = reviewer
+	ok, err := s.repo.Claim(ctx, rv)
+	if err != nil {
+		return dto.ReviewResponse{}, err
+	}
+	if !ok {
+		return dto.ReviewResponse{}, ErrReviewNotPending
+	}
+	return toReviewResponse(rv, tx), nil
+}
+
+// Approve releases the held transaction: it becomes successful and goes
+// through the same ledger, balance and settlement steps as an allowed one.
+func (s *ReviewService) Approve(ctx context.Context, id int, req dto.ReviewDecisionRequest) (dto.ReviewResponse, error) {
+	rv, tx, err := s.decide(ctx, id, req, models.AuditActionReviewApprove, models.ReviewStatusApproved, models.TransactionStatusSuccess)
+	if err != nil {
+		return dto.ReviewResponse{}, err
+	}
+	s.txSvc.applySettlementEffects(ctx, tx)
+	return toReviewResponse(rv, tx), nil
+}
+
+// Decline fails the held transaction, gives its volume back to the merchant's
+// limits and notifies the merchant.
+func (s *ReviewService) Decline(ctx context.Context, id int, req dto.ReviewDecisionRequest) (dto.ReviewResponse, error) {
+	rv, tx, err := s.decide(ctx, id, req, models.AuditActionReviewDecline, models.ReviewStatusDeclined, models.TransactionStatusFailed)
+	if err != nil {
+		return dto.ReviewResponse{}, err
+	}
+
+	if s.limits != nil {
+		if err := s.limits.ReleaseTransaction(ctx, tx); err != nil {
+			s.log.WarnContext(ctx, "failed to release limit volume", "reference", tx.Reference, "error", err)
+		}
+	}
+
+	if s.notifier != nil {
+		n := queue.TransactionNotification{
+			Event:      "transaction.declined",
+			Reference:  tx.Reference,
+			MerchantID: tx.MerchantID,
+			Status:     tx.Status,
+			Reason:     "declined after manual review",
+		}
+		s.tasks.Go(func() {
+			if err := s.notifier.Publish(context.WithoutCancel(ctx), n); err != nil {
+				s.log.WarnContext(ctx, "failed to publish decline notification", "reference", n.Reference, "error", err)
+			}
+		})
+	}
+
+	return toReviewResponse(rv, tx), nil
+}
+
+func (s *ReviewService) decide(ctx context.Context, id int, req dto.ReviewDecisionRequest, action, reviewStatus, txStatus string) (*models.Review, *models.Transaction, error) {
+	reviewer, err := reviewerFrom(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	rv, tx, err := s.load(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if rv.Status != models.ReviewStatusClaimed || rv.Reviewer != reviewer {
+		return nil, nil, ErrReviewNotClaimedBy
+	}
+
+	before := *tx
+	rv.Status = reviewStatus
+	rv.Notes = req.Notes
+	tx.Status = txStatus
+	ev := newTransactionEvent(models.EventSourceAPI, before.Status, map[string]interface{}{
+		"review_id": rv.ID,
+		"reviewer":  rv.Reviewer,
+	})
+	ok, err := s.repo.Decide(ctx, rv, tx, ev)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !ok {
+		return nil, nil, ErrReviewNotClaimedBy
+	}
+	s.txSvc.audit.Record(ctx, action, &before, tx)
+	return rv, tx, nil
+}
+
+func (s *ReviewService) load(ctx context.Context, id int) (*models.Review, *models.Transaction, error) {
+	rv, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if rv == nil {
+		return nil, nil, ErrReviewNotFound
+	}
+	tx, err := s.txRepo.GetByID(ctx, rv.TransactionID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if tx == nil {
+		return nil, nil, ErrTransactionNotFound
+	}
+	return rv, tx, nil
+}
+
+// reviewerFrom returns who is working a review: the subject of the caller's
+// credentials, never a name taken from the request body.
+func reviewerFrom(ctx context.Context) (string, error) {
+	p := auth.FromContext(ctx)
+	if p == nil || p.Subject == "" {
+		return "", ErrReviewerRequired
+	}
+	return p.Subject, nil
+}
+
+func toReviewResponse(rv *models.Review, tx *models.Transaction) dto.ReviewResponse {
+	resp := dto.ReviewResponse{
+		ID:        rv.ID,
+		Status:    rv.Status,
+		Reviewer:  rv.Reviewer,
+		Notes:     rv.Notes,
+		ClaimedAt: rv.ClaimedAt,
+		DecidedAt: rv.DecidedAt,
+		CreatedAt: rv.CreatedAt,
+	}
+	if tx != nil {
+		resp.Transaction = toTransactionResponse(tx)
+	}
+	return resp
+}
@@ -0,0 +1,128 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/kodra-pay/transaction-service/internal/config"
+	"github.com/kodra-pay/transaction-service/internal/models"
+)
+
+// Scores contributed by each rule. A transaction's score is their sum, capped at 100.
+const (
+	riskScoreBlocked          = 100
+	riskScoreVelocity         = 40
+	riskScoreAmountThreshold  = 50
+	riskScoreCurrencyMismatch = 30
+	maxRiskScore              = 100
+)
+
+// RiskAssessment is the outcome of screening a transaction.
+type RiskAssessment struct {
+	Score    int
+	Decision string
+	Reasons  []string
+}
+
+// RiskEngine evaluates the configured rules against a transaction before it is persisted.
+type RiskEngine struct {
+	cfg    config.RiskConfig
+	repo   TransactionStore
+	emails map[string]bool
+	bins   map[string]bool
+}
+
+func NewRiskEngine(cfg config.RiskConfig, repo TransactionStore) *RiskEngine {
+	e := &RiskEngine{
+		cfg:    cfg,
+		repo:   repo,
+		emails: map[string]bool{},
+		bins:   map[string]bool{},
+	}
+	for _, email := range cfg.BlockedEmails {
+		e.emails[strings.ToLower(email)] = true
+	}
+	for _, bin := range cfg.BlockedBINs {
+		e.bins[bin] = true
+	}
+	return e
+}
+
+// Evaluate scores the transaction. cardBIN is the first six to eight digits
+// of the card number, if the caller supplied one.
+func (e *RiskEngine) Evaluate(ctx context.Context, tx *models.Transaction, cardBIN string) (RiskAssessment, error) {
+	var a RiskAssessment
+
+	if tx.CustomerEmail != "" && e.emails[strings.ToLower(tx.CustomerEmail)] {
+		a.add(riskScoreBlocked, "blocked_email")
+	}
+	if cardBIN != "" && e.blockedBIN(cardBIN) {
+		a.add(riskScoreBlocked, "blocked_bin")
+	}
+
+	if threshold := e.amountThreshold(tx.MerchantID); threshold > 0 && tx.Amount > threshold {
+		a.add(riskScoreAmountThreshold, "amount_threshold")
+	}
+
+	if allowed, ok := e.cfg.MerchantCurrencies[tx.MerchantID]; ok && !containsFold(allowed, tx.Currency) {
+		a.add(riskScoreCurrencyMismatch, "currency_mismatch")
+	}
+
+	if e.cfg.VelocityLimit > 0 && (tx.CustomerID != 0 || tx.CustomerEmail != "") {
+		// Counted per merchant and mode, so neither another merchant's sales
+		// nor test traffic can hold up a live payment.
+		count, err := e.repo.CountRecentByCustomer(ctx, tx.MerchantID, tx.Livemode, tx.CustomerID, tx.CustomerEmail, time.Now().Add(-e.cfg.VelocityWindow))
+		if err != nil {
+			return RiskAssessment{}, err
+		}
+		if count >= e.cfg.VelocityLimit {
+			a.add(riskScoreVelocity, "velocity")
+		}
+	}
+
+	switch {
+	case a.Score >= e.cfg.BlockScore:
+		a.Decision = models.RiskDecisionBlock
+	case a.Score >= e.cfg.ReviewScore:
+		a.Decision = models.RiskDecisionReview
+	default:
+		a.Decision = models.RiskDecisionAllow
+	}
+	return a, nil
+}
+
+func (a *RiskAssessment) add(score int, reason string) {
+	a.Score += score
+	if a.Score > maxRiskScore {
+		a.Score = maxRiskScore
+	}
+	a.Reasons = append(a.Reasons, reason)
+}
+
+// blockedBIN matches any configured BIN that prefixes the supplied one, so
+// both six and eight digit BINs can be listed.
+func (e *RiskEngine) blockedBIN(bin string) bool {
+	for blocked := range e.bins {
+		if strings.HasPrefix(bin, blocked) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *RiskEngine) amountThreshold(merchantID int) int64 {
+	if threshold, ok := e.cfg.MerchantAmountThresholds[merchantID]; ok {
+		return threshold
+	}
+	return e.cfg.AmountThreshold
+}
+
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,51 @@
+package services
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/kodra-pay/transaction-service/internal/config"
+	"github.com/kodra-pay/transaction-service/internal/models"
+)
+
+func TestRiskEngineRules(t *testing.T) {
+	engine := NewRiskEngine(config.RiskConfig{
+		ReviewScore:              50,
+		BlockScore:               90,
+		AmountThreshold:          100000,
+		MerchantAmountThresholds: map[int]int64{7: 500},
+		MerchantCurrencies:       map[int][]string{8: {"NGN"}},
+		BlockedEmails:            []string{"Fraud@Example.com"},
+		BlockedBINs:              []string{"411111"},
+	}, nil)
+
+	tests := []struct {
+		name     string
+		tx       models.Transaction
+		bin      string
+		score    int
+		decision string
+		reasons  []string
+	}{
+		{"clean", models.Transaction{MerchantID: 1, Amount: 1000, Currency: "NGN"}, "", 0, models.RiskDecisionAllow, nil},
+		{"blocked email", models.Transaction{MerchantID: 1, Amount: 1000, CustomerEmail: "fraud@example.com"}, "", 100, models.RiskDecisionBlock, []string{"blocked_email"}},
+		{"blocked eight digit bin", models.Transaction{MerchantID: 1, Amount: 1000}, "41111122", 100, models.RiskDecisionBlock, []string{"blocked_bin"}},
+		{"over threshold", models.Transaction{MerchantID: 1, Amount: 100001}, "", 50, models.RiskDecisionReview, []string{"amount_threshold"}},
+		{"merchant threshold", models.Transaction{MerchantID: 7, Amount: 501}, "", 50, models.RiskDecisionReview, []string{"amount_threshold"}},
+		{"currency mismatch", models.Transaction{MerchantID: 8, Amount: 1000, Currency: "usd"}, "", 30, models.RiskDecisionAllow, []string{"currency_mismatch"}},
+		{"allowed currency", models.Transaction{MerchantID: 8, Amount: 1000, Currency: "ngn"}, "", 0, models.RiskDecisionAllow, nil},
+		{"capped", models.Transaction{MerchantID: 8, Amount: 100001, Currency: "USD", CustomerEmail: "fraud@example.com"}, "", 100, models.RiskDecisionBlock, []string{"blocked_email", "amount_threshold", "currency_mismatch"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := engine.Evaluate(context.Background(), &tt.tx, tt.bin)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if a.Score != tt.score || a.Decision != tt.decision || !reflect.DeepEqual(a.Reasons, tt.reasons) {
+				t.Errorf("got %d %s %v, want %d %s %v", a.Score, a.Decision, a.Reasons, tt.score, tt.decision, tt.reasons)
+			}
+		})
+	}
+}
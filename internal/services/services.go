@@ -1,3 +1,10 @@
 package services
 
-// TODO: implement domain services for this microservice
+import "github.com/kodra-pay/transaction-service/internal/apperr"
+
+var (
+	ErrTransactionNotFound     = apperr.New(apperr.CodeNotFound, "transaction not found")
+	ErrInvalidTransactionState = apperr.New(apperr.CodeInvalidStateTransition, "invalid transaction state")
+	ErrInvalidEventSource      = apperr.Invalid("source", "must be worker or webhook")
+	ErrInsufficientBalance     = apperr.New(apperr.CodeInsufficientBalance, "merchant balance is too low for this payout")
+)
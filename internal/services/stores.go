@@ -0,0 +1,135 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/kodra-pay/transaction-service/internal/models"
+	"github.com/kodra-pay/transaction-service/internal/queue"
+)
+
+// The services depend on the stores and publishers below rather than on
+// Postgres and Redis directly. The repositories and queue packages implement
+// them for production; the memory package implements them in process for
+// tests and local demos.
+
+// TransactionStore persists transactions with their timeline events.
+type TransactionStore interface {
+	Create(ctx context.Context, tx *models.Transaction, ev *models.TransactionEvent) error
+	CreateForReview(ctx context.Context, tx *models.Transaction, ev *models.TransactionEvent) error
+	CreatePayout(ctx context.Context, tx *models.Transaction, ev *models.TransactionEvent, debit *models.LedgerEntry) (bool, error)
+	CreateAdjustment(ctx context.Context, tx *models.Transaction, ev *models.TransactionEvent, adj *models.Adjustment) error
+	GetByReference(ctx context.Context, reference string) (*models.Transaction, error)
+	GetByID(ctx context.Context, id int) (*models.Transaction, error)
+	ListByMerchant(ctx context.Context, merchantID int, status string, livemode *bool, limit int) ([]*models.Transaction, error)
+	ListByStatus(ctx context.Context, status string, limit int) ([]*models.Transaction, error)
+	UpdateStatus(ctx context.Context, tx *models.Transaction, ev *models.TransactionEvent, from ...string) (bool, error)
+	Refund(ctx context.Context, tx *models.Transaction, ev *models.TransactionEvent, from ...string) (bool, error)
+	UpdateDetails(ctx context.Context, tx *models.Transaction) error
+	ReencryptPII(ctx context.Context, batch int) (int, error)
+	CountRecentByCustomer(ctx context.Context, merchantID int, livemode bool, customerID int, email string, since time.Time) (int, error)
+	ListByCustomer(ctx context.Context, customerID int, email string) ([]*models.Transaction, error)
+	CountByCustomer(ctx context.Context, customerID int, emailIndex string) (int, error)
+	Pseudonymise(ctx context.Context, customerID int, email, pseudonym string) (int, int, error)
+}
+
+// LedgerStore appends entries to the merchant wallet ledger.
+type LedgerStore interface {
+	Record(ctx context.Context, entry *models.LedgerEntry) error
+}
+
+// EventStore reads transaction timelines.
+type EventStore interface {
+	ListByTransaction(ctx context.Context, transactionID int) ([]*models.TransactionEvent, error)
+}
+
+// ReviewStore persists the manual review queue.
+type ReviewStore interface {
+	GetByID(ctx context.Context, id int) (*models.Review, error)
+	ListByStatus(ctx context.Context, status string, limit int) ([]*models.Review, error)
+	CountByStatus(ctx context.Context, statuses ...string) (int64, error)
+	Claim(ctx context.Context, rv *models.Review) (bool, error)
+	Decide(ctx context.Context, rv *models.Review, tx *models.Transaction, ev *models.TransactionEvent) (bool, error)
+}
+
+// AdjustmentStore persists manual ledger adjustments and their approval.
+type AdjustmentStore interface {
+	GetByID(ctx context.Context, id int) (*models.Adjustment, error)
+	List(ctx context.Context, merchantID int, status string, limit int) ([]*models.Adjustment, error)
+	Decide(ctx context.Context, adj *models.Adjustment, tx *models.Transaction, ev *models.TransactionEvent, entry *models.LedgerEntry) (bool, error)
+}
+
+// DisputeStore persists disputes, their evidence and the ledger entries they
+// cause. Create returns one of the repositories.Dispute* codes.
+type DisputeStore interface {
+	Create(ctx context.Context, d *models.Dispute, debit *models.LedgerEntry) (int, error)
+	GetByID(ctx context.Context, id int) (*models.Dispute, error)
+	List(ctx context.Context, merchantID int, status string, livemode *bool, limit int) ([]*models.Dispute, error)
+	UpdateStatus(ctx context.Context, d *models.Dispute, from string, entry *models.LedgerEntry) (bool, error)
+	AddEvidence(ctx context.Context, e *models.DisputeEvidence) error
+	ListEvidence(ctx context.Context, disputeID int) ([]*models.DisputeEvidence, error)
+}
+
+// LimitStore persists per-merchant limit overrides.
+type LimitStore interface {
+	Get(ctx context.Context, merchantID int, currency string) (*models.MerchantLimit, error)
+	ListByMerchant(ctx context.Context, merchantID int) ([]*models.MerchantLimit, error)
+	Upsert(ctx context.Context, l *models.MerchantLimit) error
+}
+
+// VolumeCounter tracks collected volume against daily and monthly limits.
+// Reserve returns one of the repositories.Volume* codes.
+type VolumeCounter interface {
+	Reserve(ctx context.Context, merchantID int, currency string, amount, dailyLimit, monthlyLimit int64, at time.Time) (int, int64, int64, error)
+	Release(ctx context.Context, merchantID int, currency string, amount int64, at time.Time) error
+	Usage(ctx context.Context, merchantID int, currency string, at time.Time) (int64, int64, error)
+}
+
+// AuditStore appends to and reads the hash-chained audit log.
+type AuditStore interface {
+	Append(ctx context.Context, e *models.AuditEntry) error
+	ListByReference(ctx context.Context, reference string) ([]*models.AuditEntry, error)
+}
+
+// APIKeyStore persists merchant API keys by hash.
+type APIKeyStore interface {
+	Create(ctx context.Context, k *models.APIKey) error
+	GetByHash(ctx context.Context, hash string) (*models.APIKey, error)
+	GetByID(ctx context.Context, id int) (*models.APIKey, error)
+	ListByMerchant(ctx context.Context, merchantID int) ([]*models.APIKey, error)
+	Rotate(ctx context.Context, oldID int, graceUntil time.Time, replacement *models.APIKey) error
+	Revoke(ctx context.Context, id int) error
+	TouchLastUsed(ctx context.Context, id int) error
+}
+
+// ErasureStore persists data subject erasure requests.
+type ErasureStore interface {
+	Create(ctx context.Context, e *models.ErasureRequest) error
+	GetByID(ctx context.Context, id int) (*models.ErasureRequest, error)
+	ClaimNext(ctx context.Context) (*models.ErasureRequest, error)
+	Complete(ctx context.Context, e *models.ErasureRequest) error
+	Fail(ctx context.Context, id int, reason string) error
+	CountByStatus(ctx context.Context, statuses ...string) (int64, error)
+}
+
+// TokenBucket takes rate limit tokens from shared buckets.
+type TokenBucket interface {
+	Take(ctx context.Context, key string, capacity int, window time.Duration) (bool, int, time.Duration, time.Duration, error)
+}
+
+// SettlementPublisher queues completed collections for settlement.
+type SettlementPublisher interface {
+	PublishTransaction(ctx context.Context, merchantID int, amount int64, currency string, txID int) error
+}
+
+// Notifier queues merchant-facing transaction notifications.
+type Notifier interface {
+	Publish(ctx context.Context, n queue.TransactionNotification) error
+}
+
+// BalanceUpdater tells the merchant service about balance movements, in
+// currency units. Failures are the updater's to log; callers do not wait on
+// them.
+type BalanceUpdater interface {
+	Record(ctx context.Context, merchantID int, currency string, amount float64)
+}
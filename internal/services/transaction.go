@@ -1,41 +1,56 @@
 package services
 
 import (
-	"bytes"
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
-	"io"
-	"log"
+	"log/slog"
 	"math"
-	"net/http"
-	"os"
-	"time"
 
+	"github.com/kodra-pay/transaction-service/internal/auth"
+	"github.com/kodra-pay/transaction-service/internal/background"
 	"github.com/kodra-pay/transaction-service/internal/dto"
+	"github.com/kodra-pay/transaction-service/internal/metrics"
 	"github.com/kodra-pay/transaction-service/internal/models"
-	"github.com/kodra-pay/transaction-service/internal/queue"
-	"github.com/kodra-pay/transaction-service/internal/repositories"
 )
 
 type TransactionService struct {
-	repo                *repositories.TransactionRepository
-	settlementPublisher *queue.SettlementPublisher
+	repo                TransactionStore
+	ledger              LedgerStore
+	settlementPublisher SettlementPublisher
+	risk                *RiskEngine
+	limits              *LimitService
+	audit               *AuditService
+	events              EventStore
+	balances            BalanceUpdater
+	tasks               *background.Tasks
+	log                 *slog.Logger
 }
 
-func NewTransactionService(repo *repositories.TransactionRepository, publisher *queue.SettlementPublisher) *TransactionService {
+func NewTransactionService(repo TransactionStore, ledger LedgerStore, publisher SettlementPublisher, risk *RiskEngine, limits *LimitService, audit *AuditService, events EventStore, balances BalanceUpdater, tasks *background.Tasks, logger *slog.Logger) *TransactionService {
 	return &TransactionService{
 		repo:                repo,
+		ledger:              ledger,
 		settlementPublisher: publisher,
+		risk:                risk,
+		limits:              limits,
+		audit:               audit,
+		events:              events,
+		balances:            balances,
+		tasks:               tasks,
+		log:                 logger,
 	}
 }
 
 func (s *TransactionService) Create(ctx context.Context, req dto.TransactionCreateRequest) (dto.TransactionResponse, error) {
-	ref := req.Reference // is now string
-	if ref == "" {       // Check for empty string instead of 0
-		// In a real scenario, an int reference would be generated, perhaps from a sequence or tx.ID after creation.
-		// For now, we'll rely on it being provided or handled by the DB.
-		// For string references, you might want to generate a UUID here if not provided.
+	ref := req.Reference
+	if ref == "" {
+		var err error
+		if ref, err = newReference(); err != nil {
+			return dto.TransactionResponse{}, fmt.Errorf("generate reference: %w", err)
+		}
 	}
 
 	email := req.CustomerEmail
@@ -54,8 +69,9 @@ func (s *TransactionService) Create(ctx context.Context, req dto.TransactionCrea
 	amountKobo := int64(math.Round(req.Amount * 100))
 
 	tx := &models.Transaction{
-		Reference:     ref, // string
+		Reference:     ref,
 		MerchantID:    req.MerchantID,
+		Type:          models.TransactionTypePayment,
 		CustomerEmail: email,
 		CustomerID:    req.CustomerID,
 		CustomerName:  req.CustomerName,
@@ -64,138 +80,410 @@ func (s *TransactionService) Create(ctx context.Context, req dto.TransactionCrea
 		Status:        status,
 		PaymentMethod: paymentMethod,
 		Description:   req.Description,
+		Livemode:      auth.FromContext(ctx).Livemode(),
 	}
 
-	if err := s.repo.Create(ctx, tx); err != nil {
+	if s.risk != nil {
+		assessment, err := s.risk.Evaluate(ctx, tx, req.CardBIN)
+		if err != nil {
+			return dto.TransactionResponse{}, fmt.Errorf("risk screening: %w", err)
+		}
+		tx.RiskScore = assessment.Score
+		tx.RiskDecision = assessment.Decision
+		tx.RiskReasons = assessment.Reasons
+		switch assessment.Decision {
+		case models.RiskDecisionBlock:
+			tx.Status = models.TransactionStatusBlocked
+		case models.RiskDecisionReview:
+			tx.Status = models.TransactionStatusPendingReview
+		}
+	} else {
+		tx.RiskDecision = models.RiskDecisionAllow
+	}
+
+	// Blocked and test-mode transactions are recorded without counting towards the merchant's volume.
+	if s.limits != nil && tx.Status != models.TransactionStatusBlocked && tx.Livemode {
+		if err := s.limits.Reserve(ctx, tx); err != nil {
+			return dto.TransactionResponse{}, err
+		}
+	}
+
+	create := s.repo.Create
+	if tx.Status == models.TransactionStatusPendingReview {
+		create = s.repo.CreateForReview
+	}
+	ev := newTransactionEvent(models.EventSourceAPI, "", map[string]interface{}{
+		"risk_decision": tx.RiskDecision,
+		"risk_score":    tx.RiskScore,
+	})
+	if err := create(ctx, tx, ev); err != nil {
+		s.releaseLimits(context.WithoutCancel(ctx), tx)
 		return dto.TransactionResponse{}, err
 	}
 
-	// Update merchant balance asynchronously for revenue-generating transactions only
-	if tx.Status != "payout" && tx.PaymentMethod != "payout" {
-		amountCurrency := float64(tx.Amount) / 100
-		go s.updateMerchantBalance(tx.MerchantID, tx.Currency, amountCurrency)
+	s.audit.Record(ctx, models.AuditActionCreate, nil, tx)
+
+	metrics.TransactionsCreated.WithLabelValues(tx.Currency, tx.Status).Inc()
+	metrics.TransactionAmount.WithLabelValues(tx.Currency, tx.Status).Add(float64(tx.Amount))
+
+	// Only captured transactions reach the ledger and settlement. Blocked and
+	// held ones never do; pending and authorized ones wait for Capture.
+	if tx.Status == models.TransactionStatusSuccess {
+		s.applySettlementEffects(ctx, tx)
 	}
 
+	return toTransactionResponse(tx), nil
+}
+
+// applySettlementEffects credits the merchant for a revenue-generating
+// transaction: a wallet_ledger entry, the merchant-service balance and the
+// settlement queue. Only live payments are credited. Failures are logged, not returned,
+// because the transaction itself has already been recorded.
+func (s *TransactionService) applySettlementEffects(ctx context.Context, tx *models.Transaction) {
+	if !tx.IsPayment() || !tx.Livemode {
+		return
+	}
+
+	// Record ledger credit for this merchant to feed settlement calculations.
+	if s.ledger != nil {
+		err := s.ledger.Record(ctx, &models.LedgerEntry{
+			MerchantID:    tx.MerchantID,
+			TransactionID: tx.ID,
+			EntryType:     models.LedgerEntryCredit,
+			Amount:        tx.Amount,
+			Currency:      tx.Currency,
+			Description:   "Transaction credit",
+			Reference:     tx.Reference,
+		})
+		if err != nil {
+			// Log the error but don't fail the transaction creation
+			s.log.ErrorContext(ctx, "failed to record ledger entry", "reference", tx.Reference,
+				"merchant_id", tx.MerchantID, "entry_type", models.LedgerEntryCredit, "error", err)
+		}
+	}
+
+	// Update merchant balance asynchronously
+	amountCurrency := float64(tx.Amount) / 100
+	s.tasks.Go(func() {
+		s.balances.Record(context.WithoutCancel(ctx), tx.MerchantID, tx.Currency, amountCurrency)
+	})
+
 	// Publish settlement event to Redis queue
-	if s.settlementPublisher != nil && tx.Status != "payout" && tx.PaymentMethod != "payout" {
-		go func() {
-			publishCtx := context.Background()
-			// tx.Reference is now string, need to convert tx.ID to string if settlementPublisher expects string reference
-			if err := s.settlementPublisher.PublishTransaction(publishCtx, tx.MerchantID, tx.Amount, tx.Currency, tx.ID); err != nil {
-				// Log error but don't fail the transaction
-				log.Printf("Failed to publish settlement event: %v\n", err)
-			}
-		}()
+	s.publishSettlement(ctx, tx, tx.Amount)
+}
+
+// applyRefundEffects reverses applySettlementEffects for a refunded transaction.
+func (s *TransactionService) applyRefundEffects(ctx context.Context, tx *models.Transaction) {
+	if !tx.IsPayment() || !tx.Livemode {
+		return
 	}
 
-	return dto.TransactionResponse{
-		ID:            tx.ID,
-		Reference:     tx.Reference, // string
-		MerchantID:    tx.MerchantID,
-		CustomerEmail: tx.CustomerEmail,
-		CustomerID:    tx.CustomerID,
-		CustomerName:  tx.CustomerName,
-		Amount:        float64(tx.Amount) / 100,
-		Currency:      tx.Currency,
-		Status:        tx.Status,
-		Description:   tx.Description,
-		CreatedAt:     tx.CreatedAt,
-	}, nil
+	if s.ledger != nil {
+		err := s.ledger.Record(ctx, &models.LedgerEntry{
+			MerchantID:    tx.MerchantID,
+			TransactionID: tx.ID,
+			EntryType:     models.LedgerEntryDebit,
+			Amount:        tx.Amount,
+			Currency:      tx.Currency,
+			Description:   "Refund debit",
+			Reference:     tx.Reference,
+		})
+		if err != nil {
+			s.log.ErrorContext(ctx, "failed to record refund ledger entry", "reference", tx.Reference,
+				"merchant_id", tx.MerchantID, "entry_type", models.LedgerEntryDebit, "error", err)
+		}
+	}
+
+	s.tasks.Go(func() {
+		s.balances.Record(context.WithoutCancel(ctx), tx.MerchantID, tx.Currency, -float64(tx.Amount)/100)
+	})
+
+	s.publishSettlement(ctx, tx, -tx.Amount)
 }
 
-// updateMerchantBalance calls the merchant service to update the balance
-func (s *TransactionService) updateMerchantBalance(merchantID int, currency string, amount float64) {
-	merchantServiceURL := os.Getenv("MERCHANT_SERVICE_URL")
-	if merchantServiceURL == "" {
-		merchantServiceURL = "http://merchant-service:7002"
+// publishSettlement adds amount (negative for reversals) to the merchant's
+// unsettled total in the background.
+func (s *TransactionService) publishSettlement(ctx context.Context, tx *models.Transaction, amount int64) {
+	if s.settlementPublisher == nil {
+		return
 	}
+	merchantID, currency, txID, reference := tx.MerchantID, tx.Currency, tx.ID, tx.Reference
+	s.tasks.Go(func() {
+		// Detached from the request but kept in its trace.
+		publishCtx := context.WithoutCancel(ctx)
+		if err := s.settlementPublisher.PublishTransaction(publishCtx, merchantID, amount, currency, txID); err != nil {
+			// Log error but don't fail the transaction
+			s.log.ErrorContext(publishCtx, "failed to publish settlement event", "reference", reference,
+				"merchant_id", merchantID, "amount", amount, "currency", currency, "error", err)
+		}
+	})
+}
 
-	url := fmt.Sprintf("%s/internal/balance/record", merchantServiceURL)
-	payload := map[string]interface{}{
-		"merchant_id": merchantID,
-		"currency":    currency,
-		"amount":      amount,
+// applyBalanceChange reports a movement of amount minor units (negative for
+// money leaving the merchant's balance) to the merchant service and the
+// settlement queue, in the background.
+func (s *TransactionService) applyBalanceChange(ctx context.Context, tx *models.Transaction, amount int64) {
+	s.tasks.Go(func() {
+		s.balances.Record(context.WithoutCancel(ctx), tx.MerchantID, tx.Currency, float64(amount)/100)
+	})
+	s.publishSettlement(ctx, tx, amount)
+}
+
+func (s *TransactionService) Get(ctx context.Context, reference string) (dto.TransactionResponse, error) {
+	tx, err := s.repo.GetByReference(ctx, reference)
+	if err != nil {
+		return dto.TransactionResponse{}, err
 	}
+	if tx == nil {
+		return dto.TransactionResponse{}, nil
+	}
+	return toTransactionResponse(tx), nil
+}
 
-	jsonData, err := json.Marshal(payload)
+// Capture completes a pending or authorized transaction and credits the merchant.
+func (s *TransactionService) Capture(ctx context.Context, reference string) (dto.TransactionResponse, error) {
+	tx, err := s.transition(ctx, reference, models.TransactionTypePayment, models.AuditActionCapture, models.TransactionStatusSuccess,
+		newTransactionEvent(models.EventSourceAPI, "", nil),
+		models.TransactionStatusPending, models.TransactionStatusAuthorized)
 	if err != nil {
-		return
+		return dto.TransactionResponse{}, err
 	}
+	s.applySettlementEffects(ctx, tx)
+	return toTransactionResponse(tx), nil
+}
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+// Refund reverses a captured transaction in full and debits the merchant. It
+// is refused while the transaction has an open or lost dispute, whose
+// chargeback has already debited the merchant.
+func (s *TransactionService) Refund(ctx context.Context, reference string) (dto.TransactionResponse, error) {
+	tx, err := s.transition(ctx, reference, models.TransactionTypePayment, models.AuditActionRefund, models.TransactionStatusRefunded,
+		newTransactionEvent(models.EventSourceAPI, "", nil),
+		models.TransactionStatusSuccess)
 	if err != nil {
-		return
+		return dto.TransactionResponse{}, err
 	}
-	req.Header.Set("Content-Type", "application/json")
+	s.applyRefundEffects(ctx, tx)
+	return toTransactionResponse(tx), nil
+}
 
-	client := &http.Client{Timeout: 5 * time.Second}
-	resp, err := client.Do(req)
+// Void cancels a transaction before capture. Nothing was credited, so only
+// the merchant's limit volume is given back.
+func (s *TransactionService) Void(ctx context.Context, reference string) (dto.TransactionResponse, error) {
+	tx, err := s.transition(ctx, reference, models.TransactionTypePayment, models.AuditActionVoid, models.TransactionStatusVoided,
+		newTransactionEvent(models.EventSourceAPI, "", nil),
+		models.TransactionStatusPending, models.TransactionStatusAuthorized)
 	if err != nil {
-		fmt.Printf("Warning: failed to call merchant service to update balance: %v\n", err)
-		return
+		return dto.TransactionResponse{}, err
+	}
+	s.releaseLimits(ctx, tx)
+	return toTransactionResponse(tx), nil
+}
+
+// processorTransitions lists, per target status, the statuses a worker or
+// processor webhook may move a payment from.
+var processorTransitions = map[string][]string{
+	models.TransactionStatusAuthorized: {models.TransactionStatusPending},
+	models.TransactionStatusSuccess:    {models.TransactionStatusPending, models.TransactionStatusAuthorized},
+	models.TransactionStatusFailed:     {models.TransactionStatusPending, models.TransactionStatusAuthorized},
+	models.TransactionStatusVoided:     {models.TransactionStatusPending, models.TransactionStatusAuthorized},
+	models.TransactionStatusRefunded:   {models.TransactionStatusSuccess},
+}
+
+// payoutTransitions is processorTransitions for payouts, which are only ever
+// paid out or failed.
+var payoutTransitions = map[string][]string{
+	models.TransactionStatusSuccess: {models.TransactionStatusPending},
+	models.TransactionStatusFailed:  {models.TransactionStatusPending},
+}
+
+// ApplyStatusUpdate records a status change reported by a background worker
+// or the payment processor, with the same side effects as the equivalent API call.
+func (s *TransactionService) ApplyStatusUpdate(ctx context.Context, reference string, req dto.TransactionEventRequest) (dto.TransactionResponse, error) {
+	if req.Source != models.EventSourceWorker && req.Source != models.EventSourceWebhook {
+		return dto.TransactionResponse{}, ErrInvalidEventSource
+	}
+	current, err := s.repo.GetByReference(ctx, reference)
+	if err != nil {
+		return dto.TransactionResponse{}, err
+	}
+	if current == nil {
+		return dto.TransactionResponse{}, ErrTransactionNotFound
+	}
+	var transitions map[string][]string
+	switch current.Type {
+	case models.TransactionTypePayment:
+		transitions = processorTransitions
+	case models.TransactionTypePayout:
+		transitions = payoutTransitions
+	default:
+		// Adjustments and the like are moved by operators, never by processors.
+		return dto.TransactionResponse{}, fmt.Errorf("%w: processor events do not apply to a %s", ErrInvalidTransactionState, current.Type)
+	}
+	from, ok := transitions[req.Status]
+	if !ok {
+		return dto.TransactionResponse{}, fmt.Errorf("%w: unsupported status %q for a %s", ErrInvalidTransactionState, req.Status, current.Type)
+	}
+
+	tx, err := s.transition(ctx, reference, current.Type, models.AuditActionStatusUpdate, req.Status,
+		newTransactionEvent(req.Source, "", req.Metadata), from...)
+	if err != nil {
+		return dto.TransactionResponse{}, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		respBody, _ := io.ReadAll(resp.Body)
-		fmt.Printf("Warning: merchant service returned non-ok status for balance update: %d, body: %s\n", resp.StatusCode, respBody)
+	switch {
+	case tx.Type == models.TransactionTypePayout:
+		if tx.Status == models.TransactionStatusFailed {
+			s.reversePayout(ctx, tx)
+		}
+	case tx.Status == models.TransactionStatusSuccess:
+		s.applySettlementEffects(ctx, tx)
+	case tx.Status == models.TransactionStatusRefunded:
+		s.applyRefundEffects(ctx, tx)
+	case tx.Status == models.TransactionStatusFailed, tx.Status == models.TransactionStatusVoided:
+		s.releaseLimits(ctx, tx)
+	}
+	return toTransactionResponse(tx), nil
+}
+
+// Timeline returns the status events of a transaction, oldest first.
+func (s *TransactionService) Timeline(ctx context.Context, transactionID int) ([]dto.TransactionEventResponse, error) {
+	events, err := s.events.ListByTransaction(ctx, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	res := []dto.TransactionEventResponse{}
+	for _, e := range events {
+		res = append(res, dto.TransactionEventResponse{
+			FromStatus: e.FromStatus,
+			ToStatus:   e.ToStatus,
+			Source:     e.Source,
+			Metadata:   e.Metadata,
+			CreatedAt:  e.CreatedAt,
+		})
+	}
+	return res, nil
+}
+
+// piiReencryptBatch is how many rows ReencryptPII updates per query.
+const piiReencryptBatch = 500
+
+// ReencryptPII brings every transaction's customer PII onto the current
+// key, batch by batch, and returns the number of rows updated.
+func (s *TransactionService) ReencryptPII(ctx context.Context) (int, error) {
+	total := 0
+	for {
+		n, err := s.repo.ReencryptPII(ctx, piiReencryptBatch)
+		total += n
+		if err != nil || n == 0 {
+			return total, err
+		}
+	}
+}
+
+// StartPIIReencryption runs ReencryptPII in the background. It stops between
+// batches on shutdown; starting it again resumes where it left off.
+func (s *TransactionService) StartPIIReencryption(ctx context.Context) {
+	s.tasks.Go(func() {
+		n, err := s.ReencryptPII(s.tasks.Context())
+		if err != nil {
+			s.log.ErrorContext(ctx, "PII re-encryption stopped", "rows", n, "error", err)
+			return
+		}
+		s.log.InfoContext(ctx, "PII re-encryption finished", "rows", n)
+	})
+}
+
+// releaseLimits gives back the limit volume reserved for a transaction that
+// will never be collected.
+func (s *TransactionService) releaseLimits(ctx context.Context, tx *models.Transaction) {
+	if s.limits == nil {
+		return
+	}
+	if err := s.limits.ReleaseTransaction(ctx, tx); err != nil {
+		s.log.WarnContext(ctx, "failed to release limit volume", "reference", tx.Reference, "error", err)
 	}
-	// Ignore errors - balance update is not critical for transaction success
 }
 
-func (s *TransactionService) Get(ctx context.Context, reference string) (dto.TransactionResponse, error) { // changed reference to string
-	tx, err := s.repo.GetByReference(ctx, reference) // pass string
+// UpdateDetails lets an operator correct a transaction's descriptive fields.
+func (s *TransactionService) UpdateDetails(ctx context.Context, reference string, req dto.TransactionUpdateRequest) (dto.TransactionResponse, error) {
+	tx, err := s.repo.GetByReference(ctx, reference)
 	if err != nil {
 		return dto.TransactionResponse{}, err
 	}
 	if tx == nil {
-		return dto.TransactionResponse{}, nil
+		return dto.TransactionResponse{}, ErrTransactionNotFound
 	}
-	return dto.TransactionResponse{
-		ID:            tx.ID,
-		Reference:     tx.Reference, // string
-		MerchantID:    tx.MerchantID,
-		CustomerEmail: tx.CustomerEmail,
-		CustomerID:    tx.CustomerID,
-		CustomerName:  tx.CustomerName,
-		Amount:        float64(tx.Amount) / 100.0,
-		Currency:      tx.Currency,
-		Status:        tx.Status,
-		Description:   tx.Description,
-		CreatedAt:     tx.CreatedAt,
-	}, nil
+
+	before := *tx
+	if req.Description != nil {
+		tx.Description = *req.Description
+	}
+	if req.CustomerEmail != nil {
+		tx.CustomerEmail = *req.CustomerEmail
+	}
+	if req.CustomerName != nil {
+		tx.CustomerName = *req.CustomerName
+	}
+	if req.CustomerID != nil {
+		tx.CustomerID = *req.CustomerID
+	}
+	if err := s.repo.UpdateDetails(ctx, tx); err != nil {
+		return dto.TransactionResponse{}, err
+	}
+	s.audit.Record(ctx, models.AuditActionAdminEdit, &before, tx)
+	return toTransactionResponse(tx), nil
 }
 
-func (s *TransactionService) Capture(ctx context.Context, reference string) dto.TransactionResponse { // changed reference to string
-	_ = ctx
-	return dto.TransactionResponse{Reference: reference, Status: "captured"}
+// History returns the transaction's audit trail.
+func (s *TransactionService) History(ctx context.Context, reference string) (dto.TransactionHistoryResponse, error) {
+	return s.audit.History(ctx, reference)
 }
 
-func (s *TransactionService) Refund(ctx context.Context, reference string) dto.TransactionResponse { // changed reference to string
-	_ = ctx
-	return dto.TransactionResponse{Reference: reference, Status: "refunded"}
+// transition moves a transaction of type typ to status if it is currently in
+// one of from, adding ev to its timeline and recording the change in the
+// audit log.
+func (s *TransactionService) transition(ctx context.Context, reference, typ, action, status string, ev *models.TransactionEvent, from ...string) (*models.Transaction, error) {
+	tx, err := s.repo.GetByReference(ctx, reference)
+	if err != nil {
+		return nil, err
+	}
+	if tx == nil {
+		return nil, ErrTransactionNotFound
+	}
+	if tx.Type != typ {
+		return nil, fmt.Errorf("%w: cannot %s a %s", ErrInvalidTransactionState, action, tx.Type)
+	}
+
+	before := *tx
+	tx.Status = status
+	ev.FromStatus = before.Status
+	var ok bool
+	if status == models.TransactionStatusRefunded {
+		// A refund must not give back money a chargeback already took.
+		ok, err = s.repo.Refund(ctx, tx, ev, from...)
+	} else {
+		ok, err = s.repo.UpdateStatus(ctx, tx, ev, from...)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: cannot %s a %s transaction", ErrInvalidTransactionState, action, before.Status)
+	}
+	s.audit.Record(ctx, action, &before, tx)
+	return tx, nil
 }
 
-func (s *TransactionService) ListByMerchant(ctx context.Context, merchantID int, limit int) (dto.TransactionListResponse, error) {
-	list, err := s.repo.ListByMerchant(ctx, merchantID, limit)
+// ListByMerchant lists a merchant's transactions. livemode narrows the list to
+// live or test transactions; nil returns both.
+func (s *TransactionService) ListByMerchant(ctx context.Context, merchantID int, status string, livemode *bool, limit int) (dto.TransactionListResponse, error) {
+	list, err := s.repo.ListByMerchant(ctx, merchantID, status, livemode, limit)
 	if err != nil {
 		return dto.TransactionListResponse{}, err
 	}
 	res := dto.TransactionListResponse{}
 	for _, tx := range list {
-		res.Transactions = append(res.Transactions, dto.TransactionResponse{
-			ID:            tx.ID,
-			Reference:     tx.Reference, // string
-			MerchantID:    tx.MerchantID,
-			CustomerEmail: tx.CustomerEmail,
-			CustomerID:    tx.CustomerID,
-			CustomerName:  tx.CustomerName,
-			Amount:        float64(tx.Amount) / 100,
-			Currency:      tx.Currency,
-			Status:        tx.Status,
-			Description:   tx.Description,
-			CreatedAt:     tx.CreatedAt,
-		})
+		res.Transactions = append(res.Transactions, toTransactionResponse(tx))
 	}
 	return res, nil
 }
@@ -207,19 +495,50 @@ func (s *TransactionService) ListByStatus(ctx context.Context, status string, li
 	}
 	res := dto.TransactionListResponse{}
 	for _, tx := range list {
-		res.Transactions = append(res.Transactions, dto.TransactionResponse{
-			ID:            tx.ID,
-			Reference:     tx.Reference,
-			MerchantID:    tx.MerchantID,
-			CustomerEmail: tx.CustomerEmail,
-			CustomerID:    tx.CustomerID,
-			CustomerName:  tx.CustomerName,
-			Amount:        float64(tx.Amount) / 100,
-			Currency:      tx.Currency,
-			Status:        tx.Status,
-			Description:   tx.Description,
-			CreatedAt:     tx.CreatedAt,
-		})
+		res.Transactions = append(res.Transactions, toTransactionResponse(tx))
 	}
 	return res, nil
 }
+
+func toTransactionResponse(tx *models.Transaction) dto.TransactionResponse {
+	return dto.TransactionResponse{
+		ID:            tx.ID,
+		Reference:     tx.Reference,
+		MerchantID:    tx.MerchantID,
+		CustomerEmail: tx.CustomerEmail,
+		CustomerID:    tx.CustomerID,
+		CustomerName:  tx.CustomerName,
+		Amount:        float64(tx.Amount) / 100,
+		Currency:      tx.Currency,
+		Type:          tx.Type,
+		Status:        tx.Status,
+		Description:   tx.Description,
+		RiskScore:     tx.RiskScore,
+		RiskDecision:  tx.RiskDecision,
+		RiskReasons:   tx.RiskReasons,
+		Livemode:      tx.Livemode,
+		CreatedAt:     tx.CreatedAt,
+	}
+}
+
+// newReference returns a reference for a transaction created without one.
+func newReference() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "txn_" + hex.EncodeToString(b), nil
+}
+
+// newTransactionEvent builds a timeline event. Metadata that cannot be encoded is dropped.
+func newTransactionEvent(source, from string, metadata map[string]interface{}) *models.TransactionEvent {
+	ev := &models.TransactionEvent{FromStatus: from, Source: source}
+	if len(metadata) > 0 {
+		if raw, err := json.Marshal(metadata); err == nil {
+			ev.Metadata = raw
+		} else {
+			slog.Warn("failed to encode event metadata", "error", err)
+		}
+	}
+	return ev
+}
@@ -0,0 +1,209 @@
+// Package validate checks request DTOs against the rules declared in their
+// `validate` struct tags, so each DTO documents its own constraints:
+//
+//	Amount   float64 `json:"amount" validate:"required,gt=0"`
+//	Currency string  `json:"currency" validate:"required,currency"`
+//
+// Rules other than required are skipped for zero values and nil pointers, so
+// optional fields are only checked when set. Fields are reported by their
+// JSON names.
+//
+// Supported rules:
+//
+//	required     the value must not be zero
+//	min=N max=N  numbers: bounds on the value; strings: bounds on the length
+//	gt=N         numbers: strictly greater than N
+//	decimals=N   numbers: at most N decimal places
+//	oneof=a b c  the value must be one of the listed words
+//	email        an email address
+//	currency     a three-letter upper-case ISO 4217 code
+//	numeric      a string of digits
+//	url          an absolute http or https URL
+package validate
+
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/kodra-pay/transaction-service/internal/apperr"
+)
+
+// Struct checks every field of the struct v points to and returns a
+// validation_failed error listing each invalid field, or nil. It panics on
+// an unknown rule, since tags are fixed at compile time.
+func Struct(v interface{}) error {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("validate: %T is not a struct", v))
+	}
+	var invalid []apperr.FieldError
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		f := rt.Field(i)
+		tag, ok := f.Tag.Lookup("validate")
+		if !ok || !f.IsExported() {
+			continue
+		}
+		if problem := check(rv.Field(i), tag); problem != "" {
+			invalid = append(invalid, apperr.FieldError{Field: fieldName(f), Message: problem})
+		}
+	}
+	if len(invalid) > 0 {
+		return apperr.Validation(invalid...)
+	}
+	return nil
+}
+
+// check applies the rules in tag to v and returns the first problem found.
+func check(v reflect.Value, tag string) string {
+	rules := strings.Split(tag, ",")
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			if contains(rules, "required") {
+				return "is required"
+			}
+			return ""
+		}
+		v = v.Elem()
+	}
+	if v.IsZero() {
+		if contains(rules, "required") {
+			return "is required"
+		}
+		return ""
+	}
+	for _, rule := range rules {
+		name, arg, _ := strings.Cut(rule, "=")
+		if problem := apply(v, name, arg); problem != "" {
+			return problem
+		}
+	}
+	return ""
+}
+
+func apply(v reflect.Value, rule, arg string) string {
+	switch rule {
+	case "required":
+		return ""
+	case "min":
+		if v.Kind() == reflect.String {
+			if utf8.RuneCountInString(v.String()) < atoi(arg) {
+				return "must be at least " + arg + " characters"
+			}
+		} else if number(v) < atof(arg) {
+			return "must be at least " + arg
+		}
+	case "max":
+		if v.Kind() == reflect.String {
+			if utf8.RuneCountInString(v.String()) > atoi(arg) {
+				return "must be at most " + arg + " characters"
+			}
+		} else if number(v) > atof(arg) {
+			return "must be at most " + arg
+		}
+	case "gt":
+		if number(v) <= atof(arg) {
+			return "must be greater than " + arg
+		}
+	case "decimals":
+		if decimalPlaces(v.Float()) > atoi(arg) {
+			return "must have at most " + arg + " decimal places"
+		}
+	case "oneof":
+		options := strings.Fields(arg)
+		if !contains(options, fmt.Sprint(v.Interface())) {
+			return "must be one of: " + strings.Join(options, ", ")
+		}
+	case "email":
+		addr, err := mail.ParseAddress(v.String())
+		if err != nil || addr.Address != v.String() {
+			return "must be a valid email address"
+		}
+	case "currency":
+		if !isCurrency(v.String()) {
+			return "must be a three-letter ISO 4217 code such as NGN"
+		}
+	case "numeric":
+		if strings.Trim(v.String(), "0123456789") != "" {
+			return "must contain only digits"
+		}
+	case "url":
+		u, err := url.Parse(v.String())
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return "must be an absolute http or https URL"
+		}
+	default:
+		panic("validate: unknown rule " + rule)
+	}
+	return ""
+}
+
+// decimalPlaces counts the decimal places of f as written in a request. f is
+// first rounded to the 15 significant digits a float64 holds exactly, so
+// noise such as 0.1+0.2 counts as the 0.3 it stands for.
+func decimalPlaces(f float64) int {
+	rounded, _ := strconv.ParseFloat(strconv.FormatFloat(f, 'g', 15, 64), 64)
+	_, frac, _ := strings.Cut(strconv.FormatFloat(rounded, 'f', -1, 64), ".")
+	return len(frac)
+}
+
+func isCurrency(s string) bool {
+	if len(s) != 3 {
+		return false
+	}
+	for _, r := range s {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+func number(v reflect.Value) float64 {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	}
+	panic("validate: numeric rule on " + v.Kind().String())
+}
+
+func atoi(s string) int {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		panic("validate: bad rule argument " + s)
+	}
+	return n
+}
+
+func atof(s string) float64 {
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		panic("validate: bad rule argument " + s)
+	}
+	return n
+}
+
+func fieldName(f reflect.StructField) string {
+	if name, _, _ := strings.Cut(f.Tag.Get("json"), ","); name != "" && name != "-" {
+		return name
+	}
+	return f.Name
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,72 @@
+package validate
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/kodra-pay/transaction-service/internal/apperr"
+)
+
+type request struct {
+	Reference string   `json:"reference" validate:"required,max=5"`
+	Amount    float64  `json:"amount" validate:"required,min=0.01,decimals=2"`
+	Currency  string   `json:"currency" validate:"currency"`
+	Email     string   `json:"email,omitempty" validate:"email"`
+	Method    string   `json:"method" validate:"oneof=card ussd"`
+	BIN       *string  `json:"bin" validate:"numeric"`
+	Fee       *float64 `json:"fee" validate:"required"`
+}
+
+func fields(err error) []string {
+	var e *apperr.Error
+	if !errors.As(err, &e) {
+		return nil
+	}
+	var names []string
+	for _, f := range e.Fields {
+		names = append(names, f.Field)
+	}
+	return names
+}
+
+func TestStruct(t *testing.T) {
+	fee := 1.5
+	valid := func() request {
+		return request{Reference: "r-1", Amount: 10.5, Currency: "NGN", Fee: &fee}
+	}
+	if err := Struct(&request{Reference: "r-1", Amount: 0.1 + 0.2, Fee: &fee}); err != nil {
+		t.Errorf("valid request with optional fields unset: %v", err)
+	}
+
+	bin := "41x1"
+	tests := []struct {
+		name   string
+		change func(*request)
+		field  string
+	}{
+		{"missing reference", func(r *request) { r.Reference = "" }, "reference"},
+		{"long reference", func(r *request) { r.Reference = "r-1234" }, "reference"},
+		{"below minimum", func(r *request) { r.Amount = 0.001 }, "amount"},
+		{"three decimals", func(r *request) { r.Amount = 1.005 }, "amount"},
+		{"lower-case currency", func(r *request) { r.Currency = "ngn" }, "currency"},
+		{"bad email", func(r *request) { r.Email = "@" }, "email"},
+		{"unknown option", func(r *request) { r.Method = "cheque" }, "method"},
+		{"non-numeric pointer", func(r *request) { r.BIN = &bin }, "bin"},
+		{"nil required pointer", func(r *request) { r.Fee = nil }, "fee"},
+	}
+	for _, tt := range tests {
+		r := valid()
+		tt.change(&r)
+		if got := fields(Struct(&r)); len(got) != 1 || got[0] != tt.field {
+			t.Errorf("%s: invalid fields %v, want [%s]", tt.name, got, tt.field)
+		}
+	}
+
+	err := Struct(&request{Amount: -1, Currency: "naira", Fee: &fee})
+	if got := fields(err); len(got) != 3 {
+		t.Errorf("invalid fields %v, want reference, amount and currency", got)
+	}
+	if apperr.Status(err) != 400 {
+		t.Errorf("status %d, want 400", apperr.Status(err))
+	}
+}
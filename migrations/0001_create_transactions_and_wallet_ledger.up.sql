@@ -0,0 +1,36 @@
+-- Base schema: payment transactions and the merchant wallet ledger they post to.
+-- Amounts are in minor units.
+CREATE TABLE IF NOT EXISTS transactions (
+    id BIGSERIAL PRIMARY KEY,
+    reference TEXT NOT NULL UNIQUE,
+    merchant_id BIGINT NOT NULL,
+    customer_email TEXT NOT NULL DEFAULT '',
+    customer_name TEXT NOT NULL DEFAULT '',
+    amount BIGINT NOT NULL,
+    currency TEXT NOT NULL,
+    status TEXT NOT NULL,
+    payment_method TEXT NOT NULL DEFAULT 'card',
+    description TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_transactions_merchant_id_created_at ON transactions (merchant_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_transactions_status_created_at ON transactions (status, created_at DESC);
+
+-- Each entry carries the merchant's running balance after it was applied
+CREATE TABLE IF NOT EXISTS wallet_ledger (
+    id BIGSERIAL PRIMARY KEY,
+    merchant_id BIGINT NOT NULL,
+    transaction_id BIGINT NOT NULL REFERENCES transactions(id),
+    entry_type TEXT NOT NULL CHECK (entry_type IN ('credit', 'debit')),
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    balance_after BIGINT NOT NULL,
+    currency TEXT NOT NULL,
+    description TEXT,
+    reference TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_wallet_ledger_merchant_id_created_at ON wallet_ledger (merchant_id, created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_wallet_ledger_transaction_id ON wallet_ledger (transaction_id);
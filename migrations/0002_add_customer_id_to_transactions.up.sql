@@ -1,3 +1,3 @@
 -- Add customer_id column to the transactions table
 ALTER TABLE transactions
-ADD COLUMN customer_id BIGINT;
\ No newline at end of file
+ADD COLUMN IF NOT EXISTS customer_id BIGINT;
\ No newline at end of file
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_wallet_ledger_merchant_id_currency_id;
+DROP TABLE IF EXISTS dispute_evidence;
+DROP TABLE IF EXISTS disputes;
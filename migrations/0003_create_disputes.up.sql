@@ -0,0 +1,36 @@
+-- Chargebacks raised against captured transactions
+CREATE TABLE IF NOT EXISTS disputes (
+    id BIGSERIAL PRIMARY KEY,
+    transaction_id BIGINT NOT NULL REFERENCES transactions(id),
+    reference TEXT NOT NULL,
+    merchant_id BIGINT NOT NULL,
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    currency TEXT NOT NULL,
+    reason_code TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'needs_response',
+    evidence_due_by TIMESTAMPTZ NOT NULL,
+    resolved_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_disputes_merchant_id ON disputes (merchant_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_disputes_status ON disputes (status, created_at DESC);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_disputes_open_transaction
+    ON disputes (transaction_id) WHERE status IN ('needs_response', 'under_review');
+
+CREATE TABLE IF NOT EXISTS dispute_evidence (
+    id BIGSERIAL PRIMARY KEY,
+    dispute_id BIGINT NOT NULL REFERENCES disputes(id),
+    type TEXT NOT NULL,
+    description TEXT,
+    file_url TEXT,
+    submitted_by TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_dispute_evidence_dispute_id ON dispute_evidence (dispute_id);
+
+-- Every ledger entry, dispute debits and re-credits included, derives
+-- balance_after from the merchant's latest entry in its currency by id.
+CREATE INDEX IF NOT EXISTS idx_wallet_ledger_merchant_id_currency_id ON wallet_ledger (merchant_id, currency, id DESC);
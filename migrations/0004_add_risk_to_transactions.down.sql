@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_transactions_customer_email_created_at;
+DROP INDEX IF EXISTS idx_transactions_customer_id_created_at;
+
+ALTER TABLE transactions
+DROP COLUMN IF EXISTS risk_reasons,
+DROP COLUMN IF EXISTS risk_decision,
+DROP COLUMN IF EXISTS risk_score;
@@ -0,0 +1,9 @@
+-- Risk screening outcome recorded when a transaction is created
+ALTER TABLE transactions
+ADD COLUMN IF NOT EXISTS risk_score INTEGER NOT NULL DEFAULT 0,
+ADD COLUMN IF NOT EXISTS risk_decision TEXT NOT NULL DEFAULT 'allow',
+ADD COLUMN IF NOT EXISTS risk_reasons TEXT[] NOT NULL DEFAULT '{}';
+
+-- Velocity rules look up recent transactions per customer
+CREATE INDEX IF NOT EXISTS idx_transactions_customer_id_created_at ON transactions (customer_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_transactions_customer_email_created_at ON transactions (customer_email, created_at DESC);
@@ -0,0 +1,16 @@
+-- Manual review queue for transactions held by risk screening
+CREATE TABLE IF NOT EXISTS transaction_reviews (
+    id BIGSERIAL PRIMARY KEY,
+    transaction_id BIGINT NOT NULL UNIQUE REFERENCES transactions(id),
+    reference TEXT NOT NULL,
+    merchant_id BIGINT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending',
+    reviewer TEXT,
+    notes TEXT,
+    claimed_at TIMESTAMPTZ,
+    decided_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_transaction_reviews_status ON transaction_reviews (status, created_at);
@@ -0,0 +1,3 @@
+ALTER TABLE transactions DROP COLUMN IF EXISTS limit_currency;
+ALTER TABLE transactions DROP COLUMN IF EXISTS limit_reserved_at;
+DROP TABLE IF EXISTS merchant_limits;
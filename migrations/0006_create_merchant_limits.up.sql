@@ -0,0 +1,16 @@
+-- Per-merchant, per-currency collection limits. Amounts are in minor units; 0 disables a cap.
+CREATE TABLE IF NOT EXISTS merchant_limits (
+    merchant_id BIGINT NOT NULL,
+    currency TEXT NOT NULL,
+    tier TEXT NOT NULL DEFAULT 'starter',
+    max_single_amount BIGINT NOT NULL DEFAULT 0,
+    daily_volume BIGINT NOT NULL DEFAULT 0,
+    monthly_volume BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (merchant_id, currency)
+);
+
+-- The volume a transaction added to the counters, so it is given back in
+-- the same currency, day and month. NULL means it was not counted.
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS limit_reserved_at TIMESTAMPTZ;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS limit_currency TEXT NOT NULL DEFAULT '';
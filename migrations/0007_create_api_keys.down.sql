@@ -0,0 +1,4 @@
+ALTER TABLE transactions
+DROP COLUMN IF EXISTS livemode;
+
+DROP TABLE IF EXISTS api_keys;
@@ -0,0 +1,19 @@
+-- Merchant API keys; only a SHA-256 hash of each key is stored
+CREATE TABLE IF NOT EXISTS api_keys (
+    id BIGSERIAL PRIMARY KEY,
+    merchant_id BIGINT NOT NULL,
+    prefix TEXT NOT NULL,
+    key_hash TEXT NOT NULL UNIQUE,
+    mode TEXT NOT NULL CHECK (mode IN ('test', 'live')),
+    status TEXT NOT NULL DEFAULT 'active',
+    expires_at TIMESTAMPTZ,
+    last_used_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_merchant_id ON api_keys (merchant_id);
+
+-- Transactions created with test-mode keys never reach the ledger or settlement
+ALTER TABLE transactions
+ADD COLUMN IF NOT EXISTS livemode BOOLEAN NOT NULL DEFAULT TRUE;
@@ -0,0 +1,7 @@
+-- Only drops the lookup columns; rows stay encrypted and still need the keyfile.
+DROP INDEX IF EXISTS idx_transactions_pii_key_id;
+DROP INDEX IF EXISTS idx_transactions_customer_email_index;
+
+ALTER TABLE transactions
+DROP COLUMN IF EXISTS pii_key_id,
+DROP COLUMN IF EXISTS customer_email_index;
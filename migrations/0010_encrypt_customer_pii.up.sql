@@ -0,0 +1,10 @@
+-- Customer email and name are stored envelope-encrypted; emails are looked up
+-- through a keyed blind index. pii_key_id records the key-encryption key each
+-- row is wrapped with so rotation can find rows still on an older key. Rows
+-- written before this migration are encrypted by the re-encryption job.
+ALTER TABLE transactions
+ADD COLUMN IF NOT EXISTS customer_email_index TEXT,
+ADD COLUMN IF NOT EXISTS pii_key_id TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_transactions_customer_email_index ON transactions (customer_email_index, created_at);
+CREATE INDEX IF NOT EXISTS idx_transactions_pii_key_id ON transactions (pii_key_id);
@@ -0,0 +1,21 @@
+-- Customer erasure (GDPR/NDPR) requests, executed by a background job.
+-- email holds the encrypted address only until the request has run;
+-- email_index is kept so the erasure can be verified later.
+CREATE TABLE IF NOT EXISTS erasure_requests (
+    id BIGSERIAL PRIMARY KEY,
+    customer_id BIGINT NOT NULL DEFAULT 0,
+    email TEXT,
+    email_index TEXT,
+    status TEXT NOT NULL DEFAULT 'pending',
+    requested_by TEXT NOT NULL,
+    reason TEXT,
+    transactions_affected INT NOT NULL DEFAULT 0,
+    ledger_entries_affected INT NOT NULL DEFAULT 0,
+    error TEXT,
+    started_at TIMESTAMPTZ,
+    completed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (customer_id <> 0 OR email_index IS NOT NULL)
+);
+
+CREATE INDEX IF NOT EXISTS idx_erasure_requests_status ON erasure_requests (status, id);
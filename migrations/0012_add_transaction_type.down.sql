@@ -0,0 +1,5 @@
+UPDATE transactions SET status = 'payout' WHERE type = 'payout' AND status = 'success';
+
+DROP INDEX IF EXISTS idx_transactions_merchant_id_type;
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_type_check;
+ALTER TABLE transactions DROP COLUMN IF EXISTS type;
@@ -0,0 +1,18 @@
+-- Transactions carry an explicit type. Payouts used to be recognised by a
+-- status or payment_method of 'payout'; those rows are backfilled, and a
+-- 'payout' status becomes 'success' since it was only ever set on completed
+-- payouts.
+ALTER TABLE transactions
+ADD COLUMN IF NOT EXISTS type TEXT NOT NULL DEFAULT 'payment';
+
+UPDATE transactions
+SET type = 'payout',
+    status = CASE WHEN status = 'payout' THEN 'success' ELSE status END
+WHERE status = 'payout' OR payment_method = 'payout';
+
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_type_check;
+ALTER TABLE transactions
+ADD CONSTRAINT transactions_type_check
+CHECK (type IN ('payment', 'refund', 'payout', 'adjustment', 'fee', 'chargeback'));
+
+CREATE INDEX IF NOT EXISTS idx_transactions_merchant_id_type ON transactions (merchant_id, type, created_at DESC);
@@ -0,0 +1,23 @@
+-- Manual balance adjustments awaiting or past a second operator's approval.
+-- The check on decided_by keeps the maker-checker rule even for direct writes.
+CREATE TABLE IF NOT EXISTS ledger_adjustments (
+    id BIGSERIAL PRIMARY KEY,
+    transaction_id BIGINT NOT NULL UNIQUE REFERENCES transactions(id),
+    reference TEXT NOT NULL,
+    merchant_id BIGINT NOT NULL,
+    direction TEXT NOT NULL CHECK (direction IN ('credit', 'debit')),
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    currency TEXT NOT NULL,
+    reason TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending',
+    proposed_by TEXT NOT NULL,
+    decided_by TEXT,
+    decision_notes TEXT,
+    decided_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (decided_by IS NULL OR decided_by <> proposed_by)
+);
+
+CREATE INDEX IF NOT EXISTS idx_ledger_adjustments_status ON ledger_adjustments (status, created_at);
+CREATE INDEX IF NOT EXISTS idx_ledger_adjustments_merchant_id ON ledger_adjustments (merchant_id, created_at DESC);
@@ -0,0 +1 @@
+ALTER TABLE disputes DROP COLUMN IF EXISTS livemode;
@@ -0,0 +1,9 @@
+-- Disputes carry the mode of their transaction, so API keys only reach
+-- disputes of their own mode.
+ALTER TABLE disputes
+ADD COLUMN IF NOT EXISTS livemode BOOLEAN NOT NULL DEFAULT TRUE;
+
+UPDATE disputes d
+SET livemode = t.livemode
+FROM transactions t
+WHERE t.id = d.transaction_id;
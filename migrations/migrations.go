@@ -0,0 +1,11 @@
+// Package migrations embeds the service's SQL schema migrations. Each version
+// is a NNNN_name.up.sql file with a matching NNNN_name.down.sql that undoes it.
+// Up migrations are written to be re-runnable, so a database whose schema was
+// applied by hand before versions were tracked can be brought under the
+// migrator with a plain "migrate up".
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS